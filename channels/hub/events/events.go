@@ -9,13 +9,20 @@ import (
 	"github.com/hamstah/gomcp/protocol/mux"
 )
 
-type Events interface {
+// McpEvents are the events of an MCP session, received from the MCP client
+type McpEvents interface {
 	// receive "initialize" request
 	EventMcpRequestInitialize(params *mcp.JsonRpcRequestInitializeParams, reqId *jsonrpc.JsonRpcRequestId)
 
 	// receive "initialized" notification
 	EventMcpNotificationInitialized()
 
+	// receive "notifications/roots/list_changed" notification
+	EventMcpNotificationRootsListChanged()
+
+	// receive "notifications/cancelled" notification
+	EventMcpNotificationCancelled(params *mcp.JsonRpcNotificationCancelledParams)
+
 	// receive "tools/list" request
 	EventMcpRequestToolsList(params *mcp.JsonRpcRequestToolsListParams, reqId *jsonrpc.JsonRpcRequestId)
 
@@ -28,24 +35,57 @@ type Events interface {
 	// receive "resources/list" request
 	EventMcpRequestResourcesList(params *mcp.JsonRpcRequestResourcesListParams, reqId *jsonrpc.JsonRpcRequestId)
 
+	// receive "resources/read" request
+	EventMcpRequestResourcesRead(ctx context.Context, params *mcp.JsonRpcRequestResourcesReadParams, reqId *jsonrpc.JsonRpcRequestId)
+
+	// receive "resources/templates/list" request
+	EventMcpRequestResourcesTemplatesList(params *mcp.JsonRpcRequestResourcesTemplatesListParams, reqId *jsonrpc.JsonRpcRequestId)
+
+	// receive "resources/subscribe" request
+	EventMcpRequestResourcesSubscribe(params *mcp.JsonRpcRequestResourcesSubscribeParams, reqId *jsonrpc.JsonRpcRequestId)
+
+	// receive "resources/unsubscribe" request
+	EventMcpRequestResourcesUnsubscribe(params *mcp.JsonRpcRequestResourcesUnsubscribeParams, reqId *jsonrpc.JsonRpcRequestId)
+
+	// receive "logging/setLevel" request
+	EventMcpRequestLoggingSetLevel(params *mcp.JsonRpcRequestLoggingSetLevelParams, reqId *jsonrpc.JsonRpcRequestId)
+
 	// receive "prompts/list" request
 	EventMcpRequestPromptsList(params *mcp.JsonRpcRequestPromptsListParams, reqId *jsonrpc.JsonRpcRequestId)
 
 	// receive "prompts/get" request
 	EventMcpRequestPromptsGet(params *mcp.JsonRpcRequestPromptsGetParams, reqId *jsonrpc.JsonRpcRequestId)
 
+	// receive "completion/complete" request
+	EventMcpRequestCompletionComplete(ctx context.Context, params *mcp.JsonRpcRequestCompletionCompleteParams, reqId *jsonrpc.JsonRpcRequestId)
+
 	// receive "error" notification
 	EventMcpError(code int, message string, data *json.RawMessage, id *jsonrpc.JsonRpcRequestId)
+}
 
+// MuxEvents are the events of the mux server, received from the proxies
+type MuxEvents interface {
 	// EventMuxProxyRegister
 	EventMuxRequestProxyRegister(proxyId string, params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId)
 
 	// EventMuxRequestToolsRegister
 	EventMuxRequestToolsRegister(proxyId string, params *mux.JsonRpcRequestToolsRegisterParams, reqId *jsonrpc.JsonRpcRequestId)
 
+	// EventMuxRequestPromptsRegister
+	EventMuxRequestPromptsRegister(proxyId string, params *mux.JsonRpcRequestPromptsRegisterParams, reqId *jsonrpc.JsonRpcRequestId)
+
+	// EventMuxProxyDisconnected is sent when the session of a registered proxy ends
+	EventMuxProxyDisconnected(proxyId string)
+
 	// EventMuxResponseToolCall
-	EventMuxResponseToolCall(toolsCallResult *mux.JsonRpcResponseToolsCallResult, reqId *jsonrpc.JsonRpcRequestId)
+	EventMuxResponseToolCall(proxyId string, toolsCallResult *mux.JsonRpcResponseToolsCallResult, reqId *jsonrpc.JsonRpcRequestId)
 
 	// EventMuxResponseToolCallError
-	EventMuxResponseToolCallError(error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId)
+	EventMuxResponseToolCallError(proxyId string, error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId)
+
+	// EventMuxNotificationMessage
+	EventMuxNotificationMessage(proxyId string, params *mux.JsonRpcNotificationMessageParams)
+
+	// EventMuxNotificationProgress
+	EventMuxNotificationProgress(proxyId string, params *mux.JsonRpcNotificationProgressParams)
 }
@@ -2,6 +2,7 @@ package hub
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
@@ -10,13 +11,13 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/hamstah/gomcp/channels/hub/events"
 	"github.com/hamstah/gomcp/channels/hubinspector"
-	"github.com/hamstah/gomcp/channels/hubmcpserver"
 	"github.com/hamstah/gomcp/channels/hubmuxserver"
 	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/defaults"
 	"github.com/hamstah/gomcp/logger"
 	"github.com/hamstah/gomcp/prompts"
+	"github.com/hamstah/gomcp/resources"
 	"github.com/hamstah/gomcp/tools"
 	"github.com/hamstah/gomcp/transport"
 	"github.com/hamstah/gomcp/types"
@@ -24,15 +25,16 @@ import (
 )
 
 type ModelContextProtocolImpl struct {
-	logging         *config.LoggingInfo
-	toolsRegistry   *tools.ToolsRegistry
-	promptsRegistry *prompts.PromptsRegistry
-	inspector       *hubinspector.Inspector
-	muxServer       *hubmuxserver.MuxServer
-	tools           []config.ToolConfig
-	stateManager    *StateManager
-	events          events.Events
-	logger          types.Logger
+	logging           *config.LoggingInfo
+	toolsRegistry     *tools.ToolsRegistry
+	resourcesRegistry *resources.ResourcesRegistry
+	promptsRegistry   *prompts.PromptsRegistry
+	inspector         *hubinspector.Inspector
+	muxServer         *hubmuxserver.MuxServer
+	tools             []config.ToolConfig
+	resources         []config.ResourceConfig
+	sessionManager    *SessionManager
+	logger            types.Logger
 }
 
 func newModelContextProtocolServer(
@@ -41,6 +43,8 @@ func newModelContextProtocolServer(
 	promptsConfig *config.PromptConfig,
 	inspectorConfig *config.InspectorInfo,
 	toolsConfig []config.ToolConfig,
+	resourcesConfig []config.ResourceConfig,
+	paginationConfig *config.PaginationInfo,
 	loadProxyTools bool,
 	proxyConfig *config.ServerProxyConfig) (*ModelContextProtocolImpl, error) {
 	// we initialize the logger
@@ -52,6 +56,9 @@ func newModelContextProtocolServer(
 	// Initialize tools registry
 	toolsRegistry := tools.NewToolsRegistry(loadProxyTools, logger)
 
+	// Initialize resources registry
+	resourcesRegistry := resources.NewResourcesRegistry(logger)
+
 	// Initialize prompts registry
 	promptsRegistry := prompts.NewEmptyPromptsRegistry()
 	if promptsConfig != nil {
@@ -61,15 +68,26 @@ func newModelContextProtocolServer(
 		}
 	}
 
-	// initialize the state manager
-	stateManager := NewStateManager(
+	// the list methods are paginated with the default page size
+	// unless it is set in the configuration
+	pageSize := defaults.DefaultListPageSize
+	if paginationConfig != nil {
+		pageSize = paginationConfig.PageSize
+	}
+
+	// initialize the session manager, it creates the state of each MCP session
+	sessionManager := NewSessionManager(
 		serverInfo.Name,
 		serverInfo.Version,
+		pageSize,
 		toolsRegistry,
+		resourcesRegistry,
 		promptsRegistry,
 		logger,
 	)
-	events := stateManager.AsEvents()
+
+	// the resource providers notify their changes to all the sessions
+	resourcesRegistry.SetNotificationHandlers(sessionManager.OnResourceUpdated, sessionManager.OnResourceListChanged)
 
 	// Start inspector if enabled
 	var inspectorInstance *hubinspector.Inspector = nil
@@ -80,19 +98,37 @@ func newModelContextProtocolServer(
 	// Start multiplexer if enabled
 	var muxServerInstance *hubmuxserver.MuxServer = nil
 	if proxyConfig != nil && proxyConfig.Enabled {
-		muxServerInstance = hubmuxserver.NewMuxServer(proxyConfig.ListenAddress, events, logger)
+		// the proxies authenticate with the key stored next to the hub configuration
+		muxKey, err := config.LoadOrCreateMuxKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the mux key: %v", err)
+		}
+		var muxTlsConfig *tls.Config = nil
+		if proxyConfig.Tls != nil {
+			muxTlsConfig, err = proxyConfig.Tls.ServerTlsConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure TLS on the mux link: %v", err)
+			}
+		}
+		muxServerInstance = hubmuxserver.NewMuxServer(proxyConfig.ListenAddress, muxTlsConfig, muxKey, proxyConfig.Policy, sessionManager.AsMuxEvents(), logger)
+	}
+
+	// the operators approve the pending proxies from the inspector
+	if inspectorInstance != nil && muxServerInstance != nil {
+		inspectorInstance.SetProxyApprovals(muxServerInstance)
 	}
 
 	return &ModelContextProtocolImpl{
-		logging:         logging,
-		toolsRegistry:   toolsRegistry,
-		promptsRegistry: promptsRegistry,
-		inspector:       inspectorInstance,
-		muxServer:       muxServerInstance,
-		stateManager:    stateManager,
-		tools:           toolsConfig,
-		events:          events,
-		logger:          logger,
+		logging:           logging,
+		toolsRegistry:     toolsRegistry,
+		resourcesRegistry: resourcesRegistry,
+		promptsRegistry:   promptsRegistry,
+		inspector:         inspectorInstance,
+		muxServer:         muxServerInstance,
+		sessionManager:    sessionManager,
+		tools:             toolsConfig,
+		resources:         resourcesConfig,
+		logger:            logger,
 	}, nil
 
 }
@@ -108,6 +144,7 @@ func NewHubModelContextProtocolServer(debug bool) (*ModelContextProtocolImpl, er
 	}
 
 	tools := []config.ToolConfig{}
+	resources := []config.ResourceConfig{}
 
 	return newModelContextProtocolServer(
 		&conf.ServerInfo,
@@ -115,6 +152,8 @@ func NewHubModelContextProtocolServer(debug bool) (*ModelContextProtocolImpl, er
 		conf.Prompts,
 		conf.Inspector,
 		tools,
+		resources,
+		conf.Pagination,
 		true,
 		conf.Proxy,
 	)
@@ -133,6 +172,8 @@ func NewModelContextProtocolServer(configFilePath string) (*ModelContextProtocol
 		conf.Prompts,
 		conf.Inspector,
 		conf.Tools,
+		conf.Resources,
+		conf.Pagination,
 		false,
 		nil,
 	)
@@ -157,6 +198,21 @@ func (mcp *ModelContextProtocolImpl) StdioTransport() types.Transport {
 	return transport
 }
 
+// StreamableHttpTransport serves the MCP clients over HTTP, each client gets its own session
+func (mcp *ModelContextProtocolImpl) StreamableHttpTransport(options types.StreamableHttpOptions) types.Transport {
+	return transport.NewStreamableHttpTransport(options, mcp.logger)
+}
+
+// SseTransport serves the MCP clients with the HTTP+SSE transport of the 2024-11-05 revision
+func (mcp *ModelContextProtocolImpl) SseTransport(options types.SseOptions) types.Transport {
+	return transport.NewSseServerTransport(options, mcp.logger)
+}
+
+// WebSocketTransport serves the MCP clients over WebSocket, each connection is a session
+func (mcp *ModelContextProtocolImpl) WebSocketTransport(options types.WebSocketOptions) types.Transport {
+	return transport.NewWebSocketServerTransport(options, mcp.logger)
+}
+
 func (mcp *ModelContextProtocolImpl) DeclareToolProvider(toolName string, toolInitFunction interface{}) (types.ToolProvider, error) {
 	toolProvider, err := tools.DeclareToolProvider(toolName, toolInitFunction)
 	if err != nil {
@@ -167,6 +223,16 @@ func (mcp *ModelContextProtocolImpl) DeclareToolProvider(toolName string, toolIn
 	return toolProvider, nil
 }
 
+func (mcp *ModelContextProtocolImpl) DeclareResourceProvider(providerName string, resourceInitFunction interface{}) (types.ResourceProvider, error) {
+	resourceProvider, err := resources.DeclareResourceProvider(providerName, resourceInitFunction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare resource provider %s: %v", providerName, err)
+	}
+	// we keep track of the resource providers added
+	mcp.resourcesRegistry.RegisterResourceProvider(resourceProvider)
+	return resourceProvider, nil
+}
+
 // Start starts the server and the inspector
 func (mcp *ModelContextProtocolImpl) Start(transport types.Transport) error {
 	mcp.logger.Info("Starting MCP server", types.LogArg{})
@@ -181,6 +247,12 @@ func (mcp *ModelContextProtocolImpl) Start(transport types.Transport) error {
 		return fmt.Errorf("error preparing tools registry: %s", err)
 	}
 
+	// same for the resources registry
+	err = mcp.resourcesRegistry.Prepare(ctx, mcp.resources)
+	if err != nil {
+		return fmt.Errorf("error preparing resources registry: %s", err)
+	}
+
 	mcp.logger.Info("Starting inspector", types.LogArg{})
 
 	// we create an errgroup that will be used to cancel
@@ -226,19 +298,33 @@ func (mcp *ModelContextProtocolImpl) Start(transport types.Transport) error {
 		})
 	}
 
+	// the sessions need the mux server to call the proxied tools
+	if mcp.muxServer != nil {
+		mcp.sessionManager.SetMuxServer(mcp.muxServer)
+	}
+
 	eg.Go(func() error {
 		mcp.logger.Info("[C] Starting MCP server", types.LogArg{})
 
-		// Initialize server
-		server := hubmcpserver.NewMCPServer(transport,
-			mcp.events,
-			mcp.logger)
-
-		// set the server in the state manager
-		mcp.stateManager.SetMcpServer(server)
-
-		// Start server
-		err := server.Start(egCtx)
+		var err error
+		if sessionTransport, ok := transport.(types.SessionTransport); ok {
+			// each client gets its own session, they end without stopping the server
+			sessionTransport.OnSession(func(session types.Transport) {
+				go func() {
+					err := mcp.sessionManager.StartSession(egCtx, session)
+					session.Close()
+					if err != nil && !errors.Is(err, context.Canceled) {
+						mcp.logger.Info("MCP session ended", types.LogArg{
+							"error": err,
+						})
+					}
+				}()
+			})
+			err = sessionTransport.Start(egCtx)
+		} else {
+			// a single client, the server stops when it disconnects
+			err = mcp.sessionManager.StartSession(egCtx, transport)
+		}
 		if err != nil {
 			// check if the error is because the context was cancelled
 			if errors.Is(err, context.Canceled) {
@@ -257,7 +343,6 @@ func (mcp *ModelContextProtocolImpl) Start(transport types.Transport) error {
 	if mcp.muxServer != nil {
 		eg.Go(func() error {
 			mcp.logger.Info("[D] Starting mux server", types.LogArg{})
-			mcp.stateManager.SetMuxServer(mcp.muxServer)
 
 			err := mcp.muxServer.Start(egCtx)
 			if err != nil {
@@ -327,6 +412,18 @@ func (mcp *ModelContextProtocolImpl) GetToolRegistry() types.ToolRegistry {
 	return mcp
 }
 
+func (mcp *ModelContextProtocolImpl) GetResourceRegistry() types.ResourceRegistry {
+	return mcp
+}
+
+func (mcp *ModelContextProtocolImpl) GetPromptRegistry() types.PromptRegistry {
+	return mcp
+}
+
+func (mcp *ModelContextProtocolImpl) AddPromptArgumentCompletion(promptName string, argumentName string, completionHandler types.CompletionHandler) error {
+	return mcp.promptsRegistry.AddPromptArgumentCompletion(promptName, argumentName, completionHandler)
+}
+
 func logGoroutineStacks(logger types.Logger) {
 	// Get number of goroutines
 	numGoroutines := runtime.NumGoroutine()
@@ -0,0 +1,372 @@
+package hub
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/hamstah/gomcp/channels/hub/events"
+	"github.com/hamstah/gomcp/channels/hubmcpserver"
+	"github.com/hamstah/gomcp/channels/hubmuxserver"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/prompts"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/resources"
+	"github.com/hamstah/gomcp/tools"
+	"github.com/hamstah/gomcp/types"
+)
+
+// SessionManager keeps the state of the MCP sessions, there is a single session
+// with the stdio transport and one per client with the session transports (eg HTTP).
+// The registries and the proxies are shared by the sessions, their events are
+// dispatched to the sessions concerned.
+type SessionManager struct {
+	serverName        string
+	serverVersion     string
+	pageSize          int
+	toolsRegistry     *tools.ToolsRegistry
+	resourcesRegistry *resources.ResourcesRegistry
+	promptsRegistry   *prompts.PromptsRegistry
+	muxServer         *hubmuxserver.MuxServer
+	logger            types.Logger
+
+	sessions      map[*StateManager]bool
+	sessionsMutex sync.Mutex
+
+	// roots sent to the proxies, see updateProxyRoots
+	proxyRoots      []mcp.Root
+	proxyRootsMutex sync.Mutex
+}
+
+func NewSessionManager(
+	serverName string,
+	serverVersion string,
+	pageSize int,
+	toolsRegistry *tools.ToolsRegistry,
+	resourcesRegistry *resources.ResourcesRegistry,
+	promptsRegistry *prompts.PromptsRegistry,
+	logger types.Logger,
+) *SessionManager {
+	return &SessionManager{
+		serverName:        serverName,
+		serverVersion:     serverVersion,
+		pageSize:          pageSize,
+		toolsRegistry:     toolsRegistry,
+		resourcesRegistry: resourcesRegistry,
+		promptsRegistry:   promptsRegistry,
+		logger:            logger,
+		sessions:          map[*StateManager]bool{},
+		proxyRoots:        []mcp.Root{},
+	}
+}
+
+func (m *SessionManager) SetMuxServer(server *hubmuxserver.MuxServer) {
+	m.muxServer = server
+}
+
+func (m *SessionManager) AsMuxEvents() events.MuxEvents {
+	return m
+}
+
+// StartSession serves an MCP client on the transport, it returns when
+// the client disconnects or the context is cancelled
+func (m *SessionManager) StartSession(ctx context.Context, transport types.Transport) error {
+	// the tool calls of the client and their requests end with its session
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stateManager := NewStateManager(
+		m.serverName,
+		m.serverVersion,
+		m.pageSize,
+		m.toolsRegistry,
+		m.resourcesRegistry,
+		m.promptsRegistry,
+		m.logger,
+	)
+	stateManager.sessions = m
+	stateManager.SetMuxServer(m.muxServer)
+
+	server := hubmcpserver.NewMCPServer(transport, stateManager.AsEvents(), m.logger)
+	stateManager.SetMcpServer(server)
+
+	m.sessionsMutex.Lock()
+	m.sessions[stateManager] = true
+	m.sessionsMutex.Unlock()
+	m.updateProxyRoots()
+
+	defer func() {
+		m.sessionsMutex.Lock()
+		delete(m.sessions, stateManager)
+		m.sessionsMutex.Unlock()
+		m.updateProxyRoots()
+	}()
+
+	return server.Start(ctx)
+}
+
+// getSessions returns a snapshot of the sessions in progress
+func (m *SessionManager) getSessions() []*StateManager {
+	m.sessionsMutex.Lock()
+	defer m.sessionsMutex.Unlock()
+	sessions := make([]*StateManager, 0, len(m.sessions))
+	for session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// OnResourceUpdated is called by the resources registry when a resource provider
+// notifies a change, the sessions subscribed to the resource are notified
+func (m *SessionManager) OnResourceUpdated(uri string) {
+	for _, session := range m.getSessions() {
+		session.OnResourceUpdated(uri)
+	}
+}
+
+// OnResourceListChanged is called by the resources registry when a resource provider
+// notifies that its list of resources changed
+func (m *SessionManager) OnResourceListChanged() {
+	for _, session := range m.getSessions() {
+		session.OnResourceListChanged()
+	}
+}
+
+// updateProxyRoots sends the roots to the proxies when they change. The proxies are
+// shared by the sessions, they only get the roots of the client when it is the only
+// one connected (eg with stdio). With several clients they get no roots, the roots
+// of a client must not be seen by the tools called by another one.
+func (m *SessionManager) updateProxyRoots() {
+	roots := []mcp.Root{}
+	if sessions := m.getSessions(); len(sessions) == 1 {
+		if sessionRoots := sessions[0].getRoots(); sessionRoots != nil {
+			roots = sessionRoots
+		}
+	}
+
+	m.proxyRootsMutex.Lock()
+	defer m.proxyRootsMutex.Unlock()
+	if reflect.DeepEqual(roots, m.proxyRoots) {
+		return
+	}
+	m.proxyRoots = roots
+	if m.muxServer != nil {
+		for _, session := range m.muxServer.GetRegisteredSessions() {
+			sendRoots(session, roots)
+		}
+	}
+}
+
+// sendRootsToProxy sends the roots to a proxy that just registered,
+// so that it can answer the roots/list requests of its MCP server
+func (m *SessionManager) sendRootsToProxy(session *hubmuxserver.MuxSession) {
+	m.proxyRootsMutex.Lock()
+	defer m.proxyRootsMutex.Unlock()
+	// the proxies start without roots
+	if len(m.proxyRoots) == 0 {
+		return
+	}
+	sendRoots(session, m.proxyRoots)
+}
+
+func sendRoots(session *hubmuxserver.MuxSession, roots []mcp.Root) {
+	params := &mux.JsonRpcNotificationRootsUpdatedParams{
+		Roots: make([]mux.Root, 0, len(roots)),
+	}
+	for _, root := range roots {
+		params.Roots = append(params.Roots, mux.Root{
+			Uri:  root.Uri,
+			Name: root.Name,
+		})
+	}
+	session.SendNotificationWithParams(mux.RpcNotificationMethodRootsUpdated, params)
+}
+
+func (m *SessionManager) EventMuxRequestProxyRegister(proxyId string, params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// we need to store the proxy id in the session
+	m.logger.Info("@@ EventMuxRequestProxyRegister", types.LogArg{
+		"proxyId":     proxyId,
+		"m.muxServer": m.muxServer == nil,
+	})
+	session := m.muxServer.GetSessionByProxyId(proxyId)
+	if session == nil {
+		m.logger.Error("session not found", types.LogArg{
+			"proxyId": proxyId,
+		})
+		return
+	}
+	session.SetSessionInformation(proxyId, params.ServerInfo.Name)
+
+	// the mux session applied the policy, the proxy is accepted
+	result := mux.JsonRpcResponseProxyRegisterResult{
+		SessionId:  session.SessionId(),
+		ProxyId:    proxyId,
+		Persistent: params.Persistent,
+		Denied:     false,
+	}
+	session.SendJsonRpcResponse(&result, reqId)
+
+	// the proxy needs the roots of the client for its MCP server
+	m.sendRootsToProxy(session)
+}
+
+func (m *SessionManager) EventMuxRequestToolsRegister(proxyId string, params *mux.JsonRpcRequestToolsRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// we need to store the proxy id in the session
+	session := m.muxServer.GetSessionByProxyId(proxyId)
+	if session == nil {
+		m.logger.Error("session not found", types.LogArg{
+			"proxyId": proxyId,
+		})
+		return
+	}
+
+	toolProvider, err := m.toolsRegistry.RegisterProxyToolProvider(proxyId, session.ProxyName())
+	if err != nil {
+		m.logger.Error("Failed to register proxy tool provider", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+	for _, tool := range params.Tools {
+		err := toolProvider.AddProxyTool(tools.ProxyToolDefinition{
+			Name:        tool.Name,
+			Title:       tool.Title,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
+		})
+		if err != nil {
+			m.logger.Error("Failed to add proxy tool", types.LogArg{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	// we need to prepare the tool provider so that it can be used by the hub
+	err = m.toolsRegistry.PrepareProxyToolProvider(toolProvider)
+	if err != nil {
+		m.logger.Error("Failed to prepare proxy tool provider", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+
+	// send the notification to the MCP clients
+	// so that they will refresh the tools list
+	for _, session := range m.getSessions() {
+		session.notifyToolsListChanged()
+	}
+}
+
+func (m *SessionManager) EventMuxRequestPromptsRegister(proxyId string, params *mux.JsonRpcRequestPromptsRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	proxyPrompts := make([]prompts.PromptDefinition, 0, len(params.Prompts))
+	for _, prompt := range params.Prompts {
+		arguments := make([]prompts.ArgumentDefinition, 0, len(prompt.Arguments))
+		for _, argument := range prompt.Arguments {
+			arguments = append(arguments, prompts.ArgumentDefinition{
+				Name:        argument.Name,
+				Description: argument.Description,
+				Required:    argument.Required,
+			})
+		}
+		proxyPrompts = append(proxyPrompts, prompts.PromptDefinition{
+			Name:        prompt.Name,
+			Description: prompt.Description,
+			Arguments:   arguments,
+		})
+	}
+	skipped := m.promptsRegistry.SetProxyPrompts(proxyId, proxyPrompts)
+	if len(skipped) > 0 {
+		m.logger.Error("prompts of the proxy ignored, their names are already used", types.LogArg{
+			"proxyId": proxyId,
+			"prompts": skipped,
+		})
+	}
+	if session := m.muxServer.GetSessionByProxyId(proxyId); session != nil {
+		session.SendJsonRpcResponse(&mux.JsonRpcResponsePromptsRegisterResult{}, reqId)
+	}
+
+	// the clients refresh the prompts list
+	for _, session := range m.getSessions() {
+		session.notifyPromptsListChanged()
+	}
+}
+
+// EventMuxProxyDisconnected removes the prompts of the proxy, they can't be forwarded anymore
+func (m *SessionManager) EventMuxProxyDisconnected(proxyId string) {
+	// the proxy may have registered again with a new session
+	if m.muxServer.GetSessionByProxyId(proxyId) != nil {
+		return
+	}
+	if !m.promptsRegistry.RemoveProxyPrompts(proxyId) {
+		return
+	}
+	for _, session := range m.getSessions() {
+		session.notifyPromptsListChanged()
+	}
+}
+
+func (m *SessionManager) EventMuxResponseToolCall(proxyId string, toolsCallResult *mux.JsonRpcResponseToolsCallResult, reqId *jsonrpc.JsonRpcRequestId) {
+	for _, session := range m.getSessions() {
+		if mcpReqId := session.takeProxiedToolCall(proxyId, reqId); mcpReqId != nil {
+			session.sendProxiedToolCallResult(toolsCallResult, mcpReqId)
+			return
+		}
+	}
+	// the tool call was cancelled by the client
+	m.logger.Info("dropping response of cancelled tool call", types.LogArg{
+		"reqId": jsonrpc.RequestIdToString(reqId),
+	})
+}
+
+func (m *SessionManager) EventMuxResponseToolCallError(proxyId string, error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId) {
+	for _, session := range m.getSessions() {
+		if mcpReqId := session.takeProxiedToolCall(proxyId, reqId); mcpReqId != nil {
+			session.mcpServer.SendError(error.Code, error.Message, mcpReqId)
+			return
+		}
+	}
+	// the tool call was cancelled by the client
+	m.logger.Info("dropping error of cancelled tool call", types.LogArg{
+		"reqId": jsonrpc.RequestIdToString(reqId),
+	})
+}
+
+func (m *SessionManager) EventMuxNotificationMessage(proxyId string, params *mux.JsonRpcNotificationMessageParams) {
+	// the message is tagged with the name of the proxy
+	loggerName := proxyId
+	session := m.muxServer.GetSessionByProxyId(proxyId)
+	if session != nil && session.ProxyName() != "" {
+		loggerName = session.ProxyName()
+	}
+	if params.Logger != "" {
+		loggerName = loggerName + "/" + params.Logger
+	}
+	// the proxy is shared, its logs are only sent to the clients calling its tools
+	sent := false
+	for _, session := range m.getSessions() {
+		if session.hasProxiedToolCall(proxyId) {
+			session.sendLogMessage(params.Level, loggerName, params.Data)
+			sent = true
+		}
+	}
+	if !sent {
+		m.logger.Info("log message of the proxy", types.LogArg{
+			"logger": loggerName,
+			"level":  params.Level,
+			"data":   params.Data,
+		})
+	}
+}
+
+func (m *SessionManager) EventMuxNotificationProgress(proxyId string, params *mux.JsonRpcNotificationProgressParams) {
+	// the progress token was generated by the session calling the tool
+	for _, session := range m.getSessions() {
+		if progressToken := session.findProxiedProgressToken(params.ProgressToken); progressToken != nil {
+			session.sendProgress(progressToken, params.Progress, params.Total, params.Message)
+			return
+		}
+	}
+}
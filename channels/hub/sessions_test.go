@@ -0,0 +1,194 @@
+package hub
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/channels/hubmuxserver"
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/prompts"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/resources"
+	"github.com/hamstah/gomcp/tools"
+	"github.com/hamstah/gomcp/types"
+)
+
+type waitConfig struct{}
+
+type waitContext struct{}
+
+type waitInput struct{}
+
+// newWaitToolsRegistry returns a registry with the tool wait, it blocks until its
+// context is cancelled and then closes the channel
+func newWaitToolsRegistry(t *testing.T, cancelled chan struct{}) *tools.ToolsRegistry {
+	t.Helper()
+	// the providers are only initialized with a configuration
+	provider, err := tools.DeclareToolProvider("test", func(ctx context.Context, waitConfig *waitConfig) (*waitContext, error) {
+		return &waitContext{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = provider.AddTool("wait", "Wait until cancelled", func(ctx context.Context, waitCtx *waitContext, input *waitInput, output types.ToolCallResult) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry := tools.NewToolsRegistry(false, nopLogger{})
+	registry.RegisterToolProvider(provider)
+	if err := registry.Prepare(context.Background(), []config.ToolConfig{{Name: "test", Configuration: map[string]interface{}{}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return registry
+}
+
+func TestProxyRootsOfASingleClient(t *testing.T) {
+	sessions := NewSessionManager("hub", "1.0", 0, tools.NewToolsRegistry(false, nopLogger{}), resources.NewResourcesRegistry(nopLogger{}), prompts.NewEmptyPromptsRegistry(), nopLogger{})
+	addSession := func(roots []mcp.Root) *StateManager {
+		session := NewStateManager("hub", "1.0", 0, sessions.toolsRegistry, sessions.resourcesRegistry, sessions.promptsRegistry, nopLogger{})
+		session.sessions = sessions
+		session.roots = roots
+		sessions.sessionsMutex.Lock()
+		sessions.sessions[session] = true
+		sessions.sessionsMutex.Unlock()
+		sessions.updateProxyRoots()
+		return session
+	}
+	removeSession := func(session *StateManager) {
+		sessions.sessionsMutex.Lock()
+		delete(sessions.sessions, session)
+		sessions.sessionsMutex.Unlock()
+		sessions.updateProxyRoots()
+	}
+	checkRoots := func(step string, want []mcp.Root) {
+		t.Helper()
+		sessions.proxyRootsMutex.Lock()
+		defer sessions.proxyRootsMutex.Unlock()
+		if !reflect.DeepEqual(sessions.proxyRoots, want) {
+			t.Errorf("%s: expected the proxy roots %v, got %v", step, want, sessions.proxyRoots)
+		}
+	}
+
+	first := addSession([]mcp.Root{{Uri: "file:///first"}})
+	checkRoots("single client", []mcp.Root{{Uri: "file:///first"}})
+
+	// the roots of the first client are not kept for the second one
+	second := addSession([]mcp.Root{{Uri: "file:///second"}})
+	checkRoots("two clients", []mcp.Root{})
+
+	removeSession(first)
+	checkRoots("second client left", []mcp.Root{{Uri: "file:///second"}})
+
+	removeSession(second)
+	checkRoots("no client", []mcp.Root{})
+}
+
+func TestSessionEndCancelsItsRequests(t *testing.T) {
+	cancelled := make(chan struct{})
+	sessions := NewSessionManager("hub", "1.0", 0, newWaitToolsRegistry(t, cancelled), resources.NewResourcesRegistry(nopLogger{}), prompts.NewEmptyPromptsRegistry(), nopLogger{})
+	client := newFakeClient(nil)
+	ended := make(chan error, 1)
+	go func() {
+		ended <- sessions.StartSession(context.Background(), client)
+	}()
+	<-client.started
+	initialize(client, mcp.ProtocolVersion, `{}`)
+
+	// a tool call and a request to the client are in progress
+	client.receive(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait","arguments":{}}}`)
+	session := sessions.getSessions()[0]
+	waitErr := make(chan error, 1)
+	go func() {
+		_, err := session.mcpServer.SendRequestAndWaitResponse(context.Background(), mcp.RpcRequestMethodElicitationCreate, &mcp.JsonRpcRequestElicitationCreateParams{})
+		waitErr <- err
+	}()
+
+	// the client disconnects
+	client.Close()
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the session did not end")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the tool call was not cancelled")
+	}
+	select {
+	case err := <-waitErr:
+		if err == nil {
+			t.Errorf("expected the request to the client to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the request to the client still waits for its response")
+	}
+	if len(sessions.getSessions()) != 0 {
+		t.Errorf("expected the session to be removed")
+	}
+}
+
+func TestProxyLogMessages(t *testing.T) {
+	sessions := NewSessionManager("hub", "1.0", 0, tools.NewToolsRegistry(false, nopLogger{}), resources.NewResourcesRegistry(nopLogger{}), prompts.NewEmptyPromptsRegistry(), nopLogger{})
+	sessions.SetMuxServer(hubmuxserver.NewMuxServer("", nil, nil, nil, sessions.AsMuxEvents(), nopLogger{}))
+	addClient := func() (*StateManager, *fakeClient) {
+		client := newFakeClient(nil)
+		session := newTestSession(t, client)
+		initialize(client, mcp.ProtocolVersion, `{}`)
+		session.sessions = sessions
+		sessions.sessionsMutex.Lock()
+		sessions.sessions[session] = true
+		sessions.sessionsMutex.Unlock()
+		return session, client
+	}
+	caller, callerClient := addClient()
+	_, otherClient := addClient()
+
+	// the first client calls a tool of the proxy
+	proxy := newFakeClient(nil)
+	muxSession := hubmuxserver.NewMuxSession("s-001", proxy, nopLogger{}, nil, nil, nil, false)
+	muxSession.SetSessionInformation("proxy-1", "server")
+	muxReqId := muxSession.GetNextRequestId()
+	reqId := 1
+	caller.startToolCall(&jsonrpc.JsonRpcRequestId{Number: &reqId}, &toolCall{
+		reqId:    &jsonrpc.JsonRpcRequestId{Number: &reqId},
+		session:  muxSession,
+		muxReqId: muxReqId,
+	})
+
+	tests := []struct {
+		name            string
+		level           string
+		wantCaller      int
+		wantOtherClient int
+	}{
+		{"sent to the caller", "info", 1, 0},
+		{"filtered by the level of the caller", "debug", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions.EventMuxNotificationMessage("proxy-1", &mux.JsonRpcNotificationMessageParams{Level: tt.level, Data: "message"})
+			if count := countMethod(callerClient.take(), mcp.RpcNotificationMethodMessage); count != tt.wantCaller {
+				t.Errorf("expected %d messages for the caller, got %d", tt.wantCaller, count)
+			}
+			if count := countMethod(otherClient.take(), mcp.RpcNotificationMethodMessage); count != tt.wantOtherClient {
+				t.Errorf("expected %d messages for the other client, got %d", tt.wantOtherClient, count)
+			}
+		})
+	}
+
+	// once the tool call ended the logs of the proxy are not sent anymore
+	caller.takeProxiedToolCall("proxy-1", muxReqId)
+	sessions.EventMuxNotificationMessage("proxy-1", &mux.JsonRpcNotificationMessageParams{Level: "info", Data: "message"})
+	if count := countMethod(callerClient.take(), mcp.RpcNotificationMethodMessage); count != 0 {
+		t.Errorf("expected no message after the tool call, got %d", count)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hamstah/gomcp/channels/hub/events"
 	"github.com/hamstah/gomcp/channels/hubmcpserver"
 	"github.com/hamstah/gomcp/channels/hubmuxserver"
@@ -12,6 +16,7 @@ import (
 	"github.com/hamstah/gomcp/prompts"
 	"github.com/hamstah/gomcp/protocol/mcp"
 	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/resources"
 	"github.com/hamstah/gomcp/tools"
 	"github.com/hamstah/gomcp/types"
 )
@@ -22,36 +27,86 @@ type ClientInfo struct {
 	version string
 }
 
+// maximum time to wait for the client to answer roots/list
+const rootsListTimeout = 30 * time.Second
+
+// maximum time to wait for a proxy to answer prompts/get and completion/complete
+const proxyRequestTimeout = 30 * time.Second
+
+// tool call in progress, it can be cancelled by the client
+type toolCall struct {
+	// mcp request id of the call
+	reqId *jsonrpc.JsonRpcRequestId
+	// cancels the context of a local tool call
+	cancel context.CancelFunc
+	// session and mux request id of a proxied tool call
+	session  *hubmuxserver.MuxSession
+	muxReqId *jsonrpc.JsonRpcRequestId
+	// the proxies are shared by the sessions, the progress token of the
+	// client is replaced by a unique one for the proxied tool calls
+	progressToken    interface{}
+	muxProgressToken string
+}
+
 type StateManager struct {
 	// mcp related state
-	serverName          string
-	serverVersion       string
-	clientInfo          *ClientInfo
-	isClientInitialized bool
+	serverName    string
+	serverVersion string
+	clientInfo    *ClientInfo
+	// capabilities declared by the client during initialization
+	clientCapabilities mcp.ClientCapabilities
+	// protocol version agreed with the client during initialization
+	protocolVersion string
+	pageSize        int
+	// set by the read loop, read by the providers and proxies notifying the client
+	isClientInitialized atomic.Bool
 	toolsRegistry       *tools.ToolsRegistry
+	resourcesRegistry   *resources.ResourcesRegistry
 	promptsRegistry     *prompts.PromptsRegistry
 
-	logger       types.Logger
-	mcpServer    *hubmcpserver.MCPServer
-	muxServer    *hubmuxserver.MuxServer
-	reqIdMapping *jsonrpc.ReqIdMapping
+	// resources the client subscribed to
+	resourceSubscriptions      map[string]bool
+	resourceSubscriptionsMutex sync.Mutex
+
+	// tool calls in progress, by mcp request id
+	toolCalls      map[string]*toolCall
+	toolCallsMutex sync.Mutex
+
+	// roots of the client, nil until received
+	roots      []mcp.Root
+	rootsMutex sync.Mutex
+
+	// minimum level of the log messages sent to the client
+	loggingLevel      string
+	loggingLevelMutex sync.Mutex
+
+	logger    types.Logger
+	mcpServer *hubmcpserver.MCPServer
+	muxServer *hubmuxserver.MuxServer
+	// the session manager owning that session
+	sessions *SessionManager
 }
 
 func NewStateManager(
 	serverName string,
 	serverVersion string,
+	pageSize int,
 	toolsRegistry *tools.ToolsRegistry,
+	resourcesRegistry *resources.ResourcesRegistry,
 	promptsRegistry *prompts.PromptsRegistry,
 	logger types.Logger,
 ) *StateManager {
 	return &StateManager{
-		serverName:          serverName,
-		serverVersion:       serverVersion,
-		isClientInitialized: false,
-		toolsRegistry:       toolsRegistry,
-		promptsRegistry:     promptsRegistry,
-		logger:              logger,
-		reqIdMapping:        jsonrpc.NewReqIdMapping(),
+		serverName:            serverName,
+		serverVersion:         serverVersion,
+		pageSize:              pageSize,
+		toolsRegistry:         toolsRegistry,
+		resourcesRegistry:     resourcesRegistry,
+		promptsRegistry:       promptsRegistry,
+		resourceSubscriptions: map[string]bool{},
+		toolCalls:             map[string]*toolCall{},
+		loggingLevel:          mcp.LoggingLevelInfo,
+		logger:                logger,
 	}
 }
 
@@ -63,26 +118,34 @@ func (s *StateManager) SetMuxServer(server *hubmuxserver.MuxServer) {
 	s.muxServer = server
 }
 
-func (s *StateManager) AsEvents() events.Events {
+func (s *StateManager) AsEvents() events.McpEvents {
 	return s
 }
 
 func (s *StateManager) EventMcpRequestInitialize(params *mcp.JsonRpcRequestInitializeParams, reqId *jsonrpc.JsonRpcRequestId) {
-	// store client information
-	if params.ProtocolVersion != mcp.ProtocolVersion {
-		s.logger.Error("protocol version mismatch", types.LogArg{
-			"expected": mcp.ProtocolVersion,
-			"received": params.ProtocolVersion,
+	// we answer with the client version if we support it, otherwise
+	// with our latest version and the client decides if it can use it
+	s.protocolVersion = mcp.NegotiateProtocolVersion(params.ProtocolVersion)
+	if s.protocolVersion != params.ProtocolVersion {
+		s.logger.Info("unsupported protocol version requested by the client", types.LogArg{
+			"requested":  params.ProtocolVersion,
+			"negotiated": s.protocolVersion,
+			"supported":  mcp.SupportedProtocolVersions(),
 		})
 	}
+	// JSON-RPC batches are only allowed by 2025-03-26
+	s.mcpServer.SetBatchesEnabled(s.supportsFeature(mcp.FeatureJsonRpcBatch))
+
+	// store client information
 	s.clientInfo = &ClientInfo{
 		name:    params.ClientInfo.Name,
 		version: params.ClientInfo.Version,
 	}
+	s.clientCapabilities = params.Capabilities
 
 	// prepare response
 	response := mcp.JsonRpcResponseInitializeResult{
-		ProtocolVersion: mcp.ProtocolVersion,
+		ProtocolVersion: s.protocolVersion,
 		Capabilities: mcp.ServerCapabilities{
 			Tools: &mcp.ServerCapabilitiesTools{
 				ListChanged: jsonrpc.BoolPtr(true),
@@ -90,44 +153,167 @@ func (s *StateManager) EventMcpRequestInitialize(params *mcp.JsonRpcRequestIniti
 			Prompts: &mcp.ServerCapabilitiesPrompts{
 				ListChanged: jsonrpc.BoolPtr(true),
 			},
+			Logging: &mcp.ServerCapabilitiesLogging{},
+			Resources: &mcp.ServerCapabilitiesResources{
+				ListChanged: jsonrpc.BoolPtr(true),
+				Subscribe:   jsonrpc.BoolPtr(true),
+			},
 		},
 		ServerInfo: mcp.ServerInfo{Name: s.serverName, Version: s.serverVersion},
 	}
+	// the completions capability was added in 2025-03-26
+	if s.supportsFeature(mcp.FeatureCompletions) {
+		response.Capabilities.Completions = &mcp.ServerCapabilitiesCompletions{}
+	}
 	s.mcpServer.SendJsonRpcResponse(&response, reqId)
 
 }
 
+// supportsFeature checks if a version specific behavior is available
+// with the protocol version agreed with the client
+func (s *StateManager) supportsFeature(feature mcp.ProtocolFeature) bool {
+	return mcp.SupportsFeature(s.protocolVersion, feature)
+}
+
 func (s *StateManager) EventMcpNotificationInitialized() {
 	// that's a notification, no response is needed
-	s.isClientInitialized = true
+	s.isClientInitialized.Store(true)
+
+	// the client can now receive our requests
+	if s.clientCapabilities.Roots != nil {
+		go s.refreshRoots()
+	}
+}
+
+func (s *StateManager) EventMcpNotificationRootsListChanged() {
+	if s.clientCapabilities.Roots == nil {
+		return
+	}
+	go s.refreshRoots()
+}
+
+// refreshRoots queries the roots of the client and sends them to the proxies
+func (s *StateManager) refreshRoots() {
+	ctx, cancel := context.WithTimeout(context.Background(), rootsListTimeout)
+	defer cancel()
+
+	response, err := s.mcpServer.SendRequestAndWaitResponse(ctx, mcp.RpcRequestMethodRootsList, &mcp.JsonRpcRequestRootsListParams{})
+	if err != nil {
+		s.logger.Error("failed to list the roots of the client", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+	if response.Error != nil {
+		s.logger.Error("failed to list the roots of the client", types.LogArg{
+			"code":    response.Error.Code,
+			"message": response.Error.Message,
+		})
+		return
+	}
+	result, err := mcp.ParseJsonRpcResponseRootsList(response)
+	if err != nil {
+		s.logger.Error("failed to parse the roots of the client", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+	s.logger.Info("roots of the client", types.LogArg{
+		"roots": result.Roots,
+	})
+
+	s.rootsMutex.Lock()
+	s.roots = result.Roots
+	s.rootsMutex.Unlock()
+
+	s.sessions.updateProxyRoots()
+}
+
+// getRoots returns a copy of the roots of the client, it is called
+// by gomcp.GetRoots during a tool call
+func (s *StateManager) getRoots() []mcp.Root {
+	s.rootsMutex.Lock()
+	defer s.rootsMutex.Unlock()
+	if s.roots == nil {
+		return nil
+	}
+	roots := make([]mcp.Root, len(s.roots))
+	copy(roots, s.roots)
+	return roots
 }
 
 func (s *StateManager) EventMcpRequestToolsList(params *mcp.JsonRpcRequestToolsListParams, reqId *jsonrpc.JsonRpcRequestId) {
 	// we query the tools registry
 	tools := s.toolsRegistry.GetListOfTools()
 
+	// we only send the requested page
+	start, end, nextCursor, err := mcp.Paginate(mcp.RpcRequestMethodToolsList, params.Cursor, len(tools), s.pageSize)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, err.Error(), reqId)
+		return
+	}
+
 	var response = mcp.JsonRpcResponseToolsListResult{
-		Tools: make([]mcp.ToolDescription, 0, len(tools)),
+		Tools:      make([]mcp.ToolDescription, 0, end-start),
+		NextCursor: nextCursor,
 	}
 
 	// we build the response
-	for _, tool := range tools {
+	for _, tool := range tools[start:end] {
 		// schemaBytes, _ := json.Marshal(tool.InputSchema)
-		response.Tools = append(response.Tools, mcp.ToolDescription{
+		toolDescription := mcp.ToolDescription{
 			Name:        tool.ToolName,
 			Description: tool.Description,
 			InputSchema: tool.InputSchema,
-		})
+		}
+		// the tool title was added in 2025-06-18
+		if s.supportsFeature(mcp.FeatureTitle) {
+			toolDescription.Title = tool.Title
+		}
+		// the annotations were added in 2025-03-26
+		if s.supportsFeature(mcp.FeatureToolAnnotations) {
+			toolDescription.Annotations = toMcpToolAnnotations(tool.Title, tool.Annotations)
+		}
+		// the output schema was added in 2025-06-18
+		if tool.OutputSchema != nil && s.supportsFeature(mcp.FeatureStructuredOutput) {
+			toolDescription.OutputSchema = tool.OutputSchema
+		}
+		response.Tools = append(response.Tools, toolDescription)
 	}
 
 	s.mcpServer.SendJsonRpcResponse(&response, reqId)
 }
 
+// toMcpToolAnnotations builds the annotations sent to the client,
+// the title is repeated for clients that don't support the tool title
+func toMcpToolAnnotations(title string, annotations *types.ToolAnnotations) *mcp.ToolAnnotations {
+	if title == "" && annotations == nil {
+		return nil
+	}
+	mcpAnnotations := &mcp.ToolAnnotations{}
+	if title != "" {
+		mcpAnnotations.Title = &title
+	}
+	if annotations != nil {
+		mcpAnnotations.ReadOnlyHint = annotations.ReadOnlyHint
+		mcpAnnotations.DestructiveHint = annotations.DestructiveHint
+		mcpAnnotations.IdempotentHint = annotations.IdempotentHint
+		mcpAnnotations.OpenWorldHint = annotations.OpenWorldHint
+	}
+	return mcpAnnotations
+}
+
 func (s *StateManager) EventMcpRequestToolsCall(ctx context.Context, params *mcp.JsonRpcRequestToolsCallParams, reqId *jsonrpc.JsonRpcRequestId) {
 	// we get the tool name and arguments
 	toolName := params.Name
 	toolArgs := params.Arguments
 
+	// the client asked for progress notifications
+	var progressToken interface{}
+	if params.Meta != nil {
+		progressToken = params.Meta.ProgressToken
+	}
+
 	// let's check if the tool exists and is a proxy
 	isProxy, proxyId, err := s.toolsRegistry.IsProxyTool(toolName)
 	if err != nil {
@@ -142,48 +328,388 @@ func (s *StateManager) EventMcpRequestToolsCall(ctx context.Context, params *mcp
 			s.mcpServer.SendError(jsonrpc.RpcInternalError, "session not found", reqId)
 			return
 		}
+		call := &toolCall{reqId: reqId, session: session}
 		// we send the request to the proxy
+		// the proxy sends the progress notifications with the token we give it
 		params := &mux.JsonRpcRequestToolsCallParams{
 			Name: toolName,
 			Args: toolArgs,
 		}
-		// we send the request to the proxy
-		// we keep track of the request id for that tool call in the session extra parameters
-		muxReqId, err := session.SendRequestWithMethodAndParams(mux.RpcRequestMethodCallTool, params)
+		if progressToken != nil {
+			call.progressToken = progressToken
+			call.muxProgressToken = uuid.New().String()
+			params.ProgressToken = call.muxProgressToken
+		}
+		// the call is tracked before sending the request so that the response
+		// can't be handled before, the lock is not held while sending
+		call.muxReqId = session.GetNextRequestId()
+		s.startToolCall(reqId, call)
+		err := session.SendRequestWithIdMethodAndParams(call.muxReqId, mux.RpcRequestMethodCallTool, params)
 		if err != nil {
+			s.endToolCall(reqId)
 			s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("failed to send request to proxy: %v", err), reqId)
 			return
 		}
-		// we keep track of the mapping between the mcp request id
-		// and the mux request id
-		s.reqIdMapping.AddMapping(muxReqId, reqId)
 	} else {
 		// this is a direct tool call (SDK built-in tool)
-		// let's call the tool
-		response, err := s.toolsRegistry.CallTool(ctx, toolName, toolArgs)
-		if err != nil {
-			s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("tool call failed: %v", err), reqId)
+		if progressToken != nil {
+			ctx = tools.MakeContextWithProgressReporter(ctx, s.newProgressReporter(progressToken))
+		}
+		ctx = tools.MakeContextWithLogForwarder(ctx, s.OnLogMessage)
+		ctx = tools.MakeContextWithMessageCreator(ctx, s.createMessage)
+		ctx = tools.MakeContextWithElicitor(ctx, s.elicit)
+		ctx = tools.MakeContextWithRootsProvider(ctx, s.getRoots)
+		// the tool runs in its own goroutine with a context
+		// cancelled when the client cancels the request
+		ctx, cancel := context.WithCancel(ctx)
+		s.startToolCall(reqId, &toolCall{reqId: reqId, cancel: cancel})
+		go func() {
+			defer cancel()
+			// let's call the tool
+			response, err := s.toolsRegistry.CallTool(ctx, toolName, toolArgs)
+			if !s.endToolCall(reqId) {
+				// the client cancelled the request, it does not expect a response
+				s.logger.Info("dropping response of cancelled tool call", types.LogArg{
+					"tool":  toolName,
+					"reqId": jsonrpc.RequestIdToString(reqId),
+				})
+				return
+			}
+			if err != nil {
+				s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("tool call failed: %v", err), reqId)
+				return
+			}
+			if result, ok := response.(*tools.ToolCallResultImpl); ok {
+				// older clients only read the JSON text fallback of the structured output
+				if !s.supportsFeature(mcp.FeatureStructuredOutput) {
+					result.StructuredContent = nil
+				}
+				result.Content = mcp.DowngradeContent(s.protocolVersion, result.Content)
+			}
+			s.mcpServer.SendJsonRpcResponse(&response, reqId)
+		}()
+	}
+}
+
+// createMessage sends a sampling request to the client, it is called
+// by gomcp.CreateMessage during a tool call
+func (s *StateManager) createMessage(ctx context.Context, request *mcp.JsonRpcRequestSamplingCreateMessageParams) (*mcp.JsonRpcResponseSamplingCreateMessageResult, error) {
+	if s.clientCapabilities.Sampling == nil {
+		return nil, fmt.Errorf("the client does not support sampling")
+	}
+	if len(request.Messages) == 0 {
+		return nil, fmt.Errorf("sampling request must have at least one message")
+	}
+	if request.MaxTokens <= 0 {
+		return nil, fmt.Errorf("sampling request must have a positive maxTokens")
+	}
+
+	response, err := s.mcpServer.SendRequestAndWaitResponse(ctx, mcp.RpcRequestMethodSamplingCreateMessage, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("sampling request failed: %s (%d)", response.Error.Message, response.Error.Code)
+	}
+	return mcp.ParseJsonRpcResponseSamplingCreateMessage(response)
+}
+
+// elicit sends an elicitation request to the client, it is called
+// by gomcp.Elicit during a tool call
+func (s *StateManager) elicit(ctx context.Context, request *mcp.JsonRpcRequestElicitationCreateParams) (*mcp.JsonRpcResponseElicitationCreateResult, error) {
+	if s.clientCapabilities.Elicitation == nil || !s.supportsFeature(mcp.FeatureElicitation) {
+		return nil, fmt.Errorf("the client does not support elicitation")
+	}
+
+	response, err := s.mcpServer.SendRequestAndWaitResponse(ctx, mcp.RpcRequestMethodElicitationCreate, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("elicitation request failed: %s (%d)", response.Error.Message, response.Error.Code)
+	}
+	return mcp.ParseJsonRpcResponseElicitationCreate(response)
+}
+
+func (s *StateManager) startToolCall(reqId *jsonrpc.JsonRpcRequestId, call *toolCall) {
+	s.toolCallsMutex.Lock()
+	defer s.toolCallsMutex.Unlock()
+	s.toolCalls[jsonrpc.RequestIdToString(reqId)] = call
+}
+
+// endToolCall returns false if the tool call is not in progress anymore,
+// because it was cancelled by the client
+func (s *StateManager) endToolCall(reqId *jsonrpc.JsonRpcRequestId) bool {
+	s.toolCallsMutex.Lock()
+	defer s.toolCallsMutex.Unlock()
+	key := jsonrpc.RequestIdToString(reqId)
+	_, ok := s.toolCalls[key]
+	delete(s.toolCalls, key)
+	return ok
+}
+
+func (s *StateManager) EventMcpNotificationCancelled(params *mcp.JsonRpcNotificationCancelledParams) {
+	reqId := jsonrpc.RequestIdFromValue(params.RequestId)
+	s.toolCallsMutex.Lock()
+	key := jsonrpc.RequestIdToString(reqId)
+	call, ok := s.toolCalls[key]
+	delete(s.toolCalls, key)
+	var muxReqId *jsonrpc.JsonRpcRequestId
+	if ok {
+		muxReqId = call.muxReqId
+	}
+	s.toolCallsMutex.Unlock()
+
+	if !ok {
+		// the request may have already completed, or is not a tool call
+		s.logger.Debug("ignoring cancellation of unknown request", types.LogArg{
+			"reqId": key,
+		})
+		return
+	}
+	s.logger.Info("tool call cancelled by the client", types.LogArg{
+		"reqId":  key,
+		"reason": params.Reason,
+	})
+
+	if call.cancel != nil {
+		call.cancel()
+	}
+	// the request is not answered, its batch must not wait for it
+	s.mcpServer.DiscardResponse(reqId)
+	if call.session != nil && muxReqId != nil {
+		// the late response of the proxy will not find the tool call and will be dropped
+		call.session.SendNotificationWithParams(mux.RpcNotificationMethodCancelled, &mux.JsonRpcNotificationCancelledParams{
+			RequestId: jsonrpc.RequestIdToValue(muxReqId),
+			Reason:    params.Reason,
+		})
+	}
+}
+
+// newProgressReporter returns the function called by gomcp.ReportProgress
+// during a tool call with a progress token
+func (s *StateManager) newProgressReporter(progressToken interface{}) tools.ProgressReporter {
+	var mutex sync.Mutex
+	var lastProgress *float64
+	return func(progress float64, total float64, message string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		// the progress must increase with each notification
+		if lastProgress != nil && progress <= *lastProgress {
+			s.logger.Debug("ignoring progress not increasing", types.LogArg{
+				"progressToken": progressToken,
+				"progress":      progress,
+				"lastProgress":  *lastProgress,
+			})
 			return
 		}
-		s.mcpServer.SendJsonRpcResponse(&response, reqId)
+		lastProgress = &progress
+
+		var totalPtr *float64
+		if total > 0 {
+			totalPtr = &total
+		}
+		var messagePtr *string
+		if message != "" {
+			messagePtr = &message
+		}
+		s.sendProgress(progressToken, progress, totalPtr, messagePtr)
+	}
+}
+
+func (s *StateManager) sendProgress(progressToken interface{}, progress float64, total *float64, message *string) {
+	if s.mcpServer == nil {
+		return
+	}
+	// the message was added in 2025-03-26
+	if !s.supportsFeature(mcp.FeatureProgressMessage) {
+		message = nil
 	}
+	s.mcpServer.SendNotificationWithParams(mcp.RpcNotificationMethodProgress, &mcp.JsonRpcNotificationProgressParams{
+		ProgressToken: progressToken,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
 }
 
 func (s *StateManager) EventMcpRequestResourcesList(params *mcp.JsonRpcRequestResourcesListParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// we query the resources registry
+	resources := s.resourcesRegistry.GetListOfResources()
+
+	// we only send the requested page
+	start, end, nextCursor, err := mcp.Paginate(mcp.RpcRequestMethodResourcesList, params.Cursor, len(resources), s.pageSize)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, err.Error(), reqId)
+		return
+	}
+
 	var response = mcp.JsonRpcResponseResourcesListResult{
-		Resources: make([]mcp.ResourceDescription, 0),
+		Resources:  make([]mcp.ResourceDescription, 0, end-start),
+		NextCursor: nextCursor,
+	}
+
+	// we build the response
+	for _, resource := range resources[start:end] {
+		response.Resources = append(response.Resources, mcp.ResourceDescription{
+			Uri:         resource.Uri,
+			Name:        resource.Name,
+			Description: resource.Description,
+			MimeType:    resource.MimeType,
+		})
 	}
 
 	s.mcpServer.SendJsonRpcResponse(&response, reqId)
 }
 
+func (s *StateManager) EventMcpRequestResourcesRead(ctx context.Context, params *mcp.JsonRpcRequestResourcesReadParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// let's check if the resource exists
+	if !s.resourcesRegistry.HasResource(params.Uri) {
+		s.mcpServer.SendError(mcp.RpcResourceNotFound, fmt.Sprintf("resource not found: %s", params.Uri), reqId)
+		return
+	}
+
+	response, err := s.resourcesRegistry.ReadResource(tools.MakeContextWithLogForwarder(ctx, s.OnLogMessage), params.Uri)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("resource read failed: %v", err), reqId)
+		return
+	}
+	s.mcpServer.SendJsonRpcResponse(response, reqId)
+}
+
+func (s *StateManager) EventMcpRequestResourcesTemplatesList(params *mcp.JsonRpcRequestResourcesTemplatesListParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// we query the resources registry
+	templates := s.resourcesRegistry.GetListOfResourceTemplates()
+
+	// we only send the requested page
+	start, end, nextCursor, err := mcp.Paginate(mcp.RpcRequestMethodResourcesTemplatesList, params.Cursor, len(templates), s.pageSize)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, err.Error(), reqId)
+		return
+	}
+
+	var response = mcp.JsonRpcResponseResourcesTemplatesListResult{
+		ResourceTemplates: make([]mcp.ResourceTemplateDescription, 0, end-start),
+		NextCursor:        nextCursor,
+	}
+
+	// we build the response
+	for _, template := range templates[start:end] {
+		response.ResourceTemplates = append(response.ResourceTemplates, mcp.ResourceTemplateDescription{
+			UriTemplate: template.UriTemplate,
+			Name:        template.Name,
+			Description: template.Description,
+			MimeType:    template.MimeType,
+		})
+	}
+
+	s.mcpServer.SendJsonRpcResponse(&response, reqId)
+}
+
+func (s *StateManager) EventMcpRequestResourcesSubscribe(params *mcp.JsonRpcRequestResourcesSubscribeParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// let's check if the resource exists
+	if !s.resourcesRegistry.HasResource(params.Uri) {
+		s.mcpServer.SendError(mcp.RpcResourceNotFound, fmt.Sprintf("resource not found: %s", params.Uri), reqId)
+		return
+	}
+
+	s.resourceSubscriptionsMutex.Lock()
+	s.resourceSubscriptions[params.Uri] = true
+	s.resourceSubscriptionsMutex.Unlock()
+
+	s.mcpServer.SendJsonRpcResponse(&mcp.JsonRpcResponseEmptyResult{}, reqId)
+}
+
+func (s *StateManager) EventMcpRequestResourcesUnsubscribe(params *mcp.JsonRpcRequestResourcesUnsubscribeParams, reqId *jsonrpc.JsonRpcRequestId) {
+	s.resourceSubscriptionsMutex.Lock()
+	delete(s.resourceSubscriptions, params.Uri)
+	s.resourceSubscriptionsMutex.Unlock()
+
+	s.mcpServer.SendJsonRpcResponse(&mcp.JsonRpcResponseEmptyResult{}, reqId)
+}
+
+// OnResourceUpdated is called by the resources registry when a resource provider
+// notifies a change, the client is notified only if it subscribed to the resource
+func (s *StateManager) OnResourceUpdated(uri string) {
+	s.resourceSubscriptionsMutex.Lock()
+	isSubscribed := s.resourceSubscriptions[uri]
+	s.resourceSubscriptionsMutex.Unlock()
+
+	if !isSubscribed || !s.isClientInitialized.Load() || s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.SendNotificationWithParams(mcp.RpcNotificationMethodResourcesUpdated, &mcp.JsonRpcNotificationResourcesUpdatedParams{
+		Uri: uri,
+	})
+}
+
+// OnResourceListChanged is called by the resources registry when a resource provider
+// notifies that its list of resources changed
+func (s *StateManager) OnResourceListChanged() {
+	if !s.isClientInitialized.Load() || s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.SendNotification(mcp.RpcNotificationMethodResourcesListChanged)
+}
+
+func (s *StateManager) EventMcpRequestLoggingSetLevel(params *mcp.JsonRpcRequestLoggingSetLevelParams, reqId *jsonrpc.JsonRpcRequestId) {
+	s.loggingLevelMutex.Lock()
+	s.loggingLevel = params.Level
+	s.loggingLevelMutex.Unlock()
+
+	s.mcpServer.SendJsonRpcResponse(&mcp.JsonRpcResponseEmptyResult{}, reqId)
+}
+
+// OnLogMessage is called by the registries when a tool or resource handler logs
+// a message, the record is sent to the client tagged with the provider name
+func (s *StateManager) OnLogMessage(level string, loggerName string, message string, fields types.LogArg) {
+	data := map[string]interface{}{
+		"message": message,
+	}
+	for key, value := range fields {
+		// errors are not serializable as JSON
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[key] = value
+	}
+	s.sendLogMessage(level, loggerName, data)
+}
+
+func (s *StateManager) sendLogMessage(level string, loggerName string, data interface{}) {
+	s.loggingLevelMutex.Lock()
+	loggingLevel := s.loggingLevel
+	s.loggingLevelMutex.Unlock()
+
+	if mcp.LoggingLevelSeverity(level) < mcp.LoggingLevelSeverity(loggingLevel) {
+		return
+	}
+	if !s.isClientInitialized.Load() || s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.SendNotificationWithParams(mcp.RpcNotificationMethodMessage, &mcp.JsonRpcNotificationMessageParams{
+		Level:  level,
+		Logger: loggerName,
+		Data:   data,
+	})
+}
+
 func (s *StateManager) EventMcpRequestPromptsList(params *mcp.JsonRpcRequestPromptsListParams, reqId *jsonrpc.JsonRpcRequestId) {
+	prompts := s.promptsRegistry.GetListOfPrompts()
+
+	// we only send the requested page
+	start, end, nextCursor, err := mcp.Paginate(mcp.RpcRequestMethodPromptsList, params.Cursor, len(prompts), s.pageSize)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, err.Error(), reqId)
+		return
+	}
+
 	var response = mcp.JsonRpcResponsePromptsListResult{
-		Prompts: make([]mcp.PromptDescription, 0),
+		Prompts:    make([]mcp.PromptDescription, 0, end-start),
+		NextCursor: nextCursor,
 	}
 
-	prompts := s.promptsRegistry.GetListOfPrompts()
-	for _, prompt := range prompts {
+	for _, prompt := range prompts[start:end] {
 		arguments := make([]mcp.PromptArgumentDescription, 0, len(prompt.Arguments))
 		for _, argument := range prompt.Arguments {
 			arguments = append(arguments, mcp.PromptArgumentDescription{
@@ -203,6 +729,12 @@ func (s *StateManager) EventMcpRequestPromptsList(params *mcp.JsonRpcRequestProm
 }
 
 func (s *StateManager) EventMcpRequestPromptsGet(params *mcp.JsonRpcRequestPromptsGetParams, reqId *jsonrpc.JsonRpcRequestId) {
+	if proxyId := s.promptsRegistry.GetPromptProxyId(params.Name); proxyId != "" {
+		// the client can send other requests while the proxy answers
+		go s.getProxiedPrompt(proxyId, params, reqId)
+		return
+	}
+
 	var templateArgs = map[string]string{}
 	// copy the arguments, as strings
 	for key, value := range params.Arguments {
@@ -215,6 +747,13 @@ func (s *StateManager) EventMcpRequestPromptsGet(params *mcp.JsonRpcRequestPromp
 		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, fmt.Sprintf("prompt processing error: %s", err), reqId)
 		return
 	}
+	if result, ok := response.(*prompts.PromptGetResultImpl); ok {
+		for _, message := range result.Messages {
+			if message, ok := message.(map[string]interface{}); ok {
+				message["content"] = mcp.DowngradeContentBlock(s.protocolVersion, message["content"])
+			}
+		}
+	}
 
 	// marshal response
 	responseBytes, err := json.Marshal(response)
@@ -227,99 +766,191 @@ func (s *StateManager) EventMcpRequestPromptsGet(params *mcp.JsonRpcRequestPromp
 	s.mcpServer.SendJsonRpcResponse(&jsonResponse, reqId)
 }
 
-func (s *StateManager) EventNewProxyTools() {
-	// s.mcpServer.OnNewProxyTools()
-}
+func (s *StateManager) EventMcpRequestCompletionComplete(ctx context.Context, params *mcp.JsonRpcRequestCompletionCompleteParams, reqId *jsonrpc.JsonRpcRequestId) {
+	var arguments = map[string]string{}
+	if params.Context != nil {
+		arguments = params.Context.Arguments
+	}
 
-func (s *StateManager) EventMcpError(code int, message string, data *json.RawMessage, id *jsonrpc.JsonRpcRequestId) {
-	s.mcpServer.SendError(code, message, id)
+	var values []string
+	var err error
+	switch params.Ref.Type {
+	case mcp.CompletionRefPrompt:
+		if proxyId := s.promptsRegistry.GetPromptProxyId(params.Ref.Name); proxyId != "" {
+			go s.completeProxiedPrompt(ctx, proxyId, params, arguments, reqId)
+			return
+		}
+		logger := types.NewSubLogger(s.logger, types.LogArg{
+			"prompt": params.Ref.Name,
+		})
+		values, err = s.promptsRegistry.CompletePromptArgument(
+			tools.MakeContextWithLogger(ctx, logger), params.Ref.Name, params.Argument.Name, params.Argument.Value, arguments)
+	case mcp.CompletionRefResource:
+		values, err = s.resourcesRegistry.CompleteResourceTemplateArgument(
+			ctx, params.Ref.Uri, params.Argument.Name, params.Argument.Value, arguments)
+	default:
+		err = fmt.Errorf("invalid ref type %s", params.Ref.Type)
+	}
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, fmt.Sprintf("completion failed: %v", err), reqId)
+		return
+	}
+
+	response := mcp.NewCompletionResult(values)
+	s.mcpServer.SendJsonRpcResponse(&response, reqId)
 }
 
-func (s *StateManager) EventMuxRequestProxyRegister(proxyId string, params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
-	// we need to store the proxy id in the session
-	s.logger.Info("@@ EventMuxRequestProxyRegister", types.LogArg{
-		"proxyId":     proxyId,
-		"s.muxServer": s.muxServer == nil,
+// getProxiedPrompt gets a prompt from the MCP server behind a proxy
+func (s *StateManager) getProxiedPrompt(proxyId string, params *mcp.JsonRpcRequestPromptsGetParams, reqId *jsonrpc.JsonRpcRequestId) {
+	arguments := params.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	response, err := s.sendRequestToProxy(context.Background(), proxyId, mux.RpcRequestMethodPromptsGet, &mux.JsonRpcRequestPromptsGetParams{
+		Name:      params.Name,
+		Arguments: arguments,
 	})
-	session := s.muxServer.GetSessionByProxyId(proxyId)
-	if session == nil {
-		s.logger.Error("session not found", types.LogArg{
-			"proxyId": proxyId,
-		})
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("failed to get the prompt from the proxy: %v", err), reqId)
+		return
+	}
+	if response.Error != nil {
+		s.mcpServer.SendError(response.Error.Code, response.Error.Message, reqId)
+		return
+	}
+	result, err := mcp.ParseJsonRpcResponsePromptsGet(response)
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInternalError, fmt.Sprintf("invalid prompt from the proxy: %v", err), reqId)
 		return
 	}
-	session.SetSessionInformation(proxyId, params.ServerInfo.Name)
+	// the content blocks of the MCP server may be newer than the client
+	for i := range result.Messages {
+		result.Messages[i].Content = mcp.DowngradeContentBlock(s.protocolVersion, result.Messages[i].Content)
+	}
+	s.mcpServer.SendJsonRpcResponse(result, reqId)
+}
 
-	// for now we accept all requests
-	result := mux.JsonRpcResponseProxyRegisterResult{
-		SessionId:  session.SessionId(),
-		ProxyId:    proxyId,
-		Persistent: params.Persistent,
-		Denied:     false,
+// completeProxiedPrompt completes an argument of a prompt of the MCP server behind a proxy
+func (s *StateManager) completeProxiedPrompt(ctx context.Context, proxyId string, params *mcp.JsonRpcRequestCompletionCompleteParams, arguments map[string]string, reqId *jsonrpc.JsonRpcRequestId) {
+	response, err := s.sendRequestToProxy(ctx, proxyId, mux.RpcRequestMethodCompletionComplete, &mux.JsonRpcRequestCompletionCompleteParams{
+		Prompt:    params.Ref.Name,
+		Argument:  params.Argument.Name,
+		Value:     params.Argument.Value,
+		Arguments: arguments,
+	})
+	if err == nil && response.Error != nil {
+		err = fmt.Errorf("%s", response.Error.Message)
+	}
+	var result *mcp.JsonRpcResponseCompletionCompleteResult
+	if err == nil {
+		result, err = mcp.ParseJsonRpcResponseCompletionComplete(response)
+	}
+	if err != nil {
+		s.mcpServer.SendError(jsonrpc.RpcInvalidParams, fmt.Sprintf("completion failed: %v", err), reqId)
+		return
 	}
-	session.SendJsonRpcResponse(&result, reqId)
+	if len(result.Completion.Values) > mcp.CompletionMaxValues {
+		truncated := mcp.NewCompletionResult(result.Completion.Values)
+		result = &truncated
+	}
+	s.mcpServer.SendJsonRpcResponse(result, reqId)
 }
 
-func (s *StateManager) EventMuxRequestToolsRegister(proxyId string, params *mux.JsonRpcRequestToolsRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
-	// we need to store the proxy id in the session
-	session := s.muxServer.GetSessionByProxyId(proxyId)
+// sendRequestToProxy sends a request to a proxy and waits for its response
+func (s *StateManager) sendRequestToProxy(ctx context.Context, proxyId string, method string, params interface{}) (*jsonrpc.JsonRpcResponse, error) {
+	var session *hubmuxserver.MuxSession
+	if s.muxServer != nil {
+		session = s.muxServer.GetSessionByProxyId(proxyId)
+	}
 	if session == nil {
-		s.logger.Error("session not found", types.LogArg{
-			"proxyId": proxyId,
-		})
+		return nil, fmt.Errorf("proxy %s is not connected", proxyId)
+	}
+	ctx, cancel := context.WithTimeout(ctx, proxyRequestTimeout)
+	defer cancel()
+	return session.SendRequestAndWaitResponse(ctx, method, params)
+}
+
+func (s *StateManager) EventNewProxyTools() {
+	// s.mcpServer.OnNewProxyTools()
+}
+
+func (s *StateManager) EventMcpError(code int, message string, data *json.RawMessage, id *jsonrpc.JsonRpcRequestId) {
+	s.mcpServer.SendError(code, message, id)
+}
+
+// notifyToolsListChanged tells the client to refresh the tools list
+func (s *StateManager) notifyToolsListChanged() {
+	if !s.isClientInitialized.Load() || s.mcpServer == nil {
 		return
 	}
+	s.mcpServer.SendNotification(mcp.RpcNotificationMethodToolsListChanged)
+}
 
-	toolProvider, err := s.toolsRegistry.RegisterProxyToolProvider(proxyId, session.ProxyName())
-	if err != nil {
-		s.logger.Error("Failed to register proxy tool provider", types.LogArg{
-			"error": err,
-		})
+// notifyPromptsListChanged tells the client to refresh the prompts list
+func (s *StateManager) notifyPromptsListChanged() {
+	if !s.isClientInitialized.Load() || s.mcpServer == nil {
 		return
 	}
-	for _, tool := range params.Tools {
-		err := toolProvider.AddProxyTool(tool.Name, tool.Description, tool.InputSchema)
-		if err != nil {
-			s.logger.Error("Failed to add proxy tool", types.LogArg{
-				"error": err,
-			})
-			return
+	s.mcpServer.SendNotification(mcp.RpcNotificationMethodPromptsListChanged)
+}
+
+// takeProxiedToolCall ends the tool call of that session waiting for the response
+// of the proxy, it returns nil if there is none (eg cancelled by the client)
+func (s *StateManager) takeProxiedToolCall(proxyId string, muxReqId *jsonrpc.JsonRpcRequestId) *jsonrpc.JsonRpcRequestId {
+	s.toolCallsMutex.Lock()
+	defer s.toolCallsMutex.Unlock()
+	muxKey := jsonrpc.RequestIdToString(muxReqId)
+	for key, call := range s.toolCalls {
+		if call.session == nil || call.muxReqId == nil || call.session.ProxyId() != proxyId {
+			continue
+		}
+		if jsonrpc.RequestIdToString(call.muxReqId) == muxKey {
+			delete(s.toolCalls, key)
+			return call.reqId
 		}
 	}
+	return nil
+}
 
-	// we need to prepare the tool provider so that it can be used by the hub
-	err = s.toolsRegistry.PrepareProxyToolProvider(toolProvider)
-	if err != nil {
-		s.logger.Error("Failed to prepare proxy tool provider", types.LogArg{
-			"error": err,
-		})
-		return
+// hasProxiedToolCall returns true if that session waits for a tool call of the proxy
+func (s *StateManager) hasProxiedToolCall(proxyId string) bool {
+	s.toolCallsMutex.Lock()
+	defer s.toolCallsMutex.Unlock()
+	for _, call := range s.toolCalls {
+		if call.session != nil && call.session.ProxyId() == proxyId {
+			return true
+		}
 	}
+	return false
+}
 
-	// send the notification to the MCP client
-	// so that it will refresh the tools list
-	s.mcpServer.SendNotification(mcp.RpcNotificationMethodToolsListChanged)
-
+// findProxiedProgressToken returns the progress token of the client for the
+// token given to the proxy, nil if the tool call is not from that session
+func (s *StateManager) findProxiedProgressToken(muxProgressToken interface{}) interface{} {
+	s.toolCallsMutex.Lock()
+	defer s.toolCallsMutex.Unlock()
+	for _, call := range s.toolCalls {
+		if call.muxProgressToken != "" && call.muxProgressToken == muxProgressToken {
+			return call.progressToken
+		}
+	}
+	return nil
 }
 
-func (s *StateManager) EventMuxResponseToolCall(toolsCallResult *mux.JsonRpcResponseToolsCallResult, reqId *jsonrpc.JsonRpcRequestId) {
-	// we need to find the mcp request id for the given mux request id
-	mcpReqId := s.reqIdMapping.GetMapping(reqId)
+func (s *StateManager) sendProxiedToolCallResult(toolsCallResult *mux.JsonRpcResponseToolsCallResult, mcpReqId *jsonrpc.JsonRpcRequestId) {
 	// we send the response to the mcp client
 	s.logger.Info("EventMuxResponseToolCall", types.LogArg{
 		"mcpReqId": mcpReqId,
-		"reqId":    reqId,
 		"result":   toolsCallResult,
 	})
+	// the proxy forwards the content blocks of its MCP server unchanged,
+	// we only replace the ones the client does not support
 	mcpResponse := &mcp.JsonRpcResponseToolsCallResult{
-		Content: toolsCallResult.Content,
+		Content: mcp.DowngradeContent(s.protocolVersion, toolsCallResult.Content),
 		IsError: toolsCallResult.IsError,
 	}
+	if s.supportsFeature(mcp.FeatureStructuredOutput) {
+		mcpResponse.StructuredContent = toolsCallResult.StructuredContent
+	}
 	s.mcpServer.SendJsonRpcResponse(mcpResponse, mcpReqId)
 }
-
-func (s *StateManager) EventMuxResponseToolCallError(error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId) {
-	// we need to find the mcp request id for the given mux request id
-	mcpReqId := s.reqIdMapping.GetMapping(reqId)
-	s.mcpServer.SendError(error.Code, error.Message, mcpReqId)
-}
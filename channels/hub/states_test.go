@@ -0,0 +1,487 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/channels/hubmcpserver"
+	"github.com/hamstah/gomcp/channels/hubmuxserver"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/prompts"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/resources"
+	"github.com/hamstah/gomcp/tools"
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+// fakeClient is the transport of an MCP client, it records the messages sent
+// by the hub and answers its requests with the results given by method
+type fakeClient struct {
+	mutex     sync.Mutex
+	onMessage func(json.RawMessage)
+	started   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	sent      []fakeMessage
+	results   map[string]interface{}
+}
+
+type fakeMessage struct {
+	Id     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+func newFakeClient(results map[string]interface{}) *fakeClient {
+	return &fakeClient{
+		started: make(chan struct{}),
+		closed:  make(chan struct{}),
+		results: results,
+	}
+}
+
+func (f *fakeClient) Start(ctx context.Context) error {
+	close(f.started)
+	select {
+	case <-ctx.Done():
+	case <-f.closed:
+	}
+	return nil
+}
+
+func (f *fakeClient) Send(message json.RawMessage) error {
+	var sent fakeMessage
+	if err := json.Unmarshal(message, &sent); err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	f.sent = append(f.sent, sent)
+	result, ok := f.results[sent.Method]
+	onMessage := f.onMessage
+	f.mutex.Unlock()
+
+	if sent.Id != nil && sent.Method != "" && ok {
+		response, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      sent.Id,
+			"result":  result,
+		})
+		go onMessage(response)
+	}
+	return nil
+}
+
+func (f *fakeClient) OnMessage(callback func(json.RawMessage)) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.onMessage = callback
+}
+func (f *fakeClient) Close() {
+	f.closeOnce.Do(func() { close(f.closed) })
+}
+func (f *fakeClient) OnStarted(callback func())    {}
+func (f *fakeClient) OnClose(callback func())      {}
+func (f *fakeClient) OnError(callback func(error)) {}
+
+// setResult changes the result of the requests of the hub with that method
+func (f *fakeClient) setResult(method string, result interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.results[method] = result
+}
+
+// receive passes a message of the client to the hub, it is handled before receive returns
+func (f *fakeClient) receive(message string) {
+	f.mutex.Lock()
+	onMessage := f.onMessage
+	f.mutex.Unlock()
+	onMessage(json.RawMessage(message))
+}
+
+// take returns the messages sent by the hub since the last call
+func (f *fakeClient) take() []fakeMessage {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	sent := f.sent
+	f.sent = nil
+	return sent
+}
+
+// countMethod returns the number of messages sent with that method
+func countMethod(messages []fakeMessage, method string) int {
+	count := 0
+	for _, message := range messages {
+		if message.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func testReadResource(ctx context.Context, resourceCtx *struct{}, output types.ResourceReadResult) error {
+	return nil
+}
+
+func testReadLoggingResource(ctx context.Context, resourceCtx *struct{}, output types.ResourceReadResult) error {
+	logger := tools.GetLogger(ctx)
+	logger.Debug("details", types.LogArg{})
+	logger.Info("reading", types.LogArg{"uri": "test://log"})
+	return nil
+}
+
+// newTestSession starts a session of the hub for the client, the resources
+// test://a and test://b are registered, test://log logs when it is read
+func newTestSession(t *testing.T, client *fakeClient) *StateManager {
+	t.Helper()
+	resourcesRegistry := resources.NewResourcesRegistry(nopLogger{})
+	provider, err := resources.DeclareResourceProvider("test", func(ctx context.Context) (*struct{}, error) {
+		return &struct{}{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	provider.AddResource("test://a", "a", "", "text/plain", testReadResource)
+	provider.AddResource("test://b", "b", "", "text/plain", testReadResource)
+	provider.AddResource("test://log", "log", "", "text/plain", testReadLoggingResource)
+	resourcesRegistry.RegisterResourceProvider(provider)
+	if err := resourcesRegistry.Prepare(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sessions := NewSessionManager("hub", "1.0", 0, tools.NewToolsRegistry(false, nopLogger{}), resourcesRegistry, prompts.NewEmptyPromptsRegistry(), nopLogger{})
+	session := NewStateManager("hub", "1.0", 0, sessions.toolsRegistry, resourcesRegistry, sessions.promptsRegistry, nopLogger{})
+	session.sessions = sessions
+	session.SetMcpServer(hubmcpserver.NewMCPServer(client, session.AsEvents(), nopLogger{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go session.mcpServer.Start(ctx)
+	<-client.started
+	return session
+}
+
+// initialize runs the initialization of the session with the protocol version and the capabilities of the client
+func initialize(client *fakeClient, protocolVersion string, capabilities string) {
+	client.receive(`{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"` + protocolVersion + `","capabilities":` + capabilities + `,"clientInfo":{"name":"test","version":"1.0"}}}`)
+	client.receive(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	client.take()
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	client := newFakeClient(nil)
+	session := newTestSession(t, client)
+
+	// nothing is sent before the client is initialized
+	client.receive(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"test://a"}}`)
+	client.take()
+	session.OnResourceUpdated("test://a")
+	session.OnResourceListChanged()
+	if sent := client.take(); len(sent) != 0 {
+		t.Errorf("unexpected notifications before initialization: %+v", sent)
+	}
+
+	initialize(client, mcp.ProtocolVersion, `{}`)
+	client.receive(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"test://unknown"}}`)
+	if sent := client.take(); len(sent) != 1 || sent[0].Error == nil || sent[0].Error.Code != mcp.RpcResourceNotFound {
+		t.Errorf("expected a resource not found error, got %+v", sent)
+	}
+
+	// only the subscribed resources are notified
+	session.OnResourceUpdated("test://a")
+	session.OnResourceUpdated("test://b")
+	sent := client.take()
+	if countMethod(sent, mcp.RpcNotificationMethodResourcesUpdated) != 1 || string(sent[0].Params) != `{"uri":"test://a"}` {
+		t.Errorf("expected a single update of test://a, got %+v", sent)
+	}
+
+	client.receive(`{"jsonrpc":"2.0","id":3,"method":"resources/unsubscribe","params":{"uri":"test://a"}}`)
+	client.take()
+	session.OnResourceUpdated("test://a")
+	if sent := client.take(); len(sent) != 0 {
+		t.Errorf("unexpected notifications after unsubscribe: %+v", sent)
+	}
+
+	// the list changes are sent without subscription
+	session.OnResourceListChanged()
+	if sent := client.take(); countMethod(sent, mcp.RpcNotificationMethodResourcesListChanged) != 1 {
+		t.Errorf("expected a list changed notification, got %+v", sent)
+	}
+}
+
+func TestLogMessages(t *testing.T) {
+	client := newFakeClient(nil)
+	newTestSession(t, client)
+	initialize(client, mcp.ProtocolVersion, `{}`)
+
+	tests := []struct {
+		name  string
+		level string
+		// levels of the messages sent to the client
+		wantLevels []string
+	}{
+		{name: "default level", wantLevels: []string{"info"}},
+		{name: "debug", level: "debug", wantLevels: []string{"debug", "info"}},
+		{name: "error", level: "error", wantLevels: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.level != "" {
+				client.receive(`{"jsonrpc":"2.0","id":"level","method":"logging/setLevel","params":{"level":"` + tt.level + `"}}`)
+			}
+			client.receive(`{"jsonrpc":"2.0","id":"read","method":"resources/read","params":{"uri":"test://log"}}`)
+
+			levels := []string{}
+			for _, message := range client.take() {
+				if message.Method != mcp.RpcNotificationMethodMessage {
+					continue
+				}
+				var params mcp.JsonRpcNotificationMessageParams
+				if err := json.Unmarshal(message.Params, &params); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				// the messages are tagged with the name of the provider
+				if params.Logger != "test" {
+					t.Errorf("expected the logger of the provider, got %q", params.Logger)
+				}
+				levels = append(levels, params.Level)
+			}
+			if !reflect.DeepEqual(levels, tt.wantLevels) {
+				t.Errorf("expected the levels %v, got %v", tt.wantLevels, levels)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name            string
+		protocolVersion string
+		wantMessage     bool
+	}{
+		{name: "with message", protocolVersion: mcp.ProtocolVersion20250326, wantMessage: true},
+		{name: "message not supported", protocolVersion: mcp.ProtocolVersion20241105, wantMessage: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient(nil)
+			session := newTestSession(t, client)
+			initialize(client, tt.protocolVersion, `{}`)
+
+			ctx := tools.MakeContextWithProgressReporter(context.Background(), session.newProgressReporter("token"))
+			tools.ReportProgress(ctx, 1, 4, "first")
+			// the values not increasing are ignored
+			tools.ReportProgress(ctx, 1, 4, "same")
+			tools.ReportProgress(ctx, 0.5, 4, "lower")
+			tools.ReportProgress(ctx, 2, 0, "second")
+
+			progresses := []mcp.JsonRpcNotificationProgressParams{}
+			for _, message := range client.take() {
+				var params mcp.JsonRpcNotificationProgressParams
+				if err := json.Unmarshal(message.Params, &params); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				progresses = append(progresses, params)
+			}
+			if len(progresses) != 2 || progresses[0].Progress != 1 || progresses[1].Progress != 2 {
+				t.Fatalf("expected the progresses 1 and 2, got %+v", progresses)
+			}
+			if progresses[0].Total == nil || *progresses[0].Total != 4 || progresses[1].Total != nil {
+				t.Errorf("unexpected totals: %+v", progresses)
+			}
+			if (progresses[0].Message != nil) != tt.wantMessage {
+				t.Errorf("expected message %v, got %+v", tt.wantMessage, progresses[0].Message)
+			}
+		})
+	}
+}
+
+// waitRoots waits until the roots of the session are the expected ones
+func waitRoots(t *testing.T, session *StateManager, want []mcp.Root) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !reflect.DeepEqual(session.getRoots(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the roots %v, got %v", want, session.getRoots())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	work := []mcp.Root{{Uri: "file:///work"}}
+	client := newFakeClient(map[string]interface{}{
+		mcp.RpcRequestMethodRootsList: map[string]interface{}{"roots": work},
+	})
+	session := newTestSession(t, client)
+
+	// the roots are requested once the client is initialized
+	initialize(client, mcp.ProtocolVersion, `{"roots":{"listChanged":true}}`)
+	waitRoots(t, session, work)
+
+	// they are cached for the tool calls
+	ctx := tools.MakeContextWithRootsProvider(context.Background(), session.getRoots)
+	if roots := tools.GetRoots(ctx); !reflect.DeepEqual(roots, work) {
+		t.Errorf("expected the roots %v, got %v", work, roots)
+	}
+	if sent := client.take(); countMethod(sent, mcp.RpcRequestMethodRootsList) != 1 {
+		t.Errorf("expected a single roots/list request, got %+v", sent)
+	}
+
+	// and requested again when they change
+	home := []mcp.Root{{Uri: "file:///home"}}
+	client.setResult(mcp.RpcRequestMethodRootsList, map[string]interface{}{"roots": home})
+	client.receive(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	waitRoots(t, session, home)
+}
+
+func TestRootsNotSupported(t *testing.T) {
+	client := newFakeClient(map[string]interface{}{
+		mcp.RpcRequestMethodRootsList: map[string]interface{}{"roots": []mcp.Root{}},
+	})
+	session := newTestSession(t, client)
+	initialize(client, mcp.ProtocolVersion, `{}`)
+	client.receive(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+
+	// the roots are not requested from a client without the capability
+	if sent := client.take(); countMethod(sent, mcp.RpcRequestMethodRootsList) != 0 {
+		t.Errorf("unexpected roots/list request: %+v", sent)
+	}
+	if roots := session.getRoots(); roots != nil {
+		t.Errorf("expected no roots, got %v", roots)
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	client := newFakeClient(nil)
+	newTestSession(t, client)
+	client.receive(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":[]}`)
+	if sent := client.take(); len(sent) != 1 || sent[0].Error == nil || sent[0].Error.Code != jsonrpc.RpcInvalidParams {
+		t.Fatalf("expected an invalid params error for initialize, got %+v", sent)
+	}
+	initialize(client, mcp.ProtocolVersion, `{}`)
+
+	for _, method := range []string{
+		mcp.RpcRequestMethodToolsList,
+		mcp.RpcRequestMethodToolsCall,
+		mcp.RpcRequestMethodResourcesList,
+		mcp.RpcRequestMethodPromptsList,
+		mcp.RpcRequestMethodPromptsGet,
+	} {
+		t.Run(method, func(t *testing.T) {
+			client.receive(`{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[]}`)
+			if sent := client.take(); len(sent) != 1 || sent[0].Error == nil || sent[0].Error.Code != jsonrpc.RpcInvalidParams {
+				t.Errorf("expected an invalid params error, got %+v", sent)
+			}
+		})
+	}
+}
+
+func TestToolCallCancelled(t *testing.T) {
+	t.Run("local tool", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		client := newFakeClient(nil)
+		session := newTestSession(t, client)
+		session.toolsRegistry = newWaitToolsRegistry(t, cancelled)
+		initialize(client, mcp.ProtocolVersion, `{}`)
+
+		client.receive(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"wait","arguments":{}}}`)
+		client.receive(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"stop"}}`)
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the context of the tool was not cancelled")
+		}
+
+		// the tool ends with an error that the client does not expect anymore
+		time.Sleep(100 * time.Millisecond)
+		if sent := client.take(); len(sent) != 0 {
+			t.Errorf("expected the late response to be dropped, got %+v", sent)
+		}
+	})
+
+	t.Run("proxied tool", func(t *testing.T) {
+		client := newFakeClient(nil)
+		session := newTestSession(t, client)
+		initialize(client, mcp.ProtocolVersion, `{}`)
+
+		// the tool call was forwarded to the proxy with its own request id
+		proxy := newFakeClient(nil)
+		muxSession := hubmuxserver.NewMuxSession("s-001", proxy, nopLogger{}, nil, nil, nil, false)
+		muxSession.GetNextRequestId()
+		muxReqId := muxSession.GetNextRequestId()
+		reqId := 8
+		session.startToolCall(&jsonrpc.JsonRpcRequestId{Number: &reqId}, &toolCall{
+			reqId:    &jsonrpc.JsonRpcRequestId{Number: &reqId},
+			session:  muxSession,
+			muxReqId: muxReqId,
+		})
+
+		client.receive(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":8}}`)
+		sent := proxy.take()
+		if len(sent) != 1 || sent[0].Method != mux.RpcNotificationMethodCancelled {
+			t.Fatalf("expected the cancellation to be forwarded to the proxy, got %+v", sent)
+		}
+		if params := string(sent[0].Params); params != `{"requestId":1}` {
+			t.Errorf("expected the request id of the mux, got %s", params)
+		}
+
+		// the late response of the proxy is dropped
+		if mcpReqId := session.takeProxiedToolCall(muxSession.ProxyId(), muxReqId); mcpReqId != nil {
+			t.Errorf("expected the tool call to be forgotten, got %v", mcpReqId)
+		}
+		if sent := client.take(); len(sent) != 0 {
+			t.Errorf("unexpected messages to the client: %+v", sent)
+		}
+	})
+}
+
+func TestCompletionInvalidParams(t *testing.T) {
+	client := newFakeClient(nil)
+	newTestSession(t, client)
+	initialize(client, mcp.ProtocolVersion, `{}`)
+
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{"unknown ref type", `{"type":"ref/tool","name":"test"}`},
+		{"unknown prompt", `{"type":"ref/prompt","name":"unknown"}`},
+		{"unknown resource template", `{"type":"ref/resource","uri":"test://{unknown}"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.receive(`{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":` + tt.ref + `,"argument":{"name":"name","value":""}}}`)
+			if sent := client.take(); len(sent) != 1 || sent[0].Error == nil || sent[0].Error.Code != jsonrpc.RpcInvalidParams {
+				t.Errorf("expected an invalid params error, got %+v", sent)
+			}
+		})
+	}
+
+	// an unknown ref type is refused even if the request was not checked by the server
+	client = newFakeClient(nil)
+	session := newTestSession(t, client)
+	initialize(client, mcp.ProtocolVersion, `{}`)
+	reqId := 2
+	session.EventMcpRequestCompletionComplete(context.Background(), &mcp.JsonRpcRequestCompletionCompleteParams{
+		Ref: mcp.CompletionReference{Type: "ref/tool"},
+	}, &jsonrpc.JsonRpcRequestId{Number: &reqId})
+	if sent := client.take(); len(sent) != 1 || sent[0].Error == nil || sent[0].Error.Code != jsonrpc.RpcInvalidParams {
+		t.Errorf("expected an invalid params error, got %+v", sent)
+	}
+}
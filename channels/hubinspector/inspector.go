@@ -36,14 +36,22 @@ type MessageInfo struct {
 	Content   string           `json:"content"`
 }
 
+// ProxyApprovals lets the operators approve or deny the pending proxies from the inspector
+type ProxyApprovals interface {
+	PendingProxies() ([]config.ProxyApproval, error)
+	ApproveProxy(proxyId string) error
+	DenyProxy(proxyId string) error
+}
+
 type Inspector struct {
-	listenAddress string
-	messageChan   chan MessageInfo
-	clients       map[*websocket.Conn]bool
-	mutex         sync.RWMutex
-	logger        types.Logger
-	server        *http.Server
-	isClosing     bool
+	listenAddress  string
+	messageChan    chan MessageInfo
+	clients        map[*websocket.Conn]bool
+	mutex          sync.RWMutex
+	logger         types.Logger
+	server         *http.Server
+	isClosing      bool
+	proxyApprovals ProxyApprovals
 }
 
 func NewInspector(config *config.InspectorInfo, logger types.Logger) *Inspector {
@@ -57,6 +65,11 @@ func NewInspector(config *config.InspectorInfo, logger types.Logger) *Inspector
 	}
 }
 
+// SetProxyApprovals enables the approval of the pending proxies
+func (i *Inspector) SetProxyApprovals(proxyApprovals ProxyApprovals) {
+	i.proxyApprovals = proxyApprovals
+}
+
 // EnqueueMessage adds a new message to the inspection queue
 func (i *Inspector) EnqueueMessage(msg MessageInfo) {
 	// json encode the message
@@ -76,6 +89,9 @@ func (i *Inspector) Start(ctx context.Context) error {
 		}
 	})
 	router.HandleFunc("/ws", i.serveWs)
+	router.HandleFunc("GET /proxies", i.servePendingProxies)
+	router.HandleFunc("POST /proxies/approve", i.serveProxyDecision)
+	router.HandleFunc("POST /proxies/deny", i.serveProxyDecision)
 
 	server := &http.Server{
 		Addr:    i.listenAddress,
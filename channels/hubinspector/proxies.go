@@ -0,0 +1,64 @@
+package hubinspector
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/types"
+)
+
+type proxyDecisionRequest struct {
+	ProxyId string `json:"proxyId"`
+}
+
+// servePendingProxies returns the proxies waiting for the decision of an operator
+func (i *Inspector) servePendingProxies(w http.ResponseWriter, r *http.Request) {
+	pending := []config.ProxyApproval{}
+	if i.proxyApprovals != nil {
+		var err error
+		pending, err = i.proxyApprovals.PendingProxies()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pending)
+}
+
+// serveProxyDecision approves or denies a pending proxy, the body is {"proxyId": "..."}
+func (i *Inspector) serveProxyDecision(w http.ResponseWriter, r *http.Request) {
+	if i.proxyApprovals == nil {
+		http.Error(w, "the proxies are not enabled", http.StatusNotFound)
+		return
+	}
+	// the browsers send a preflight request for this content type, the other
+	// sites can't make a browser approve a proxy
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "the content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var request proxyDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.ProxyId == "" {
+		http.Error(w, "missing proxyId", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if r.URL.Path == "/proxies/approve" {
+		err = i.proxyApprovals.ApproveProxy(request.ProxyId)
+	} else {
+		err = i.proxyApprovals.DenyProxy(request.ProxyId)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	i.logger.Info("proxy decision from the inspector", types.LogArg{
+		"proxyId": request.ProxyId,
+		"path":    r.URL.Path,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
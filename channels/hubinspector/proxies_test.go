@@ -0,0 +1,107 @@
+package hubinspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+// fakeProxyApprovals records the decisions, only proxy-1 is pending
+type fakeProxyApprovals struct {
+	decisions []string
+}
+
+func (f *fakeProxyApprovals) PendingProxies() ([]config.ProxyApproval, error) {
+	return []config.ProxyApproval{{ProxyId: "proxy-1", Status: config.ProxyApprovalPending}}, nil
+}
+
+func (f *fakeProxyApprovals) ApproveProxy(proxyId string) error {
+	return f.decide(proxyId, "approve")
+}
+
+func (f *fakeProxyApprovals) DenyProxy(proxyId string) error {
+	return f.decide(proxyId, "deny")
+}
+
+func (f *fakeProxyApprovals) decide(proxyId string, decision string) error {
+	if proxyId != "proxy-1" {
+		return fmt.Errorf("proxy %s is not pending", proxyId)
+	}
+	f.decisions = append(f.decisions, decision+" "+proxyId)
+	return nil
+}
+
+func TestServePendingProxies(t *testing.T) {
+	inspector := NewInspector(&config.InspectorInfo{}, nopLogger{})
+
+	// without the proxies there is no pending proxy
+	recorder := httptest.NewRecorder()
+	inspector.servePendingProxies(recorder, httptest.NewRequest(http.MethodGet, "/proxies", nil))
+	if body := strings.TrimSpace(recorder.Body.String()); body != "[]" {
+		t.Errorf("expected no pending proxy, got %s", body)
+	}
+
+	inspector.SetProxyApprovals(&fakeProxyApprovals{})
+	recorder = httptest.NewRecorder()
+	inspector.servePendingProxies(recorder, httptest.NewRequest(http.MethodGet, "/proxies", nil))
+	var pending []config.ProxyApproval
+	if err := json.Unmarshal(recorder.Body.Bytes(), &pending); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []config.ProxyApproval{{ProxyId: "proxy-1", Status: config.ProxyApprovalPending}}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("expected the pending proxies %+v, got %+v", want, pending)
+	}
+}
+
+func TestServeProxyDecision(t *testing.T) {
+	tests := []struct {
+		name          string
+		disabled      bool
+		path          string
+		contentType   string
+		body          string
+		wantStatus    int
+		wantDecisions []string
+	}{
+		{"approve", false, "/proxies/approve", "application/json", `{"proxyId":"proxy-1"}`, http.StatusNoContent, []string{"approve proxy-1"}},
+		{"deny", false, "/proxies/deny", "application/json; charset=utf-8", `{"proxyId":"proxy-1"}`, http.StatusNoContent, []string{"deny proxy-1"}},
+		{"not pending", false, "/proxies/approve", "application/json", `{"proxyId":"proxy-2"}`, http.StatusConflict, nil},
+		{"missing proxy id", false, "/proxies/approve", "application/json", `{}`, http.StatusBadRequest, nil},
+		{"form sent by another site", false, "/proxies/approve", "text/plain", `{"proxyId":"proxy-1"}`, http.StatusUnsupportedMediaType, nil},
+		{"proxies not enabled", true, "/proxies/approve", "application/json", `{"proxyId":"proxy-1"}`, http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspector := NewInspector(&config.InspectorInfo{}, nopLogger{})
+			proxyApprovals := &fakeProxyApprovals{}
+			if !tt.disabled {
+				inspector.SetProxyApprovals(proxyApprovals)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			recorder := httptest.NewRecorder()
+			inspector.serveProxyDecision(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("expected the status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if !reflect.DeepEqual(proxyApprovals.decisions, tt.wantDecisions) {
+				t.Errorf("expected the decisions %v, got %v", tt.wantDecisions, proxyApprovals.decisions)
+			}
+		})
+	}
+}
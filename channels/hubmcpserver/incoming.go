@@ -14,6 +14,11 @@ import (
 func (s *MCPServer) handleIncomingMessage(ctx context.Context, message transport.JsonRpcMessage) error {
 	if message.Response != nil {
 		response := message.Response
+		// response to a request sent by the server, for example
+		// sampling, the sender is waiting for it
+		if s.deliverResponse(response) {
+			return nil
+		}
 		if response.Error != nil {
 			s.logger.Error("error in response", types.LogArg{
 				"response":      fmt.Sprintf("%+v", response),
@@ -37,17 +42,33 @@ func (s *MCPServer) handleIncomingMessage(ctx context.Context, message transport
 			{
 				parsed, err := mcp.ParseJsonRpcRequestInitialize(request)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
 				}
 				s.events.EventMcpRequestInitialize(parsed, request.Id)
 			}
 		case mcp.RpcNotificationMethodInitialized:
 			s.events.EventMcpNotificationInitialized()
+		case mcp.RpcNotificationMethodRootsListChanged:
+			s.events.EventMcpNotificationRootsListChanged()
+		case mcp.RpcNotificationMethodCancelled:
+			{
+				// that's a notification, we can't answer with an error
+				parsed, err := mcp.ParseJsonRpcNotificationCancelledParams(request.Params)
+				if err != nil {
+					s.logger.Error("error parsing cancelled notification", types.LogArg{
+						"error": err,
+					})
+					return nil
+				}
+				s.events.EventMcpNotificationCancelled(parsed)
+			}
 		case mcp.RpcRequestMethodToolsList:
 			{
 				parsed, err := mcp.ParseJsonRpcRequestToolsList(request)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
 				}
 				s.events.EventMcpRequestToolsList(parsed, request.Id)
 			}
@@ -55,7 +76,7 @@ func (s *MCPServer) handleIncomingMessage(ctx context.Context, message transport
 			{
 				parsed, err := mcp.ParseJsonRpcRequestToolsCallParams(request.Params)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
 					return nil
 				}
 				s.events.EventMcpRequestToolsCall(ctx, parsed, request.Id)
@@ -64,15 +85,62 @@ func (s *MCPServer) handleIncomingMessage(ctx context.Context, message transport
 			{
 				parsed, err := mcp.ParseJsonRpcRequestResourcesList(request.Params)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
 				}
 				s.events.EventMcpRequestResourcesList(parsed, request.Id)
 			}
+		case mcp.RpcRequestMethodResourcesRead:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestResourcesRead(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestResourcesRead(ctx, parsed, request.Id)
+			}
+		case mcp.RpcRequestMethodResourcesTemplatesList:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestResourcesTemplatesList(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestResourcesTemplatesList(parsed, request.Id)
+			}
+		case mcp.RpcRequestMethodResourcesSubscribe:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestResourcesSubscribe(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestResourcesSubscribe(parsed, request.Id)
+			}
+		case mcp.RpcRequestMethodResourcesUnsubscribe:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestResourcesUnsubscribe(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestResourcesUnsubscribe(parsed, request.Id)
+			}
+		case mcp.RpcRequestMethodLoggingSetLevel:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestLoggingSetLevel(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestLoggingSetLevel(parsed, request.Id)
+			}
 		case mcp.RpcRequestMethodPromptsList:
 			{
 				parsed, err := mcp.ParseJsonRpcRequestPromptsList(request.Params)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
 				}
 				s.events.EventMcpRequestPromptsList(parsed, request.Id)
 			}
@@ -80,14 +148,31 @@ func (s *MCPServer) handleIncomingMessage(ctx context.Context, message transport
 			{
 				parsed, err := mcp.ParseJsonRpcRequestPromptsGet(request.Params)
 				if err != nil {
-					s.SendError(jsonrpc.RpcInvalidRequest, err.Error(), request.Id)
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
 				}
 				s.events.EventMcpRequestPromptsGet(parsed, request.Id)
 			}
+		case mcp.RpcRequestMethodCompletionComplete:
+			{
+				parsed, err := mcp.ParseJsonRpcRequestCompletionComplete(request.Params)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMcpRequestCompletionComplete(ctx, parsed, request.Id)
+			}
 		case "ping":
 			result := json.RawMessage(`{}`)
 			s.SendJsonRpcResponse(result, request.Id)
 		default:
+			if request.Id == nil {
+				// unknown notifications are ignored, they are never answered
+				s.logger.Info("ignoring unknown notification", types.LogArg{
+					"method": request.Method,
+				})
+				return nil
+			}
 			s.SendError(jsonrpc.RpcMethodNotFound, fmt.Sprintf("unknown method: %s", request.Method), request.Id)
 		}
 	} else {
@@ -3,42 +3,52 @@ package hubmcpserver
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"github.com/hamstah/gomcp/channels/hub/events"
 	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
 	"github.com/hamstah/gomcp/transport"
 	"github.com/hamstah/gomcp/types"
 )
 
 type MCPServer struct {
 	transport *transport.JsonRpcTransport
-	events    events.Events
+	events    events.McpEvents
 	logger    types.Logger
+	// requests sent to the client waiting for their response,
+	// they fail once done is closed at the end of the session
+	pendingResponses      map[string]chan *jsonrpc.JsonRpcResponse
+	pendingResponsesMutex sync.Mutex
+	done                  chan struct{}
 }
 
+var errSessionEnded = errors.New("the session of the client ended")
+
 func NewMCPServer(
 	tran types.Transport,
-	events events.Events,
+	events events.McpEvents,
 	logger types.Logger,
 ) *MCPServer {
 	jsonRpcTransport := transport.NewJsonRpcTransport(tran, "mcp server", logger)
 	return &MCPServer{
-		transport: jsonRpcTransport,
-		events:    events,
-		logger:    logger,
+		transport:        jsonRpcTransport,
+		events:           events,
+		logger:           logger,
+		pendingResponses: map[string]chan *jsonrpc.JsonRpcResponse{},
+		done:             make(chan struct{}),
 	}
 }
 
 func (s *MCPServer) Start(ctx context.Context) error {
-	var err error
-
+	defer close(s.done)
 	errChan := make(chan error, 1)
 
 	go func() {
 		// Start the transport
 		err := s.transport.Start(ctx, func(message transport.JsonRpcMessage, jsonRpcTransport *transport.JsonRpcTransport) {
-			err = s.handleIncomingMessage(ctx, message)
-			if err != nil {
+			// the messages can be handled concurrently (eg responses to our requests)
+			if err := s.handleIncomingMessage(ctx, message); err != nil {
 				s.logError("failed to handle incoming message", err)
 			}
 		})
@@ -77,6 +87,69 @@ func (s *MCPServer) SendRequestWithMethodAndParams(method string, params interfa
 	return s.transport.SendRequestWithMethodAndParams(method, params)
 }
 
+// SendRequestAndWaitResponse sends a request to the client and blocks until
+// the response is received, the context is cancelled or the session ends.
+// The response can be an error response.
+func (s *MCPServer) SendRequestAndWaitResponse(ctx context.Context, method string, params interface{}) (*jsonrpc.JsonRpcResponse, error) {
+	responseChan := make(chan *jsonrpc.JsonRpcResponse, 1)
+
+	// the lock is kept while sending so that the response
+	// can't be received before we wait for it
+	s.pendingResponsesMutex.Lock()
+	reqId, err := s.transport.SendRequestWithMethodAndParams(method, params)
+	if err != nil {
+		s.pendingResponsesMutex.Unlock()
+		return nil, err
+	}
+	key := jsonrpc.RequestIdToString(reqId)
+	s.pendingResponses[key] = responseChan
+	s.pendingResponsesMutex.Unlock()
+
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-s.done:
+		s.pendingResponsesMutex.Lock()
+		delete(s.pendingResponses, key)
+		s.pendingResponsesMutex.Unlock()
+		return nil, errSessionEnded
+	case <-ctx.Done():
+		s.pendingResponsesMutex.Lock()
+		delete(s.pendingResponses, key)
+		s.pendingResponsesMutex.Unlock()
+		// we tell the client that we don't need the response anymore
+		s.SendNotificationWithParams(mcp.RpcNotificationMethodCancelled, &mcp.JsonRpcNotificationCancelledParams{
+			RequestId: jsonrpc.RequestIdToValue(reqId),
+		})
+		return nil, ctx.Err()
+	}
+}
+
+// deliverResponse passes the response to the goroutine waiting for it,
+// it returns false if nobody is waiting for that response
+func (s *MCPServer) deliverResponse(response *jsonrpc.JsonRpcResponse) bool {
+	s.pendingResponsesMutex.Lock()
+	defer s.pendingResponsesMutex.Unlock()
+	key := jsonrpc.RequestIdToString(response.Id)
+	responseChan, ok := s.pendingResponses[key]
+	if !ok {
+		return false
+	}
+	delete(s.pendingResponses, key)
+	responseChan <- response
+	return true
+}
+
+// SetBatchesEnabled accepts the JSON-RPC batches, it depends on the protocol version
+func (s *MCPServer) SetBatchesEnabled(enabled bool) {
+	s.transport.SetBatchesEnabled(enabled)
+}
+
+// DiscardResponse is called for the requests that will not be answered
+func (s *MCPServer) DiscardResponse(id *jsonrpc.JsonRpcRequestId) {
+	s.transport.DiscardResponse(id)
+}
+
 func (s *MCPServer) SendJsonRpcResponse(response interface{}, id *jsonrpc.JsonRpcRequestId) {
 	s.transport.SendResponse(&jsonrpc.JsonRpcResponse{
 		JsonRpcVersion: jsonrpc.JsonRpcVersion,
@@ -105,3 +178,10 @@ func (c *MCPServer) SendNotification(method string) {
 	}
 	c.transport.SendRequest(&notification)
 }
+
+func (c *MCPServer) SendNotificationWithParams(method string, params interface{}) {
+	err := c.transport.SendNotificationWithParams(method, params)
+	if err != nil {
+		c.logError("failed to send notification", err)
+	}
+}
@@ -0,0 +1,79 @@
+package hubmuxserver
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hamstah/gomcp/protocol/mux"
+)
+
+// maximum difference between the clocks of the proxy and the hub,
+// the nonces are remembered that long to refuse the replayed registrations
+const proxyAuthenticationMaxSkew = 5 * time.Minute
+
+// proxyAuthenticator checks that the proxies registering have the key of the hub
+type proxyAuthenticator struct {
+	key    []byte
+	nonces map[string]time.Time
+	mutex  sync.Mutex
+}
+
+func newProxyAuthenticator(key []byte) *proxyAuthenticator {
+	return &proxyAuthenticator{
+		key:    key,
+		nonces: map[string]time.Time{},
+	}
+}
+
+// authenticate returns an error if the registration is not signed with the key,
+// is too old or was already received
+func (a *proxyAuthenticator) authenticate(params *mux.JsonRpcRequestProxyRegisterParams, now time.Time) error {
+	a.forgetExpiredNonces(now)
+
+	auth := params.Authentication
+	if auth == nil {
+		return fmt.Errorf("authentication required")
+	}
+	if auth.Nonce == "" {
+		return fmt.Errorf("missing nonce")
+	}
+	signedAt := time.Unix(auth.Timestamp, 0)
+	if signedAt.Before(now.Add(-proxyAuthenticationMaxSkew)) || signedAt.After(now.Add(proxyAuthenticationMaxSkew)) {
+		return fmt.Errorf("the registration is too old or the clocks are not synchronized")
+	}
+	if !mux.VerifyProxyRegistration(a.key, params.ProxyId, auth) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, ok := a.nonces[auth.Nonce]; ok {
+		return fmt.Errorf("the registration was already received")
+	}
+	a.nonces[auth.Nonce] = signedAt.Add(proxyAuthenticationMaxSkew)
+	return nil
+}
+
+func (a *proxyAuthenticator) forgetExpiredNonces(now time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for nonce, expiry := range a.nonces {
+		if now.After(expiry) {
+			delete(a.nonces, nonce)
+		}
+	}
+}
+
+// checkPeerIdentity returns an error if the proxy presented a client certificate
+// issued for another proxy id, the proxies connecting without mutual TLS have none
+// but with mutual TLS the certificate must name the proxy
+func (s *MuxSession) checkPeerIdentity(proxyId string) error {
+	if s.requireClientCert && s.peerCommonName == "" {
+		return fmt.Errorf("the client certificate has no common name")
+	}
+	if s.peerCommonName != "" && s.peerCommonName != proxyId {
+		return fmt.Errorf("the client certificate was issued for another proxy")
+	}
+	return nil
+}
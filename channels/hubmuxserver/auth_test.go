@@ -0,0 +1,79 @@
+package hubmuxserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/protocol/mux"
+)
+
+func TestProxyAuthenticator(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Unix(1700000000, 0)
+	authenticator := newProxyAuthenticator(key)
+
+	signed := func(key []byte, proxyId string, nonce string, timestamp time.Time) *mux.ProxyAuthentication {
+		return &mux.ProxyAuthentication{
+			Nonce:     nonce,
+			Timestamp: timestamp.Unix(),
+			Signature: mux.SignProxyRegistration(key, proxyId, nonce, timestamp.Unix()),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		proxyId        string
+		authentication *mux.ProxyAuthentication
+		wantErr        bool
+	}{
+		{"valid", "proxy-1", signed(key, "proxy-1", "n1", now), false},
+		{"replayed", "proxy-1", signed(key, "proxy-1", "n1", now), true},
+		{"missing", "proxy-1", nil, true},
+		{"other key", "proxy-1", signed([]byte("another key"), "proxy-1", "n2", now), true},
+		{"other proxy id", "proxy-2", signed(key, "proxy-1", "n3", now), true},
+		{"too old", "proxy-1", signed(key, "proxy-1", "n4", now.Add(-10*time.Minute)), true},
+		{"clock skew", "proxy-1", signed(key, "proxy-1", "n5", now.Add(time.Minute)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &mux.JsonRpcRequestProxyRegisterParams{
+				ProxyId:        tt.proxyId,
+				Authentication: tt.authentication,
+			}
+			err := authenticator.authenticate(params, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// the nonces are forgotten once the registrations expire
+	authenticator.authenticate(&mux.JsonRpcRequestProxyRegisterParams{ProxyId: "proxy-1"}, now.Add(time.Hour))
+	if len(authenticator.nonces) != 0 {
+		t.Errorf("expected the nonces to expire, got %d", len(authenticator.nonces))
+	}
+}
+
+func TestCheckPeerIdentity(t *testing.T) {
+	tests := []struct {
+		name              string
+		requireClientCert bool
+		peerCommonName    string
+		proxyId           string
+		wantErr           bool
+	}{
+		{"without mutual TLS", false, "", "proxy-1", false},
+		{"same proxy", true, "proxy-1", "proxy-1", false},
+		{"other proxy", true, "proxy-2", "proxy-1", true},
+		{"certificate without common name", true, "", "proxy-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &MuxSession{peerCommonName: tt.peerCommonName, requireClientCert: tt.requireClientCert}
+			err := session.checkPeerIdentity(tt.proxyId)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package hubmuxserver
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hamstah/gomcp/jsonrpc"
 	"github.com/hamstah/gomcp/protocol/mux"
@@ -12,6 +13,10 @@ import (
 func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) error {
 	if message.Response != nil {
 		response := message.Response
+		// response to a request waiting for it, eg prompts/get
+		if s.deliverResponse(response) {
+			return nil
+		}
 		if response.Error != nil {
 			s.logger.Error("error in response", types.LogArg{
 				"response":      fmt.Sprintf("%+v", response),
@@ -21,7 +26,7 @@ func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) err
 			})
 			switch message.Method {
 			case mux.RpcRequestMethodCallTool:
-				s.events.EventMuxResponseToolCallError(response.Error, response.Id)
+				s.events.EventMuxResponseToolCallError(s.ProxyId(), response.Error, response.Id)
 			}
 			return nil
 		}
@@ -36,7 +41,7 @@ func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) err
 					})
 					return err
 				}
-				s.events.EventMuxResponseToolCall(toolsCallResult, response.Id)
+				s.events.EventMuxResponseToolCall(s.ProxyId(), toolsCallResult, response.Id)
 			}
 		default:
 			s.logger.Error("received response message with unexpected method", types.LogArg{
@@ -47,9 +52,18 @@ func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) err
 		}
 	} else if message.Request != nil {
 		request := message.Request
+		// nothing is accepted from a proxy before its registration
+		if s.ProxyId() == "" && message.Method != mux.RpcRequestMethodProxyRegister {
+			if request.Id != nil {
+				s.SendError(jsonrpc.RpcInvalidRequest, "proxy not registered", request.Id)
+			}
+			return nil
+		}
 		switch message.Method {
 		case mux.RpcRequestMethodProxyRegister:
 			{
+				// an invalid registration is denied like a failed authentication,
+				// the proxy is told why and the session closed
 				params, err := mux.ParseJsonRpcRequestProxyRegisterParams(request)
 				if err != nil {
 					s.logger.Error("Failed to parse request params", types.LogArg{
@@ -57,30 +71,35 @@ func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) err
 						"method":  request.Method,
 						"error":   err,
 					})
-					return err
+					s.denyRegistration(&mux.JsonRpcRequestProxyRegisterParams{}, request.Id, fmt.Sprintf("invalid registration: %v", err))
+					return nil
 				}
 				// set the session information, required to
 				// send the event to a specific proxy
 				proxyId := params.ProxyId
 				if proxyId == "" {
-					s.logger.Error("missing proxy id", types.LogArg{
-						"request": request,
-						"method":  request.Method,
-					})
-					return fmt.Errorf("missing proxy id")
+					s.denyRegistration(params, request.Id, "missing proxy id")
+					return nil
+				}
+				if s.ProxyId() != "" || s.pendingProxyId() != "" {
+					s.SendError(jsonrpc.RpcInvalidRequest, "proxy already registered", request.Id)
+					return nil
 				}
-				// we store the proxy id in the session
-				s.proxyId = proxyId
-				s.proxyName = params.ServerInfo.Name
-
-				s.logger.Info("@@ Proxy register", types.LogArg{
-					"proxyId":   s.proxyId,
-					"proxyName": s.proxyName,
-				})
 
-				// send the event
-				s.events.EventMuxRequestProxyRegister(s.proxyId, params, request.Id)
+				// the proxy must prove that it has the key of the hub and, with mutual TLS,
+				// present the certificate issued for its proxy id, otherwise the registration
+				// is denied and the session closed
+				err = s.checkPeerIdentity(proxyId)
+				if err == nil {
+					err = s.authenticator.authenticate(params, time.Now())
+				}
+				if err != nil {
+					s.denyRegistration(params, request.Id, err.Error())
+					return nil
+				}
 
+				// accepted, denied or pending until an operator decides
+				s.register(params, request.Id)
 			}
 		case mux.RpcRequestMethodToolsRegister:
 			{
@@ -98,7 +117,42 @@ func (s *MuxSession) handleIncomingMessage(message transport.JsonRpcMessage) err
 				})
 
 				// send the event
-				s.events.EventMuxRequestToolsRegister(s.proxyId, params, request.Id)
+				s.events.EventMuxRequestToolsRegister(s.ProxyId(), params, request.Id)
+			}
+		case mux.RpcRequestMethodPromptsRegister:
+			{
+				params, err := mux.ParseJsonRpcRequestPromptsRegisterParams(request)
+				if err != nil {
+					s.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				s.events.EventMuxRequestPromptsRegister(s.ProxyId(), params, request.Id)
+			}
+		case mux.RpcNotificationMethodMessage:
+			{
+				params, err := mux.ParseJsonRpcNotificationMessageParams(request)
+				if err != nil {
+					s.logger.Error("Failed to parse notification params", types.LogArg{
+						"request": request,
+						"method":  request.Method,
+						"error":   err,
+					})
+					return err
+				}
+				s.events.EventMuxNotificationMessage(s.ProxyId(), params)
+			}
+		case mux.RpcNotificationMethodProgress:
+			{
+				params, err := mux.ParseJsonRpcNotificationProgressParams(request)
+				if err != nil {
+					s.logger.Error("Failed to parse notification params", types.LogArg{
+						"request": request,
+						"method":  request.Method,
+						"error":   err,
+					})
+					return err
+				}
+				s.events.EventMuxNotificationProgress(s.ProxyId(), params)
 			}
 
 		default:
@@ -2,38 +2,68 @@ package hubmuxserver
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/hamstah/gomcp/channels/hub/events"
+	"github.com/hamstah/gomcp/config"
 	"github.com/hamstah/gomcp/transport/socket"
 	"github.com/hamstah/gomcp/types"
 )
 
 type MuxServer struct {
 	listenAddress string
-	socketServer  *socket.SocketServer
+	tlsConfig     *tls.Config
+	socketServer  socket.Server
 	sessions      []*MuxSession
 	sessionCount  int
+	// the connections are handed over concurrently once their TLS handshake is done
+	sessionsMutex sync.Mutex
 	logger        types.Logger
-	events        events.Events
+	events        events.MuxEvents
+	authenticator *proxyAuthenticator
+	policy        *proxyPolicy
 }
 
-// server inside the mcp server in charge of multiplexing multiple proxy clients
-func NewMuxServer(listenAddress string, events events.Events, logger types.Logger) *MuxServer {
+// server inside the mcp server in charge of multiplexing multiple proxy clients,
+// the proxies must sign their registration with the key, the link uses TLS if tlsConfig is not nil,
+// the proxies the policy does not allow wait for an operator (all are accepted without policy)
+func NewMuxServer(listenAddress string, tlsConfig *tls.Config, key []byte, policy *config.MuxPolicyInfo, events events.MuxEvents, logger types.Logger) *MuxServer {
 	return &MuxServer{
 		listenAddress: listenAddress,
+		tlsConfig:     tlsConfig,
 		socketServer:  nil,
 		sessions:      []*MuxSession{},
 		sessionCount:  0,
 		logger:        logger,
 		events:        events,
+		authenticator: newProxyAuthenticator(key),
+		policy:        newProxyPolicy(policy),
 	}
 }
 
 func (m *MuxServer) Start(ctx context.Context) error {
-	// create socket server to listen for new proxy client connections
-	m.socketServer = socket.NewSocketServer(m.listenAddress)
+	// create socket server to listen for new proxy client connections,
+	// a TCP socket or a WebSocket depending on the address
+	socketServer, err := socket.NewServer(m.listenAddress, m.tlsConfig)
+	if err != nil {
+		return err
+	}
+	m.socketServer = socketServer
+
+	if m.policy.enabled() {
+		// the proxies pending when the hub stopped will register again
+		err := m.policy.forget("")
+		if err != nil {
+			m.logger.Error("failed to remove the pending proxies", types.LogArg{
+				"error": err,
+			})
+		}
+		go m.pollApprovals(ctx)
+	}
 
 	m.socketServer.OnError(func(err error) {
 		m.logger.Error("Error", types.LogArg{
@@ -43,10 +73,12 @@ func (m *MuxServer) Start(ctx context.Context) error {
 
 	// the parameter is a function that will be called when
 	// a new connection is established with a proxy client
-	m.socketServer.Start(ctx, func(transport types.Transport) {
+	m.socketServer.Start(ctx, func(tran types.Transport) {
 		// we have a new session
+		m.sessionsMutex.Lock()
 		m.sessionCount++
 		sessionId := fmt.Sprintf("s-%03d", m.sessionCount)
+		m.sessionsMutex.Unlock()
 		m.logger.Info("new session", types.LogArg{
 			"sessionId": sessionId,
 		})
@@ -55,8 +87,12 @@ func (m *MuxServer) Start(ctx context.Context) error {
 		})
 
 		// create a new session
-		session := NewMuxSession(sessionId, transport, subLogger, m.events)
+		// with mutual TLS every proxy presents a certificate naming it
+		requireClientCert := m.tlsConfig != nil && m.tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert
+		session := NewMuxSession(sessionId, tran, subLogger, m.events, m.authenticator, m.policy, requireClientCert)
+		m.sessionsMutex.Lock()
 		m.sessions = append(m.sessions, session)
+		m.sessionsMutex.Unlock()
 
 		// start the session processing in a goroutine
 		// this is to avoid blocking the main thread
@@ -68,13 +104,18 @@ func (m *MuxServer) Start(ctx context.Context) error {
 					"sessionId": sessionId,
 					"error":     err,
 				})
-				session.Close()
-				// if the session fails to start, we remove it from the list of sessions
-				m.sessions = slices.DeleteFunc(m.sessions, func(s *MuxSession) bool {
-					return s.SessionId() == sessionId
-				})
 			}
-
+			// the session ended, closed by the proxy or by the hub when it was denied
+			session.Close()
+			m.forgetProxy(session)
+			m.sessionsMutex.Lock()
+			m.sessions = slices.DeleteFunc(m.sessions, func(s *MuxSession) bool {
+				return s.SessionId() == sessionId
+			})
+			m.sessionsMutex.Unlock()
+			if proxyId := session.ProxyId(); proxyId != "" {
+				m.events.EventMuxProxyDisconnected(proxyId)
+			}
 		}()
 	})
 
@@ -87,22 +128,129 @@ func (m *MuxServer) Start(ctx context.Context) error {
 
 func (m *MuxServer) Close() {
 	m.socketServer.Close()
-	for _, session := range m.sessions {
+	for _, session := range m.getSessions() {
 		session.Close()
 	}
 }
 
+// getSessions returns a copy of the sessions, they are closed and removed concurrently
+func (m *MuxServer) getSessions() []*MuxSession {
+	m.sessionsMutex.Lock()
+	defer m.sessionsMutex.Unlock()
+	return slices.Clone(m.sessions)
+}
+
 func (m *MuxServer) GetSessionByProxyId(proxyId string) *MuxSession {
 	m.logger.Info("@@ GetSessionByProxyId", types.LogArg{
 		"proxyId": proxyId,
 	})
-	if m.sessions == nil {
-		return nil
-	}
-	for _, session := range m.sessions {
-		if session.proxyId == proxyId {
+	for _, session := range m.getSessions() {
+		if session.ProxyId() == proxyId {
 			return session
 		}
 	}
 	return nil
 }
+
+// GetRegisteredSessions returns the sessions of the proxies that are registered
+func (m *MuxServer) GetRegisteredSessions() []*MuxSession {
+	sessions := []*MuxSession{}
+	for _, session := range m.getSessions() {
+		if session.ProxyId() != "" {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// forgetProxy removes the decision about the proxy of an ended session if it was still
+// pending or if the proxy has no persistent proxy id
+func (m *MuxServer) forgetProxy(session *MuxSession) {
+	proxyId := session.registeringProxyId()
+	if !m.policy.enabled() || proxyId == "" {
+		return
+	}
+	err := m.policy.forget(proxyId)
+	if err != nil {
+		m.logger.Error("failed to forget the proxy", types.LogArg{
+			"proxyId": proxyId,
+			"error":   err,
+		})
+	}
+}
+
+// pollApprovals completes the pending registrations once an operator decided,
+// with the gomcp proxy command or the inspector
+func (m *MuxServer) pollApprovals(ctx context.Context) {
+	ticker := time.NewTicker(proxyApprovalsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.applyApprovals()
+		}
+	}
+}
+
+func (m *MuxServer) applyApprovals() {
+	pendingSessions := []*MuxSession{}
+	for _, session := range m.getSessions() {
+		if session.pendingProxyId() != "" {
+			pendingSessions = append(pendingSessions, session)
+		}
+	}
+	if len(pendingSessions) == 0 {
+		return
+	}
+	approvals, err := m.policy.approvals()
+	if err != nil {
+		m.logger.Error("failed to load the proxy approvals", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+	for _, session := range pendingSessions {
+		if approval := approvals.Get(session.pendingProxyId()); approval != nil {
+			session.applyDecision(approval.Status)
+		}
+	}
+}
+
+// PendingProxies returns the proxies waiting for the decision of an operator
+func (m *MuxServer) PendingProxies() ([]config.ProxyApproval, error) {
+	pending := []config.ProxyApproval{}
+	if !m.policy.enabled() {
+		return pending, nil
+	}
+	approvals, err := m.policy.approvals()
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals.Proxies {
+		if approval.Status == config.ProxyApprovalPending {
+			pending = append(pending, approval)
+		}
+	}
+	return pending, nil
+}
+
+// ApproveProxy accepts a pending proxy, the decision is remembered for the persistent proxies
+func (m *MuxServer) ApproveProxy(proxyId string) error {
+	return m.decide(proxyId, config.ProxyApprovalApproved)
+}
+
+// DenyProxy refuses a pending proxy, the decision is remembered for the persistent proxies
+func (m *MuxServer) DenyProxy(proxyId string) error {
+	return m.decide(proxyId, config.ProxyApprovalDenied)
+}
+
+func (m *MuxServer) decide(proxyId string, status config.ProxyApprovalStatus) error {
+	err := m.policy.decide(proxyId, status)
+	if err != nil {
+		return err
+	}
+	m.applyApprovals()
+	return nil
+}
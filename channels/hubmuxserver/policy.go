@@ -0,0 +1,117 @@
+package hubmuxserver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/protocol/mux"
+)
+
+// how often the decisions of the operators are read while proxies are pending
+const proxyApprovalsPollInterval = time.Second
+
+type registrationDecision int
+
+const (
+	registrationAccepted registrationDecision = iota
+	registrationPending
+	registrationDenied
+	registrationDeniedByPolicy
+)
+
+// proxyPolicy decides which proxies are accepted, the ones the policy does not allow
+// wait for the decision of an operator in the proxy approvals file
+type proxyPolicy struct {
+	policy *config.MuxPolicyInfo
+	// the file is also written by the gomcp proxy command, it is updated under its lock
+	approvalsPath string
+}
+
+func newProxyPolicy(policy *config.MuxPolicyInfo) *proxyPolicy {
+	return &proxyPolicy{
+		policy:        policy,
+		approvalsPath: config.GetDefaultProxyApprovalsPath(),
+	}
+}
+
+// enabled returns false if all the proxies are accepted
+func (p *proxyPolicy) enabled() bool {
+	return p.policy != nil
+}
+
+// check returns the decision about a registration, the denied lists are checked first
+// and the proxies that are neither allowed nor approved are added to the pending proxies
+func (p *proxyPolicy) check(params *mux.JsonRpcRequestProxyRegisterParams) (registrationDecision, error) {
+	if !p.enabled() {
+		return registrationAccepted, nil
+	}
+	if p.policy.Denies(params.ProxyId, params.Proxy.WorkingDirectory, params.Proxy.Command) {
+		return registrationDeniedByPolicy, nil
+	}
+	if p.policy.Allows(params.ProxyId, params.Proxy.WorkingDirectory, params.Proxy.Command) {
+		return registrationAccepted, nil
+	}
+
+	decision := registrationPending
+	err := config.UpdateProxyApprovalsFile(p.approvalsPath, func(approvals *config.ProxyApprovals) (bool, error) {
+		if approval := approvals.Get(params.ProxyId); approval != nil {
+			switch approval.Status {
+			case config.ProxyApprovalApproved:
+				decision = registrationAccepted
+				return false, nil
+			case config.ProxyApprovalDenied:
+				decision = registrationDenied
+				return false, nil
+			}
+		}
+		approvals.Set(config.ProxyApproval{
+			ProxyId:          params.ProxyId,
+			Status:           config.ProxyApprovalPending,
+			Name:             params.ServerInfo.Name,
+			WorkingDirectory: params.Proxy.WorkingDirectory,
+			Command:          params.Proxy.Command,
+			Persistent:       params.Persistent,
+		})
+		return true, nil
+	})
+	if err != nil {
+		return registrationDenied, err
+	}
+	return decision, nil
+}
+
+// approvals returns the decisions about the proxies
+func (p *proxyPolicy) approvals() (*config.ProxyApprovals, error) {
+	return config.LoadProxyApprovalsFile(p.approvalsPath)
+}
+
+// decide records the decision of an operator about a pending proxy
+func (p *proxyPolicy) decide(proxyId string, status config.ProxyApprovalStatus) error {
+	return config.UpdateProxyApprovalsFile(p.approvalsPath, func(approvals *config.ProxyApprovals) (bool, error) {
+		approval := approvals.Get(proxyId)
+		if approval == nil || approval.Status != config.ProxyApprovalPending {
+			return false, fmt.Errorf("proxy %s is not pending", proxyId)
+		}
+		approval.Status = status
+		approvals.Set(*approval)
+		return true, nil
+	})
+}
+
+// forget removes the proxies that are still pending when their connection ends,
+// and the decisions about the proxies without persistent proxy id. With an empty
+// proxy id, all the pending proxies are removed (left by a hub that stopped).
+func (p *proxyPolicy) forget(proxyId string) error {
+	return config.UpdateProxyApprovalsFile(p.approvalsPath, func(approvals *config.ProxyApprovals) (bool, error) {
+		count := len(approvals.Proxies)
+		approvals.Proxies = slices.DeleteFunc(approvals.Proxies, func(approval config.ProxyApproval) bool {
+			if proxyId != "" && approval.ProxyId != proxyId {
+				return false
+			}
+			return approval.Status == config.ProxyApprovalPending || (proxyId != "" && !approval.Persistent)
+		})
+		return len(approvals.Proxies) != count, nil
+	})
+}
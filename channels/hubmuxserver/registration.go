@@ -0,0 +1,113 @@
+package hubmuxserver
+
+import (
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/types"
+)
+
+// pendingRegistration is the registration of a proxy waiting for the decision of an operator,
+// the proxy gets its response once it is approved or denied
+type pendingRegistration struct {
+	params *mux.JsonRpcRequestProxyRegisterParams
+	reqId  *jsonrpc.JsonRpcRequestId
+}
+
+// register applies the policy of the hub to an authenticated registration
+func (s *MuxSession) register(params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	s.registrationMutex.Lock()
+	s.checkedProxyId = params.ProxyId
+	s.registrationMutex.Unlock()
+
+	decision, err := s.policy.check(params)
+	if err != nil {
+		s.logger.Error("failed to check the policy", types.LogArg{
+			"proxyId": params.ProxyId,
+			"error":   err,
+		})
+		s.denyRegistration(params, reqId, "the policy of the hub could not be checked")
+		return
+	}
+	switch decision {
+	case registrationAccepted:
+		s.acceptRegistration(params, reqId)
+	case registrationDenied:
+		s.denyRegistration(params, reqId, "denied by an operator")
+	case registrationDeniedByPolicy:
+		s.denyRegistration(params, reqId, "denied by the policy of the hub")
+	case registrationPending:
+		s.logger.Info("proxy pending approval", types.LogArg{
+			"proxyId":          params.ProxyId,
+			"workingDirectory": params.Proxy.WorkingDirectory,
+			"command":          params.Proxy.Command,
+		})
+		s.registrationMutex.Lock()
+		s.pending = &pendingRegistration{params: params, reqId: reqId}
+		s.registrationMutex.Unlock()
+	}
+}
+
+func (s *MuxSession) acceptRegistration(params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// we store the proxy id in the session
+	s.SetSessionInformation(params.ProxyId, params.ServerInfo.Name)
+
+	s.logger.Info("@@ Proxy register", types.LogArg{
+		"proxyId":   params.ProxyId,
+		"proxyName": params.ServerInfo.Name,
+	})
+
+	// send the event
+	s.events.EventMuxRequestProxyRegister(params.ProxyId, params, reqId)
+}
+
+// denyRegistration answers the proxy and closes the session
+func (s *MuxSession) denyRegistration(params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId, reason string) {
+	s.logger.Error("proxy registration denied", types.LogArg{
+		"proxyId": params.ProxyId,
+		"reason":  reason,
+	})
+	s.SendJsonRpcResponse(&mux.JsonRpcResponseProxyRegisterResult{
+		SessionId:  s.sessionId,
+		ProxyId:    params.ProxyId,
+		Persistent: params.Persistent,
+		Denied:     true,
+		Reason:     reason,
+	}, reqId)
+	s.Close()
+}
+
+// pendingProxyId returns the id of the proxy waiting for a decision, empty if there is none
+func (s *MuxSession) pendingProxyId() string {
+	s.registrationMutex.Lock()
+	defer s.registrationMutex.Unlock()
+	if s.pending == nil {
+		return ""
+	}
+	return s.pending.params.ProxyId
+}
+
+// registeringProxyId returns the id of the proxy the policy was checked for, even if it was denied
+func (s *MuxSession) registeringProxyId() string {
+	s.registrationMutex.Lock()
+	defer s.registrationMutex.Unlock()
+	return s.checkedProxyId
+}
+
+// applyDecision completes the pending registration once an operator approved or denied it
+func (s *MuxSession) applyDecision(status config.ProxyApprovalStatus) {
+	s.registrationMutex.Lock()
+	pending := s.pending
+	if pending == nil || status == config.ProxyApprovalPending {
+		s.registrationMutex.Unlock()
+		return
+	}
+	s.pending = nil
+	s.registrationMutex.Unlock()
+
+	if status == config.ProxyApprovalApproved {
+		s.acceptRegistration(pending.params, pending.reqId)
+	} else {
+		s.denyRegistration(pending.params, pending.reqId, "denied by an operator")
+	}
+}
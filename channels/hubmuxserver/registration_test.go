@@ -0,0 +1,349 @@
+package hubmuxserver
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hamstah/gomcp/channels/hub/events"
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+// fakeTransport is the connection of a proxy, it records the messages sent by the hub
+type fakeTransport struct {
+	mutex  sync.Mutex
+	sent   []json.RawMessage
+	closed bool
+}
+
+func (f *fakeTransport) Start(ctx context.Context) error { return nil }
+func (f *fakeTransport) Send(message json.RawMessage) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.sent = append(f.sent, message)
+	return nil
+}
+func (f *fakeTransport) OnMessage(callback func(json.RawMessage)) {}
+func (f *fakeTransport) Close() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.closed = true
+}
+func (f *fakeTransport) OnStarted(callback func())    {}
+func (f *fakeTransport) OnClose(callback func())      {}
+func (f *fakeTransport) OnError(callback func(error)) {}
+
+// registerResult returns the response to the registration, nil if none was sent
+func (f *fakeTransport) registerResult(t *testing.T) *mux.JsonRpcResponseProxyRegisterResult {
+	t.Helper()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.sent) == 0 {
+		return nil
+	}
+	result := &mux.JsonRpcResponseProxyRegisterResult{}
+	response := struct {
+		Result *mux.JsonRpcResponseProxyRegisterResult `json:"result"`
+	}{Result: result}
+	if err := json.Unmarshal(f.sent[0], &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func (f *fakeTransport) isClosed() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.closed
+}
+
+// fakeMuxEvents records the registered proxies, the other events are not expected
+type fakeMuxEvents struct {
+	events.MuxEvents
+	mutex      sync.Mutex
+	registered []string
+}
+
+func (f *fakeMuxEvents) EventMuxRequestProxyRegister(proxyId string, params *mux.JsonRpcRequestProxyRegisterParams, reqId *jsonrpc.JsonRpcRequestId) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.registered = append(f.registered, proxyId)
+}
+
+func (f *fakeMuxEvents) registeredProxies() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.registered
+}
+
+// newTestMuxServer returns a mux server keeping the proxy approvals in a temporary file
+func newTestMuxServer(t *testing.T, policy *config.MuxPolicyInfo) (*MuxServer, *fakeMuxEvents) {
+	muxEvents := &fakeMuxEvents{}
+	server := NewMuxServer("", nil, nil, policy, muxEvents, nopLogger{})
+	server.policy.approvalsPath = filepath.Join(t.TempDir(), "proxy_approvals.json")
+	return server, muxEvents
+}
+
+// connectProxy adds the session of a proxy to the server and registers it
+func connectProxy(server *MuxServer, proxyId string, persistent bool) (*MuxSession, *fakeTransport) {
+	tran := &fakeTransport{}
+	session := NewMuxSession("s-"+proxyId, tran, nopLogger{}, server.events, server.authenticator, server.policy, false)
+	server.sessionsMutex.Lock()
+	server.sessions = append(server.sessions, session)
+	server.sessionsMutex.Unlock()
+	reqId := 1
+	session.register(&mux.JsonRpcRequestProxyRegisterParams{
+		ProxyId:    proxyId,
+		Persistent: persistent,
+		Proxy:      mux.ProxyDescription{WorkingDirectory: "/work", Command: "mcp-server"},
+		ServerInfo: mux.ServerInfo{Name: "server"},
+	}, &jsonrpc.JsonRpcRequestId{Number: &reqId})
+	return session, tran
+}
+
+func approvalStatus(t *testing.T, server *MuxServer, proxyId string) config.ProxyApprovalStatus {
+	t.Helper()
+	approvals, err := server.policy.approvals()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approval := approvals.Get(proxyId); approval != nil {
+		return approval.Status
+	}
+	return ""
+}
+
+func TestRegistrationPolicy(t *testing.T) {
+	policy := &config.MuxPolicyInfo{
+		AllowedProxyIds:          []string{"allowed"},
+		DeniedProxyIds:           []string{"denied"},
+		DeniedWorkingDirectories: []string{"/tmp/**"},
+	}
+	tests := []struct {
+		name         string
+		policy       *config.MuxPolicyInfo
+		proxyId      string
+		wantAccepted bool
+		wantDenied   bool
+		wantStatus   config.ProxyApprovalStatus
+	}{
+		{"without policy", nil, "proxy-1", true, false, ""},
+		{"allowed", policy, "allowed", true, false, ""},
+		{"denied by the policy", policy, "denied", false, true, ""},
+		{"pending", policy, "proxy-1", false, false, config.ProxyApprovalPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, muxEvents := newTestMuxServer(t, tt.policy)
+			session, tran := connectProxy(server, tt.proxyId, false)
+
+			if accepted := len(muxEvents.registeredProxies()) == 1; accepted != tt.wantAccepted {
+				t.Errorf("expected accepted %v, got %v", tt.wantAccepted, accepted)
+			}
+			result := tran.registerResult(t)
+			if denied := result != nil && result.Denied; denied != tt.wantDenied {
+				t.Errorf("expected denied %v, got %+v", tt.wantDenied, result)
+			}
+			if tran.isClosed() != tt.wantDenied {
+				t.Errorf("expected the connection closed %v", tt.wantDenied)
+			}
+			if status := approvalStatus(t, server, tt.proxyId); status != tt.wantStatus {
+				t.Errorf("expected the status %q, got %q", tt.wantStatus, status)
+			}
+			if pending := session.pendingProxyId() != ""; pending != (tt.wantStatus == config.ProxyApprovalPending) {
+				t.Errorf("unexpected pending registration %q", session.pendingProxyId())
+			}
+		})
+	}
+}
+
+func TestPendingRegistrationDecision(t *testing.T) {
+	policy := &config.MuxPolicyInfo{AllowedProxyIds: []string{"allowed"}}
+
+	t.Run("approved", func(t *testing.T) {
+		server, muxEvents := newTestMuxServer(t, policy)
+		session, tran := connectProxy(server, "proxy-1", true)
+		if pending, _ := server.PendingProxies(); len(pending) != 1 || pending[0].ProxyId != "proxy-1" {
+			t.Fatalf("expected the proxy to be pending, got %+v", pending)
+		}
+
+		if err := server.ApproveProxy("proxy-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if registered := muxEvents.registeredProxies(); len(registered) != 1 || registered[0] != "proxy-1" {
+			t.Errorf("expected the proxy to be registered, got %v", registered)
+		}
+		if session.ProxyId() != "proxy-1" || session.pendingProxyId() != "" {
+			t.Errorf("expected the session of the proxy, got %q pending %q", session.ProxyId(), session.pendingProxyId())
+		}
+		if tran.isClosed() {
+			t.Errorf("expected the connection to stay open")
+		}
+		if pending, _ := server.PendingProxies(); len(pending) != 0 {
+			t.Errorf("expected no pending proxy, got %+v", pending)
+		}
+		// only the pending proxies can be decided
+		if err := server.DenyProxy("proxy-1"); err == nil {
+			t.Errorf("expected an error")
+		}
+
+		// the decision is remembered for the next registration of the persistent proxy
+		connectProxy(server, "proxy-1", true)
+		if registered := muxEvents.registeredProxies(); len(registered) != 2 {
+			t.Errorf("expected the proxy to be registered again, got %v", registered)
+		}
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		server, muxEvents := newTestMuxServer(t, policy)
+		_, tran := connectProxy(server, "proxy-1", true)
+
+		if err := server.DenyProxy("proxy-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if registered := muxEvents.registeredProxies(); len(registered) != 0 {
+			t.Errorf("expected no registered proxy, got %v", registered)
+		}
+		if result := tran.registerResult(t); result == nil || !result.Denied || result.Reason != "denied by an operator" {
+			t.Errorf("expected the registration to be denied, got %+v", result)
+		}
+		if !tran.isClosed() {
+			t.Errorf("expected the connection to be closed")
+		}
+
+		_, tran = connectProxy(server, "proxy-1", true)
+		if result := tran.registerResult(t); result == nil || !result.Denied {
+			t.Errorf("expected the next registration to be denied, got %+v", result)
+		}
+	})
+
+	t.Run("decided with the proxy command", func(t *testing.T) {
+		server, muxEvents := newTestMuxServer(t, policy)
+		connectProxy(server, "proxy-1", true)
+
+		// the hub reads the decisions written in the file
+		if err := server.policy.decide("proxy-1", config.ProxyApprovalApproved); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		server.applyApprovals()
+		if registered := muxEvents.registeredProxies(); len(registered) != 1 {
+			t.Errorf("expected the proxy to be registered, got %v", registered)
+		}
+	})
+}
+
+func TestForgetProxyOnDisconnect(t *testing.T) {
+	policy := &config.MuxPolicyInfo{AllowedProxyIds: []string{"allowed"}}
+	tests := []struct {
+		name       string
+		persistent bool
+		decision   config.ProxyApprovalStatus
+		wantStatus config.ProxyApprovalStatus
+	}{
+		{"pending", true, "", ""},
+		{"approved persistent", true, config.ProxyApprovalApproved, config.ProxyApprovalApproved},
+		{"denied persistent", true, config.ProxyApprovalDenied, config.ProxyApprovalDenied},
+		{"approved without persistent id", false, config.ProxyApprovalApproved, ""},
+		{"denied without persistent id", false, config.ProxyApprovalDenied, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestMuxServer(t, policy)
+			session, _ := connectProxy(server, "proxy-1", tt.persistent)
+			if tt.decision != "" {
+				if err := server.decide("proxy-1", tt.decision); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			server.forgetProxy(session)
+			if status := approvalStatus(t, server, "proxy-1"); status != tt.wantStatus {
+				t.Errorf("expected the status %q, got %q", tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	server, _ := newTestMuxServer(t, &config.MuxPolicyInfo{AllowedProxyIds: []string{"allowed"}})
+	session, tran := connectProxy(server, "proxy-1", true)
+
+	// the operator approves the proxy while its connection ends
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		server.ApproveProxy("proxy-1")
+	}()
+	go func() {
+		defer wg.Done()
+		session.Close()
+	}()
+	wg.Wait()
+	if !tran.isClosed() {
+		t.Errorf("expected the connection to be closed")
+	}
+
+	if _, err := session.SendRequestWithMethodAndParams(mux.RpcRequestMethodCallTool, &mux.JsonRpcRequestToolsCallParams{}); err == nil {
+		t.Errorf("expected an error after close")
+	}
+	if _, err := session.SendRequestAndWaitResponse(context.Background(), mux.RpcRequestMethodPromptsGet, &mux.JsonRpcRequestPromptsGetParams{}); err == nil {
+		t.Errorf("expected an error after close")
+	}
+	session.SendNotificationWithParams(mux.RpcNotificationMethodRootsUpdated, &mux.JsonRpcNotificationRootsUpdatedParams{})
+	session.Close()
+}
+
+func TestInvalidRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     string
+		wantReason string
+	}{
+		{"not an object", `[]`, "invalid registration: params must be an object"},
+		{"missing fields", `{"protocolVersion":"1.0"}`, "invalid registration: missing proxyId"},
+		{"empty proxy id", `{"protocolVersion":"1.0","proxyId":"","persistent":false,"proxy":{"workingDirectory":"/work","command":"mcp-server"},"serverInfo":{"name":"server","version":"1.0"}}`, "missing proxy id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, muxEvents := newTestMuxServer(t, nil)
+			tran := &fakeTransport{}
+			session := NewMuxSession("s-001", tran, nopLogger{}, server.events, server.authenticator, server.policy, false)
+
+			var raw jsonrpc.JsonRpcRawMessage
+			if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":1,"method":"proxy/register","params":`+tt.params+`}`), &raw); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			request, _, rpcErr := jsonrpc.ParseJsonRpcRequest(raw)
+			if rpcErr != nil {
+				t.Fatalf("unexpected error: %v", rpcErr)
+			}
+			if err := session.handleIncomingMessage(transport.JsonRpcMessage{Method: request.Method, Request: request}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tran.registerResult(t); result == nil || !result.Denied || result.Reason != tt.wantReason {
+				t.Errorf("expected the registration to be denied with %q, got %+v", tt.wantReason, result)
+			}
+			if !tran.isClosed() {
+				t.Errorf("expected the connection to be closed")
+			}
+			if registered := muxEvents.registeredProxies(); len(registered) != 0 {
+				t.Errorf("expected no registered proxy, got %v", registered)
+			}
+		})
+	}
+}
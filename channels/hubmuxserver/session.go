@@ -2,6 +2,9 @@ package hubmuxserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/hamstah/gomcp/channels/hub/events"
 	"github.com/hamstah/gomcp/jsonrpc"
@@ -9,23 +12,52 @@ import (
 	"github.com/hamstah/gomcp/types"
 )
 
+// errSessionClosed is returned by the requests sent once the session ended
+var errSessionClosed = errors.New("the mux session is closed")
+
 type MuxSession struct {
 	sessionId string
 	transport *transport.JsonRpcTransport
 	logger    types.Logger
+	// set once the proxy is registered, guarded by registrationMutex
 	proxyId   string
 	proxyName string
-	events    events.Events
+	events    events.MuxEvents
+	// checks the registration of the proxy, the session is only
+	// used once the proxy is registered (proxyId is set)
+	authenticator *proxyAuthenticator
+	// common name of the client certificate with mutual TLS, it must be the proxy id
+	peerCommonName    string
+	requireClientCert bool
+	// the proxies the policy does not allow wait for an operator
+	policy            *proxyPolicy
+	pending           *pendingRegistration
+	checkedProxyId    string
+	registrationMutex sync.Mutex
+	// requests sent to the proxy waiting for their response (eg prompts/get)
+	pendingResponses      map[string]chan *jsonrpc.JsonRpcResponse
+	pendingResponsesMutex sync.Mutex
+	// closed when the session ends
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
-func NewMuxSession(sessionId string, tran types.Transport, logger types.Logger, events events.Events) *MuxSession {
+func NewMuxSession(sessionId string, tran types.Transport, logger types.Logger, events events.MuxEvents, authenticator *proxyAuthenticator, policy *proxyPolicy, requireClientCert bool) *MuxSession {
 	jsonRpcTransport := transport.NewJsonRpcTransport(tran, "gomcp - proxy (mux)", logger)
 
 	session := &MuxSession{
-		sessionId: sessionId,
-		transport: jsonRpcTransport,
-		logger:    logger,
-		events:    events,
+		sessionId:        sessionId,
+		transport:        jsonRpcTransport,
+		logger:           logger,
+		events:           events,
+		authenticator:    authenticator,
+		policy:           policy,
+		pendingResponses: map[string]chan *jsonrpc.JsonRpcResponse{},
+		done:             make(chan struct{}),
+	}
+	session.requireClientCert = requireClientCert
+	if peer, ok := tran.(transport.PeerIdentity); ok {
+		session.peerCommonName = peer.PeerCommonName()
 	}
 
 	return session
@@ -59,7 +91,11 @@ func (s *MuxSession) Start(ctx context.Context) error {
 	}
 }
 
+// SetSessionInformation stores the proxy of the session, it is read by the goroutines of
+// the other sessions and of the hub
 func (s *MuxSession) SetSessionInformation(proxyId string, serverName string) {
+	s.registrationMutex.Lock()
+	defer s.registrationMutex.Unlock()
 	s.proxyId = proxyId
 	s.proxyName = serverName
 }
@@ -69,14 +105,21 @@ func (s *MuxSession) SessionId() string {
 }
 
 func (s *MuxSession) ProxyId() string {
+	s.registrationMutex.Lock()
+	defer s.registrationMutex.Unlock()
 	return s.proxyId
 }
 
 func (s *MuxSession) ProxyName() string {
+	s.registrationMutex.Lock()
+	defer s.registrationMutex.Unlock()
 	return s.proxyName
 }
 
 func (s *MuxSession) SendJsonRpcResponse(response interface{}, id *jsonrpc.JsonRpcRequestId) {
+	if s.isClosed() {
+		return
+	}
 	s.transport.SendResponse(&jsonrpc.JsonRpcResponse{
 		JsonRpcVersion: jsonrpc.JsonRpcVersion,
 		Id:             id,
@@ -86,16 +129,108 @@ func (s *MuxSession) SendJsonRpcResponse(response interface{}, id *jsonrpc.JsonR
 }
 
 func (s *MuxSession) SendRequestWithMethodAndParams(method string, params interface{}) (*jsonrpc.JsonRpcRequestId, error) {
+	if s.isClosed() {
+		return nil, errSessionClosed
+	}
 	return s.transport.SendRequestWithMethodAndParams(method, params)
 }
 
+// GetNextRequestId reserves the id of a request sent later with SendRequestWithIdMethodAndParams,
+// the caller can get ready for its response without holding a lock while sending
+func (s *MuxSession) GetNextRequestId() *jsonrpc.JsonRpcRequestId {
+	return s.transport.GetNextRequestId()
+}
+
+func (s *MuxSession) SendRequestWithIdMethodAndParams(reqId *jsonrpc.JsonRpcRequestId, method string, params interface{}) error {
+	if s.isClosed() {
+		return errSessionClosed
+	}
+	return s.transport.SendRequestWithIdMethodAndParams(reqId, method, params)
+}
+
+// SendRequestAndWaitResponse sends a request to the proxy and blocks until the response
+// is received, the context is cancelled or the session ends.
+// The response can be an error response.
+func (s *MuxSession) SendRequestAndWaitResponse(ctx context.Context, method string, params interface{}) (*jsonrpc.JsonRpcResponse, error) {
+	responseChan := make(chan *jsonrpc.JsonRpcResponse, 1)
+
+	// we wait for the response before sending the request
+	reqId := s.transport.GetNextRequestId()
+	key := jsonrpc.RequestIdToString(reqId)
+	s.pendingResponsesMutex.Lock()
+	s.pendingResponses[key] = responseChan
+	s.pendingResponsesMutex.Unlock()
+	defer func() {
+		s.pendingResponsesMutex.Lock()
+		delete(s.pendingResponses, key)
+		s.pendingResponsesMutex.Unlock()
+	}()
+
+	if s.isClosed() {
+		return nil, errSessionClosed
+	}
+	if err := s.transport.SendRequestWithIdMethodAndParams(reqId, method, params); err != nil {
+		return nil, err
+	}
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, fmt.Errorf("the proxy %s disconnected", s.ProxyId())
+	}
+}
+
+// deliverResponse passes the response to the goroutine waiting for it,
+// it returns false if nobody is waiting for that response
+func (s *MuxSession) deliverResponse(response *jsonrpc.JsonRpcResponse) bool {
+	s.pendingResponsesMutex.Lock()
+	defer s.pendingResponsesMutex.Unlock()
+	key := jsonrpc.RequestIdToString(response.Id)
+	responseChan, ok := s.pendingResponses[key]
+	if !ok {
+		return false
+	}
+	delete(s.pendingResponses, key)
+	responseChan <- response
+	return true
+}
+
+func (s *MuxSession) SendNotificationWithParams(method string, params interface{}) {
+	if s.isClosed() {
+		return
+	}
+	err := s.transport.SendNotificationWithParams(method, params)
+	if err != nil {
+		s.logger.Error("failed to send notification", types.LogArg{
+			"method": method,
+			"error":  err,
+		})
+	}
+}
+
 func (s *MuxSession) SendError(code int, message string, id *jsonrpc.JsonRpcRequestId) {
+	if s.isClosed() {
+		return
+	}
 	s.transport.SendError(code, message, id)
 }
 
+// Close ends the session, the transport is kept so that the goroutines still sending
+// to the proxy (eg the approval of the operators) get an error
 func (s *MuxSession) Close() {
-	if s.transport != nil {
+	s.closeOnce.Do(func() {
+		close(s.done)
 		s.transport.Close()
-		s.transport = nil
+	})
+}
+
+func (s *MuxSession) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
 	}
 }
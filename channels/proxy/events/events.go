@@ -12,11 +12,21 @@ type Events interface {
 	EventMcpResponseToolsList(toolsListResponse *mcp.JsonRpcResponseToolsListResult)
 	EventMcpResponseToolCall(toolsCallResult *mcp.JsonRpcResponseToolsCallResult, reqId *jsonrpc.JsonRpcRequestId)
 	EventMcpResponseToolCallError(error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId)
+	EventMcpResponsePromptsList(promptsListResponse *mcp.JsonRpcResponsePromptsListResult)
+	EventMcpResponseForwarded(response *jsonrpc.JsonRpcResponse)
+	EventMcpNotificationPromptsListChanged()
 	EventMcpNotificationResourcesListChanged()
 	EventMcpNotificationResourcesUpdated(resourcesUpdated *mcp.JsonRpcNotificationResourcesUpdatedParams)
+	EventMcpNotificationMessage(logMessage *mcp.JsonRpcNotificationMessageParams)
+	EventMcpNotificationProgress(progress *mcp.JsonRpcNotificationProgressParams)
+	EventMcpRequestRootsList(reqId *jsonrpc.JsonRpcRequestId)
 
 	EventMuxStarted()
 	EventMuxRequestToolCall(params *mux.JsonRpcRequestToolsCallParams, mcpReqId *jsonrpc.JsonRpcRequestId)
+	EventMuxRequestPromptsGet(params *mux.JsonRpcRequestPromptsGetParams, reqId *jsonrpc.JsonRpcRequestId)
+	EventMuxRequestCompletionComplete(params *mux.JsonRpcRequestCompletionCompleteParams, reqId *jsonrpc.JsonRpcRequestId)
+	EventMuxNotificationCancelled(params *mux.JsonRpcNotificationCancelledParams)
+	EventMuxNotificationRootsUpdated(params *mux.JsonRpcNotificationRootsUpdatedParams)
 
 	EventMuxResponseProxyRegistered(registerResponse *mux.JsonRpcResponseProxyRegisterResult)
 }
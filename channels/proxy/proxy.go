@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"os/signal"
@@ -30,6 +31,12 @@ type ProxyInformation struct {
 	CurrentWorkingDirectory string
 	ProgramName             string
 	Args                    []string
+	// URL of a remote MCP server using the HTTP+SSE transport, instead of a program
+	ServerUrl string
+	// key of the hub, proves to the hub that the proxy is allowed to register
+	MuxKey []byte
+	// TLS configuration of the mux link, nil without TLS
+	MuxTlsConfig *tls.Config
 }
 
 const (
@@ -43,8 +50,9 @@ func NewProxyClient(proxyInformation ProxyInformation, debug bool, logger types.
 		ProgramName:             proxyInformation.ProgramName,
 		ProgramArgs:             proxyInformation.Args,
 		ProxyId:                 proxyInformation.ProxyId,
+		ServerUrl:               proxyInformation.ServerUrl,
 	}
-	stateManager := NewStateManager(&options, tools.NewProxyToolsRegistry(), logger)
+	stateManager := NewStateManager(&options, proxyInformation.MuxKey, tools.NewProxyToolsRegistry(), logger)
 	events := stateManager.AsEvents()
 
 	return &ProxyClient{
@@ -81,6 +89,7 @@ func (c *ProxyClient) Start() error {
 
 	muxClient := proxymuxclient.NewProxyMuxClient(
 		c.proxyInformation.MuxAddress,
+		c.proxyInformation.MuxTlsConfig,
 		c.events,
 		c.logger,
 	)
@@ -1,6 +1,8 @@
 package proxy
 
 import (
+	"sync"
+
 	"github.com/hamstah/gomcp/channels/proxy/events"
 	"github.com/hamstah/gomcp/channels/proxymcpclient"
 	"github.com/hamstah/gomcp/channels/proxymuxclient"
@@ -14,20 +16,43 @@ import (
 )
 
 type StateManager struct {
-	logger    types.Logger
-	options   *transport.ProxiedMcpServerDescription
+	logger  types.Logger
+	options *transport.ProxiedMcpServerDescription
+	// key of the hub, the registration is signed with it
+	muxKey    []byte
 	muxClient *proxymuxclient.ProxyMuxClient
 	mcpClient *proxymcpclient.ProxyMcpClient
 	registry  *tools.ProxyToolsRegistry
 	// serverInfo is the info about the MCP server we are connected to
-	serverInfo   mcp.ServerInfo
+	serverInfo mcp.ServerInfo
+	// protocol version agreed with the MCP server
+	protocolVersion string
+	// true if the MCP server completes the arguments of its prompts
+	supportsCompletions bool
+	// the mapping of a request forwarded by the hub (tool call, prompt, completion)
+	// is stored before the request is sent, the HTTP transports can deliver
+	// the response before the send returns
 	reqIdMapping *jsonrpc.ReqIdMapping
+	// tools received so far when the tools list is paginated
+	pendingTools []mcp.ToolDescription
+	// prompts received so far when the prompts list is paginated
+	pendingPrompts []mcp.PromptDescription
+	// prompts of the MCP server, sent to the hub once the proxy is registered
+	prompts         []mux.PromptDescription
+	isMuxRegistered bool
+	promptsMutex    sync.Mutex
+	// roots of the hub client, the MCP server can list them
+	roots      []mcp.Root
+	rootsMutex sync.Mutex
+	// true once the MCP server can receive notifications
+	isMcpInitialized bool
 }
 
-func NewStateManager(options *transport.ProxiedMcpServerDescription,
+func NewStateManager(options *transport.ProxiedMcpServerDescription, muxKey []byte,
 	registry *tools.ProxyToolsRegistry, logger types.Logger) *StateManager {
 	return &StateManager{
 		options:      options,
+		muxKey:       muxKey,
 		logger:       logger,
 		serverInfo:   mcp.ServerInfo{},
 		reqIdMapping: jsonrpc.NewReqIdMapping(),
@@ -56,7 +81,12 @@ func (s *StateManager) EventMcpStarted() {
 	// to the MCP server
 	params := mcp.JsonRpcRequestInitializeParams{
 		ProtocolVersion: mcp.ProtocolVersion,
-		Capabilities:    mcp.ClientCapabilities{},
+		// we answer the roots/list requests with the roots sent by the hub
+		Capabilities: mcp.ClientCapabilities{
+			Roots: &mcp.ClientCapabilitiesRoots{
+				ListChanged: jsonrpc.BoolPtr(true),
+			},
+		},
 		ClientInfo: mcp.ClientInfo{
 			Name:    s.options.ProxyName,
 			Version: version.Version,
@@ -72,16 +102,103 @@ func (s *StateManager) EventMcpResponseInitialize(resp *mcp.JsonRpcResponseIniti
 		"version": resp.ServerInfo.Version,
 	})
 
+	// the server answers with the version it wants to use, it may be
+	// older than the one we requested
+	if !mcp.IsSupportedProtocolVersion(resp.ProtocolVersion) {
+		s.logger.Error("unsupported protocol version proposed by the MCP server", types.LogArg{
+			"proposed":  resp.ProtocolVersion,
+			"supported": mcp.SupportedProtocolVersions(),
+		})
+	}
+	s.protocolVersion = resp.ProtocolVersion
+	s.supportsCompletions = resp.Capabilities.Completions != nil
+
 	// we update the server information
 	s.serverInfo.Name = resp.ServerInfo.Name
 	s.serverInfo.Version = resp.ServerInfo.Version
 
 	// we send the "notifications/initialized" notification
 	s.mcpClient.SendNotification(mcp.RpcNotificationMethodInitialized)
+	s.rootsMutex.Lock()
+	s.isMcpInitialized = true
+	s.rootsMutex.Unlock()
+
+	// if the server supports logging, we ask for all the messages,
+	// the hub filters them with the level chosen by its client
+	if resp.Capabilities.Logging != nil {
+		s.mcpClient.SendRequestWithMethodAndParams(
+			mcp.RpcRequestMethodLoggingSetLevel, mcp.JsonRpcRequestLoggingSetLevelParams{Level: mcp.LoggingLevelDebug})
+	}
 
 	// we send the "tools/list" request
 	s.mcpClient.SendRequestWithMethodAndParams(
 		mcp.RpcRequestMethodToolsList, mcp.JsonRpcRequestToolsListParams{})
+
+	// the prompts are served by the hub, we send them once listed
+	if resp.Capabilities.Prompts != nil {
+		s.mcpClient.SendRequestWithMethodAndParams(
+			mcp.RpcRequestMethodPromptsList, mcp.JsonRpcRequestPromptsListParams{})
+	}
+}
+
+func (s *StateManager) EventMcpResponsePromptsList(resp *mcp.JsonRpcResponsePromptsListResult) {
+	s.logger.Info("event mcp prompts list response", types.LogArg{
+		"prompts": len(resp.Prompts),
+	})
+
+	// the list may be paginated, we request the next page until we have all the prompts
+	s.promptsMutex.Lock()
+	s.pendingPrompts = append(s.pendingPrompts, resp.Prompts...)
+	if resp.NextCursor != nil {
+		s.promptsMutex.Unlock()
+		s.mcpClient.SendRequestWithMethodAndParams(
+			mcp.RpcRequestMethodPromptsList, mcp.JsonRpcRequestPromptsListParams{Cursor: resp.NextCursor})
+		return
+	}
+	s.prompts = make([]mux.PromptDescription, 0, len(s.pendingPrompts))
+	for _, prompt := range s.pendingPrompts {
+		arguments := make([]mux.PromptArgumentDescription, 0, len(prompt.Arguments))
+		for _, argument := range prompt.Arguments {
+			arguments = append(arguments, mux.PromptArgumentDescription{
+				Name:        argument.Name,
+				Description: argument.Description,
+				Required:    argument.Required,
+			})
+		}
+		s.prompts = append(s.prompts, mux.PromptDescription{
+			Name:        prompt.Name,
+			Description: prompt.Description,
+			Arguments:   arguments,
+		})
+	}
+	s.pendingPrompts = nil
+	s.promptsMutex.Unlock()
+
+	s.registerPrompts()
+}
+
+// the prompts of the MCP server changed, we list them again
+func (s *StateManager) EventMcpNotificationPromptsListChanged() {
+	s.promptsMutex.Lock()
+	s.pendingPrompts = nil
+	s.promptsMutex.Unlock()
+	s.mcpClient.SendRequestWithMethodAndParams(
+		mcp.RpcRequestMethodPromptsList, mcp.JsonRpcRequestPromptsListParams{})
+}
+
+// registerPrompts sends the prompts to the hub, once they are listed and the proxy is registered
+func (s *StateManager) registerPrompts() {
+	s.promptsMutex.Lock()
+	if !s.isMuxRegistered || s.prompts == nil || s.muxClient == nil {
+		s.promptsMutex.Unlock()
+		return
+	}
+	params := mux.JsonRpcRequestPromptsRegisterParams{
+		Prompts: s.prompts,
+	}
+	s.promptsMutex.Unlock()
+
+	s.muxClient.SendRequestWithMethodAndParams(mux.RpcRequestMethodPromptsRegister, params)
 }
 
 func (s *StateManager) EventMcpResponseToolsList(resp *mcp.JsonRpcResponseToolsListResult) {
@@ -89,6 +206,16 @@ func (s *StateManager) EventMcpResponseToolsList(resp *mcp.JsonRpcResponseToolsL
 		"tools": resp.Tools,
 	})
 
+	// the list may be paginated, we request the next page until we have all the tools
+	s.pendingTools = append(s.pendingTools, resp.Tools...)
+	if resp.NextCursor != nil {
+		s.mcpClient.SendRequestWithMethodAndParams(
+			mcp.RpcRequestMethodToolsList, mcp.JsonRpcRequestToolsListParams{Cursor: resp.NextCursor})
+		return
+	}
+	allTools := s.pendingTools
+	s.pendingTools = nil
+
 	// we register the tools in the registry
 	proxyToolsDefinition := tools.ProxyDefinition{
 		ProxyId:          s.options.ProxyId,
@@ -96,14 +223,11 @@ func (s *StateManager) EventMcpResponseToolsList(resp *mcp.JsonRpcResponseToolsL
 		ProxyName:        s.options.ProxyName,
 		ProgramName:      s.options.ProgramName,
 		ProgramArguments: s.options.ProgramArgs,
+		ServerUrl:        s.options.ServerUrl,
 		Tools:            []tools.ProxyToolDefinition{},
 	}
-	for _, tool := range resp.Tools {
-		proxyToolsDefinition.Tools = append(proxyToolsDefinition.Tools, tools.ProxyToolDefinition{
-			Name:        tool.Name,
-			Description: tool.Description,
-			InputSchema: tool.InputSchema,
-		})
+	for _, tool := range allTools {
+		proxyToolsDefinition.Tools = append(proxyToolsDefinition.Tools, toProxyToolDefinition(tool))
 	}
 	err := s.registry.AddProxyDefinition(&proxyToolsDefinition)
 	if err != nil {
@@ -127,20 +251,57 @@ func (s *StateManager) EventMcpResponseToolsList(resp *mcp.JsonRpcResponseToolsL
 	// s.muxClient.SendRequestWithMethodAndParams(mux.RpcRequestMethodToolsRegister, params)
 }
 
+// toProxyToolDefinition keeps the title and annotations reported by the MCP server,
+// servers using 2025-03-26 can only set the title in the annotations
+func toProxyToolDefinition(tool mcp.ToolDescription) tools.ProxyToolDefinition {
+	proxyTool := tools.ProxyToolDefinition{
+		Name:         tool.Name,
+		Title:        tool.Title,
+		Description:  tool.Description,
+		InputSchema:  tool.InputSchema,
+		OutputSchema: tool.OutputSchema,
+	}
+	if tool.Annotations != nil {
+		if proxyTool.Title == "" && tool.Annotations.Title != nil {
+			proxyTool.Title = *tool.Annotations.Title
+		}
+		proxyTool.Annotations = &types.ToolAnnotations{
+			ReadOnlyHint:    tool.Annotations.ReadOnlyHint,
+			DestructiveHint: tool.Annotations.DestructiveHint,
+			IdempotentHint:  tool.Annotations.IdempotentHint,
+			OpenWorldHint:   tool.Annotations.OpenWorldHint,
+		}
+	}
+	return proxyTool
+}
+
 func (s *StateManager) EventMuxStarted() {
 	s.logger.Debug("Mux Server started", types.LogArg{})
+	// the prompts are sent again once the new connection is registered
+	s.promptsMutex.Lock()
+	s.isMuxRegistered = false
+	s.promptsMutex.Unlock()
+
+	authentication, err := mux.NewProxyAuthentication(s.muxKey, s.options.ProxyId)
+	if err != nil {
+		s.logger.Error("failed to sign the proxy registration", types.LogArg{"error": err})
+		return
+	}
 	params := mux.JsonRpcRequestProxyRegisterParams{
 		ProtocolVersion: mux.MuxProtocolVersion,
 		ProxyId:         s.options.ProxyId,
+		// the proxy id is kept in gomcp-proxy.json, the hub remembers the decisions about it
+		Persistent: true,
 		Proxy: mux.ProxyDescription{
 			WorkingDirectory: s.options.CurrentWorkingDirectory,
-			Command:          s.options.ProgramName,
+			Command:          s.options.Command(),
 			Args:             s.options.ProgramArgs,
 		},
 		ServerInfo: mux.ServerInfo{
 			Name:    s.serverInfo.Name,
 			Version: s.serverInfo.Version,
 		},
+		Authentication: authentication,
 	}
 
 	// we register the proxy to the mux server
@@ -154,7 +315,19 @@ func (s *StateManager) EventMuxResponseProxyRegistered(registerResponse *mux.Jso
 		"persistent": registerResponse.Persistent,
 		"denied":     registerResponse.Denied,
 	})
+	// the hub closes the connection, the proxy stops
+	if registerResponse.Denied {
+		s.logger.Error("the hub denied the registration of the proxy", types.LogArg{
+			"proxyId": registerResponse.ProxyId,
+			"reason":  registerResponse.Reason,
+		})
+		return
+	}
 
+	s.promptsMutex.Lock()
+	s.isMuxRegistered = true
+	s.promptsMutex.Unlock()
+	s.registerPrompts()
 }
 
 // this is a tool call from the hub
@@ -168,16 +341,140 @@ func (s *StateManager) EventMuxRequestToolCall(params *mux.JsonRpcRequestToolsCa
 		Name:      params.Name,
 		Arguments: params.Args,
 	}
+	// we reuse the progress token of the hub client, the notifications
+	// are forwarded as is to the hub
+	if params.ProgressToken != nil {
+		req.Meta = &mcp.RequestMeta{
+			ProgressToken: params.ProgressToken,
+		}
+	}
 
 	// we forward the tool call to the mcp client
-	mcpReqId, err := s.mcpClient.SendRequestWithMethodAndParams(mcp.RpcRequestMethodToolsCall, req)
-	if err != nil {
-		s.logger.Error("failed to send request to mcp client", types.LogArg{"error": err})
+	s.forwardRequest(mcp.RpcRequestMethodToolsCall, req, reqId)
+}
+
+// the hub gets a prompt of the MCP server
+func (s *StateManager) EventMuxRequestPromptsGet(params *mux.JsonRpcRequestPromptsGetParams, reqId *jsonrpc.JsonRpcRequestId) {
+	s.forwardRequest(mcp.RpcRequestMethodPromptsGet, mcp.JsonRpcRequestPromptsGetParams{
+		Name:      params.Name,
+		Arguments: params.Arguments,
+	}, reqId)
+}
+
+// the hub completes an argument of a prompt of the MCP server
+func (s *StateManager) EventMuxRequestCompletionComplete(params *mux.JsonRpcRequestCompletionCompleteParams, reqId *jsonrpc.JsonRpcRequestId) {
+	// the prompts of the servers without completions have no suggestions
+	if !s.supportsCompletions {
+		s.muxClient.SendJsonRpcResponse(mcp.NewCompletionResult(nil), reqId)
 		return
 	}
-	// we keep track of the mapping between the mcp request id
-	// and the mux request id
+	req := mcp.JsonRpcRequestCompletionCompleteParams{
+		Ref: mcp.CompletionReference{
+			Type: mcp.CompletionRefPrompt,
+			Name: params.Prompt,
+		},
+		Argument: mcp.CompletionArgument{
+			Name:  params.Argument,
+			Value: params.Value,
+		},
+	}
+	if len(params.Arguments) > 0 {
+		req.Context = &mcp.CompletionContext{
+			Arguments: params.Arguments,
+		}
+	}
+	s.forwardRequest(mcp.RpcRequestMethodCompletionComplete, req, reqId)
+}
+
+// forwardRequest sends a request of the hub to the MCP server, the response
+// is sent back unchanged by EventMcpResponseForwarded
+func (s *StateManager) forwardRequest(method string, params interface{}, reqId *jsonrpc.JsonRpcRequestId) {
+	// we keep track of the mapping between the mcp request id and the mux request id,
+	// no lock is held while sending as the pipe of the MCP server can be full
+	mcpReqId := s.mcpClient.GetNextRequestId()
 	s.reqIdMapping.AddMapping(mcpReqId, reqId)
+	err := s.mcpClient.SendRequestWithIdMethodAndParams(mcpReqId, method, params)
+	if err != nil {
+		s.reqIdMapping.GetMapping(mcpReqId)
+		s.logger.Error("failed to send request to mcp client", types.LogArg{
+			"method": method,
+			"error":  err,
+		})
+		s.muxClient.SendError(jsonrpc.RpcInternalError, err.Error(), reqId)
+	}
+}
+
+// the MCP server answered a request forwarded by forwardRequest
+func (s *StateManager) EventMcpResponseForwarded(response *jsonrpc.JsonRpcResponse) {
+	muxReqId := s.getMuxReqId(response.Id)
+	if muxReqId == nil {
+		s.logger.Info("dropping response of unknown request", types.LogArg{
+			"reqId": jsonrpc.RequestIdToString(response.Id),
+		})
+		return
+	}
+	if response.Error != nil {
+		s.muxClient.SendError(response.Error.Code, response.Error.Message, muxReqId)
+		return
+	}
+	s.muxClient.SendJsonRpcResponse(response.Result, muxReqId)
+}
+
+// getMuxReqId returns the request id of the hub for a request forwarded to the MCP server
+func (s *StateManager) getMuxReqId(mcpReqId *jsonrpc.JsonRpcRequestId) *jsonrpc.JsonRpcRequestId {
+	return s.reqIdMapping.GetMapping(mcpReqId)
+}
+
+// the MCP server asks for the roots, we answer with the ones of the hub client
+func (s *StateManager) EventMcpRequestRootsList(reqId *jsonrpc.JsonRpcRequestId) {
+	s.rootsMutex.Lock()
+	result := mcp.JsonRpcResponseRootsListResult{
+		Roots: make([]mcp.Root, len(s.roots)),
+	}
+	copy(result.Roots, s.roots)
+	s.rootsMutex.Unlock()
+
+	s.mcpClient.SendJsonRpcResponse(&result, reqId)
+}
+
+// the hub sent the roots of its client
+func (s *StateManager) EventMuxNotificationRootsUpdated(params *mux.JsonRpcNotificationRootsUpdatedParams) {
+	s.logger.Info("event mux notification roots updated", types.LogArg{
+		"roots": params.Roots,
+	})
+	roots := make([]mcp.Root, 0, len(params.Roots))
+	for _, root := range params.Roots {
+		roots = append(roots, mcp.Root{
+			Uri:  root.Uri,
+			Name: root.Name,
+		})
+	}
+	s.rootsMutex.Lock()
+	s.roots = roots
+	isMcpInitialized := s.isMcpInitialized
+	s.rootsMutex.Unlock()
+
+	// the MCP server will request the new list
+	if isMcpInitialized {
+		s.mcpClient.SendNotification(mcp.RpcNotificationMethodRootsListChanged)
+	}
+}
+
+// the hub client cancelled a tool call
+func (s *StateManager) EventMuxNotificationCancelled(params *mux.JsonRpcNotificationCancelledParams) {
+	// the request id is the one of the hub, we find the one we used
+	// with the mcp server, the late response will then be dropped
+	mcpReqId := s.reqIdMapping.GetReverseMapping(jsonrpc.RequestIdFromValue(params.RequestId))
+	if mcpReqId == nil {
+		s.logger.Info("ignoring cancellation of unknown tool call", types.LogArg{
+			"requestId": params.RequestId,
+		})
+		return
+	}
+	s.mcpClient.SendNotificationWithParams(mcp.RpcNotificationMethodCancelled, &mcp.JsonRpcNotificationCancelledParams{
+		RequestId: jsonrpc.RequestIdToValue(mcpReqId),
+		Reason:    params.Reason,
+	})
 }
 
 // got the response for the tool call from the mcp client
@@ -188,19 +485,34 @@ func (s *StateManager) EventMcpResponseToolCall(toolsCallResult *mcp.JsonRpcResp
 	})
 	//s.muxClient.SendToolCallResponse(toolsCallResult, reqId, mcpReqId)
 	params := mux.JsonRpcResponseToolsCallResult{
-		Content: toolsCallResult.Content,
-		IsError: toolsCallResult.IsError,
+		Content:           toolsCallResult.Content,
+		StructuredContent: toolsCallResult.StructuredContent,
+		IsError:           toolsCallResult.IsError,
 	}
 	// we parse the req id is the one coming from the hub
 	// and we send the response to the hub with that id
-	muxReqId := s.reqIdMapping.GetMapping(reqId)
+	muxReqId := s.getMuxReqId(reqId)
+	if muxReqId == nil {
+		// the tool call was cancelled by the hub
+		s.logger.Info("dropping response of cancelled tool call", types.LogArg{
+			"reqId": jsonrpc.RequestIdToString(reqId),
+		})
+		return
+	}
 	s.muxClient.SendJsonRpcResponse(params, muxReqId)
 }
 
 func (s *StateManager) EventMcpResponseToolCallError(error *jsonrpc.JsonRpcError, reqId *jsonrpc.JsonRpcRequestId) {
 	// we parse the req id is the one coming from the hub
 	// and we send the response to the hub with that id
-	muxReqId := s.reqIdMapping.GetMapping(reqId)
+	muxReqId := s.getMuxReqId(reqId)
+	if muxReqId == nil {
+		// the tool call was cancelled by the hub
+		s.logger.Info("dropping error of cancelled tool call", types.LogArg{
+			"reqId": jsonrpc.RequestIdToString(reqId),
+		})
+		return
+	}
 	s.muxClient.SendError(error.Code, error.Message, muxReqId)
 }
 
@@ -213,3 +525,30 @@ func (s *StateManager) EventMcpNotificationResourcesUpdated(resourcesUpdated *mc
 		"uri": resourcesUpdated.Uri,
 	})
 }
+
+func (s *StateManager) EventMcpNotificationMessage(logMessage *mcp.JsonRpcNotificationMessageParams) {
+	if s.muxClient == nil {
+		return
+	}
+	// we forward the log message to the hub
+	params := mux.JsonRpcNotificationMessageParams{
+		Level:  logMessage.Level,
+		Logger: logMessage.Logger,
+		Data:   logMessage.Data,
+	}
+	s.muxClient.SendNotificationWithParams(mux.RpcNotificationMethodMessage, params)
+}
+
+func (s *StateManager) EventMcpNotificationProgress(progress *mcp.JsonRpcNotificationProgressParams) {
+	if s.muxClient == nil {
+		return
+	}
+	// we forward the progress to the hub
+	params := mux.JsonRpcNotificationProgressParams{
+		ProgressToken: progress.ProgressToken,
+		Progress:      progress.Progress,
+		Total:         progress.Total,
+		Message:       progress.Message,
+	}
+	s.muxClient.SendNotificationWithParams(mux.RpcNotificationMethodProgress, params)
+}
@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/channels/proxymcpclient"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+// waitMessage returns the next message of the proxy with that method, the other ones are skipped
+func waitMessage(t *testing.T, ctx context.Context, messages chan transport.JsonRpcMessage, method string) transport.JsonRpcMessage {
+	t.Helper()
+	for {
+		select {
+		case message := <-messages:
+			if message.Method == method {
+				return message
+			}
+		case <-ctx.Done():
+			t.Fatalf("no %s message received", method)
+		}
+	}
+}
+
+// startProxy connects a proxy to the test, acting as its MCP server with the SSE transport,
+// and initializes it with the capabilities
+func startProxy(t *testing.T, ctx context.Context, capabilities map[string]interface{}) (*StateManager, *transport.JsonRpcTransport, chan transport.JsonRpcMessage) {
+	t.Helper()
+	sessions := make(chan *transport.JsonRpcTransport, 1)
+	messages := make(chan transport.JsonRpcMessage, 10)
+	sseTransport := transport.NewSseServerTransport(types.SseOptions{}, nopLogger{})
+	sseTransport.OnSession(func(session types.Transport) {
+		jsonRpcTransport := transport.NewJsonRpcTransport(session, "server", nopLogger{})
+		go jsonRpcTransport.Start(ctx, func(message transport.JsonRpcMessage, jsonRpcTransport *transport.JsonRpcTransport) {
+			messages <- message
+		})
+		sessions <- jsonRpcTransport
+	})
+	go sseTransport.Start(ctx)
+	server := httptest.NewServer(sseTransport)
+	t.Cleanup(server.Close)
+
+	options := &transport.ProxiedMcpServerDescription{ProxyName: "test", ServerUrl: server.URL + "/sse"}
+	stateManager := NewStateManager(options, nil, nil, nopLogger{})
+	mcpClient := proxymcpclient.NewProxyMcpClient(stateManager.AsEvents(), options, nopLogger{})
+	stateManager.SetProxyClient(mcpClient)
+	go mcpClient.Start(ctx)
+
+	var session *transport.JsonRpcTransport
+	select {
+	case session = <-sessions:
+	case <-ctx.Done():
+		t.Fatalf("the proxy did not connect")
+	}
+
+	// the proxy declares the roots capability to its MCP server
+	initialize := waitMessage(t, ctx, messages, mcp.RpcRequestMethodInitialize)
+	params, err := mcp.ParseJsonRpcRequestInitialize(initialize.Request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.Capabilities.Roots == nil {
+		t.Errorf("expected the roots capability")
+	}
+	session.SendResponseWithResults(initialize.Request.Id, map[string]interface{}{
+		"protocolVersion": mcp.ProtocolVersion,
+		"capabilities":    capabilities,
+		"serverInfo":      map[string]interface{}{"name": "server", "version": "1.0"},
+	})
+	return stateManager, session, messages
+}
+
+func TestRootsListAnsweredByTheProxy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stateManager, session, messages := startProxy(t, ctx, map[string]interface{}{})
+	// the tools are listed once the MCP server is initialized
+	waitMessage(t, ctx, messages, mcp.RpcRequestMethodToolsList)
+
+	// the roots forwarded by the hub are announced to the MCP server
+	stateManager.EventMuxNotificationRootsUpdated(&mux.JsonRpcNotificationRootsUpdatedParams{
+		Roots: []mux.Root{{Uri: "file:///work"}},
+	})
+	waitMessage(t, ctx, messages, mcp.RpcNotificationMethodRootsListChanged)
+
+	// and the proxy answers roots/list with them
+	if _, err := session.SendRequestWithMethodAndParams(mcp.RpcRequestMethodRootsList, &mcp.JsonRpcRequestRootsListParams{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response := waitMessage(t, ctx, messages, mcp.RpcRequestMethodRootsList)
+	if response.Response == nil {
+		t.Fatalf("expected a response, got %+v", response)
+	}
+	result, err := mcp.ParseJsonRpcResponseRootsList(response.Response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []mcp.Root{{Uri: "file:///work"}}; !reflect.DeepEqual(result.Roots, want) {
+		t.Errorf("expected the roots %v, got %v", want, result.Roots)
+	}
+}
+
+func TestPromptsListedByTheProxy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stateManager, session, messages := startProxy(t, ctx, map[string]interface{}{
+		"prompts": map[string]interface{}{"listChanged": true},
+	})
+
+	// the prompts are listed page by page
+	request := waitMessage(t, ctx, messages, mcp.RpcRequestMethodPromptsList)
+	session.SendResponseWithResults(request.Request.Id, map[string]interface{}{
+		"prompts": []interface{}{
+			map[string]interface{}{
+				"name": "hello",
+				"arguments": []interface{}{
+					map[string]interface{}{"name": "name", "required": true},
+				},
+			},
+		},
+		"nextCursor": "2",
+	})
+	request = waitMessage(t, ctx, messages, mcp.RpcRequestMethodPromptsList)
+	if cursor := request.Request.Params.NamedParams["cursor"]; cursor != "2" {
+		t.Errorf("expected the cursor 2, got %v", cursor)
+	}
+	session.SendResponseWithResults(request.Request.Id, map[string]interface{}{
+		"prompts": []interface{}{
+			map[string]interface{}{"name": "bye", "description": "Say goodbye"},
+		},
+	})
+
+	want := []mux.PromptDescription{
+		{Name: "hello", Arguments: []mux.PromptArgumentDescription{{Name: "name", Required: true}}},
+		{Name: "bye", Description: "Say goodbye", Arguments: []mux.PromptArgumentDescription{}},
+	}
+	for {
+		stateManager.promptsMutex.Lock()
+		prompts := stateManager.prompts
+		stateManager.promptsMutex.Unlock()
+		if prompts != nil {
+			if !reflect.DeepEqual(prompts, want) {
+				t.Errorf("expected the prompts %+v, got %+v", want, prompts)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("the prompts were not listed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestToolCallCancelledByTheHub(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stateManager, _, messages := startProxy(t, ctx, map[string]interface{}{"tools": map[string]interface{}{}})
+	waitMessage(t, ctx, messages, mcp.RpcRequestMethodToolsList)
+
+	// the tool call of the hub is sent to the MCP server with another request id
+	muxReqId := 42
+	stateManager.EventMuxRequestToolCall(&mux.JsonRpcRequestToolsCallParams{Name: "wait"}, &jsonrpc.JsonRpcRequestId{Number: &muxReqId})
+	call := waitMessage(t, ctx, messages, mcp.RpcRequestMethodToolsCall)
+
+	// the cancellation of the hub is forwarded with the request id of the MCP server
+	stateManager.EventMuxNotificationCancelled(&mux.JsonRpcNotificationCancelledParams{RequestId: muxReqId})
+	cancelled := waitMessage(t, ctx, messages, mcp.RpcNotificationMethodCancelled)
+	if requestId := cancelled.Request.Params.NamedParams["requestId"]; jsonrpc.RequestIdToString(jsonrpc.RequestIdFromValue(requestId)) != jsonrpc.RequestIdToString(call.Request.Id) {
+		t.Errorf("expected the request id %s, got %v", jsonrpc.RequestIdToString(call.Request.Id), requestId)
+	}
+
+	// the late response of the MCP server is dropped, it is not sent to the hub
+	// (the proxy of the test has no mux client)
+	stateManager.EventMcpResponseToolCall(&mcp.JsonRpcResponseToolsCallResult{}, call.Request.Id)
+}
@@ -24,6 +24,8 @@ func (c *ProxyMcpClient) handleIncomingMessage(message transport.JsonRpcMessage)
 			case mcp.RpcRequestMethodToolsCall:
 				// we forward the response to the hubmux server
 				c.events.EventMcpResponseToolCallError(response.Error, response.Id)
+			case mcp.RpcRequestMethodPromptsGet, mcp.RpcRequestMethodCompletionComplete:
+				c.events.EventMcpResponseForwarded(response)
 			}
 			return nil
 		}
@@ -56,6 +58,21 @@ func (c *ProxyMcpClient) handleIncomingMessage(message transport.JsonRpcMessage)
 
 				c.events.EventMcpResponseToolsList(toolsListResponse)
 			}
+		case mcp.RpcRequestMethodPromptsList:
+			{
+				promptsListResponse, err := mcp.ParseJsonRpcResponsePromptsList(response)
+				if err != nil {
+					c.logger.Error("error in handleMcpPromptsListResponse", types.LogArg{
+						"error": err,
+					})
+					return nil
+				}
+
+				c.events.EventMcpResponsePromptsList(promptsListResponse)
+			}
+		case mcp.RpcRequestMethodPromptsGet, mcp.RpcRequestMethodCompletionComplete:
+			// the hub reads the result, we send it back unchanged
+			c.events.EventMcpResponseForwarded(response)
 		case mcp.RpcRequestMethodToolsCall:
 			{
 				toolsCallResult, err := mcp.ParseJsonRpcResponseToolsCall(response)
@@ -74,6 +91,8 @@ func (c *ProxyMcpClient) handleIncomingMessage(message transport.JsonRpcMessage)
 				// we forward the response to the hubmux server
 				c.events.EventMcpResponseToolCall(toolsCallResult, response.Id)
 			}
+		case mcp.RpcRequestMethodLoggingSetLevel:
+			c.logger.Debug("logging level set", types.LogArg{})
 
 		default:
 			c.logger.Error("received message with unexpected method", types.LogArg{
@@ -99,6 +118,37 @@ func (c *ProxyMcpClient) handleIncomingMessage(message transport.JsonRpcMessage)
 			{
 				c.events.EventMcpNotificationResourcesListChanged()
 			}
+		case mcp.RpcNotificationMethodPromptsListChanged:
+			{
+				c.events.EventMcpNotificationPromptsListChanged()
+			}
+		case mcp.RpcNotificationMethodProgress:
+			{
+				progress, err := mcp.ParseJsonRpcNotificationProgressParams(request.Params)
+				if err != nil {
+					c.logger.Error("error parsing progress", types.LogArg{
+						"error": err,
+					})
+					return nil
+				}
+				c.events.EventMcpNotificationProgress(progress)
+			}
+		case mcp.RpcRequestMethodRootsList:
+			{
+				// the MCP server asks for the roots of the client
+				c.events.EventMcpRequestRootsList(request.Id)
+			}
+		case mcp.RpcNotificationMethodMessage:
+			{
+				logMessage, err := mcp.ParseJsonRpcNotificationMessageParams(request.Params)
+				if err != nil {
+					c.logger.Error("error parsing log message", types.LogArg{
+						"error": err,
+					})
+					return nil
+				}
+				c.events.EventMcpNotificationMessage(logMessage)
+			}
 		default:
 			c.logger.Error("received message with unexpected method", types.LogArg{
 				"method":  message.Method,
@@ -35,8 +35,14 @@ func (c *ProxyMcpClient) Start(ctx context.Context) error {
 	var err error
 	errProxyChan := make(chan error, 1)
 
-	// create the transport for the proxy client
-	proxyTransport := transport.NewStdioProxyClientTransport(c.options)
+	// create the transport for the proxy client, the MCP server
+	// is either a program we start or a remote SSE server
+	var proxyTransport types.Transport
+	if c.options.ServerUrl != "" {
+		proxyTransport = transport.NewSseClientTransport(c.options.ServerUrl, c.logger)
+	} else {
+		proxyTransport = transport.NewStdioProxyClientTransport(c.options)
+	}
 
 	clientMcpJsonRpcTransport := transport.NewJsonRpcTransport(proxyTransport, "proxy - mcpclient", c.logger)
 	c.transport = clientMcpJsonRpcTransport
@@ -86,6 +92,16 @@ func (c *ProxyMcpClient) SendNotification(method string) {
 	c.transport.SendRequest(&notification)
 }
 
+func (c *ProxyMcpClient) SendNotificationWithParams(method string, params interface{}) {
+	err := c.transport.SendNotificationWithParams(method, params)
+	if err != nil {
+		c.logger.Error("failed to send notification", types.LogArg{
+			"method": method,
+			"error":  err,
+		})
+	}
+}
+
 func (s *ProxyMcpClient) SendJsonRpcResponse(response interface{}, id *jsonrpc.JsonRpcRequestId) {
 	s.transport.SendResponse(&jsonrpc.JsonRpcResponse{
 		JsonRpcVersion: jsonrpc.JsonRpcVersion,
@@ -107,6 +123,15 @@ func (s *ProxyMcpClient) SendRequestWithMethodAndParams(method string, params in
 	return s.transport.SendRequestWithMethodAndParams(method, params)
 }
 
+// GetNextRequestId reserves the id of a request sent later with SendRequestWithIdMethodAndParams
+func (s *ProxyMcpClient) GetNextRequestId() *jsonrpc.JsonRpcRequestId {
+	return s.transport.GetNextRequestId()
+}
+
+func (s *ProxyMcpClient) SendRequestWithIdMethodAndParams(reqId *jsonrpc.JsonRpcRequestId, method string, params interface{}) error {
+	return s.transport.SendRequestWithIdMethodAndParams(reqId, method, params)
+}
+
 func (s *ProxyMcpClient) SendError(code int, message string, id *jsonrpc.JsonRpcRequestId) {
 	s.logger.Debug("JsonRpcError", types.LogArg{
 		"code":    code,
@@ -1,6 +1,7 @@
 package proxymuxclient
 
 import (
+	"github.com/hamstah/gomcp/jsonrpc"
 	"github.com/hamstah/gomcp/protocol/mux"
 	"github.com/hamstah/gomcp/transport"
 	"github.com/hamstah/gomcp/types"
@@ -22,6 +23,12 @@ func (c *ProxyMuxClient) handleIncomingMessage(message transport.JsonRpcMessage)
 
 				c.events.EventMuxResponseProxyRegistered(response)
 			}
+		case mux.RpcRequestMethodPromptsRegister:
+			if response.Error != nil {
+				c.logger.Error("the hub refused the prompts", types.LogArg{
+					"error": response.Error.Message,
+				})
+			}
 		default:
 			c.logger.Error("received message with unexpected method", types.LogArg{
 				"method":   message.Method,
@@ -43,6 +50,46 @@ func (c *ProxyMuxClient) handleIncomingMessage(message transport.JsonRpcMessage)
 				}
 				c.events.EventMuxRequestToolCall(params, request.Id)
 			}
+		case mux.RpcRequestMethodPromptsGet:
+			{
+				params, err := mux.ParseJsonRpcRequestPromptsGetParams(request)
+				if err != nil {
+					c.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				c.events.EventMuxRequestPromptsGet(params, request.Id)
+			}
+		case mux.RpcRequestMethodCompletionComplete:
+			{
+				params, err := mux.ParseJsonRpcRequestCompletionCompleteParams(request)
+				if err != nil {
+					c.SendError(jsonrpc.RpcInvalidParams, err.Error(), request.Id)
+					return nil
+				}
+				c.events.EventMuxRequestCompletionComplete(params, request.Id)
+			}
+		case mux.RpcNotificationMethodRootsUpdated:
+			{
+				params, err := mux.ParseJsonRpcNotificationRootsUpdatedParams(request)
+				if err != nil {
+					c.logger.Error("error in handleRootsUpdated", types.LogArg{
+						"error": err,
+					})
+					return err
+				}
+				c.events.EventMuxNotificationRootsUpdated(params)
+			}
+		case mux.RpcNotificationMethodCancelled:
+			{
+				params, err := mux.ParseJsonRpcNotificationCancelledParams(request)
+				if err != nil {
+					c.logger.Error("error in handleCancelled", types.LogArg{
+						"error": err,
+					})
+					return err
+				}
+				c.events.EventMuxNotificationCancelled(params)
+			}
 		default:
 			c.logger.Error("received message with unexpected method", types.LogArg{
 				"method":  message.Method,
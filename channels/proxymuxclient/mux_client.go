@@ -2,6 +2,7 @@ package proxymuxclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"time"
@@ -18,15 +19,20 @@ type ProxyMuxClient struct {
 	logger     types.Logger
 	events     events.Events
 	muxAddress string
+	tlsConfig  *tls.Config
 }
 
+// NewProxyMuxClient creates the client of the mux server of the hub,
+// the link uses TLS if tlsConfig is not nil
 func NewProxyMuxClient(
 	muxAddress string,
+	tlsConfig *tls.Config,
 	events events.Events,
 	logger types.Logger,
 ) *ProxyMuxClient {
 	return &ProxyMuxClient{
 		muxAddress: muxAddress,
+		tlsConfig:  tlsConfig,
 		transport:  nil,
 		logger:     logger,
 		events:     events,
@@ -46,7 +52,10 @@ func (c *ProxyMuxClient) Start(ctx context.Context) error {
 
 	// start the mux client
 	// create a transport for the mux client
-	muxClientSocket := socket.NewSocketClient(c.muxAddress)
+	muxClientSocket, err := socket.NewClient(c.muxAddress, c.tlsConfig)
+	if err != nil {
+		return err
+	}
 
 	// we try to start the mux client socket
 	// let's get a transport for the mux client
@@ -123,6 +132,16 @@ func (s *ProxyMuxClient) SendRequestWithMethodAndParams(method string, params in
 	return s.transport.SendRequestWithMethodAndParams(method, params)
 }
 
+func (s *ProxyMuxClient) SendNotificationWithParams(method string, params interface{}) {
+	err := s.transport.SendNotificationWithParams(method, params)
+	if err != nil {
+		s.logger.Error("failed to send notification", types.LogArg{
+			"method": method,
+			"error":  err,
+		})
+	}
+}
+
 func (s *ProxyMuxClient) SendError(code int, message string, id *jsonrpc.JsonRpcRequestId) {
 	s.logger.Debug("JsonRpcError", types.LogArg{
 		"code":    code,
@@ -151,7 +170,12 @@ func isMuxServerReady(ctx context.Context, muxAddress string, logger types.Logge
 			// Continue with the connection attempt
 		}
 		logger.Info("waiting for mux server to be ready", types.LogArg{"attempts": i})
-		conn, err := net.DialTimeout("tcp", muxAddress, 5*time.Second)
+		network, address, err := socket.NetworkAddress(muxAddress)
+		if err != nil {
+			logger.Error("invalid mux server address", types.LogArg{"error": err})
+			return false
+		}
+		conn, err := net.DialTimeout(network, address, 5*time.Second)
 
 		if conn != nil {
 			conn.Close()
@@ -0,0 +1,402 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// Options of the connection to the MCP server
+type Options struct {
+	// name and version sent to the server, defaults to gomcp-client
+	ClientName    string
+	ClientVersion string
+	// optional, nothing is logged if not set
+	Logger types.Logger
+}
+
+// RpcError is the error returned when the server answers a request with an error
+type RpcError struct {
+	Code    int
+	Message string
+	Data    *json.RawMessage
+}
+
+func (e *RpcError) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("rpc error %d: %s (%s)", e.Code, e.Message, string(*e.Data))
+	}
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Client is a connection to an MCP server
+type Client struct {
+	transport  *transport.JsonRpcTransport
+	logger     types.Logger
+	cancel     context.CancelFunc
+	done       chan struct{}
+	initResult *mcp.JsonRpcResponseInitializeResult
+	// closed once the session is initialized, replaced when the
+	// transport opens a new session
+	ready chan struct{}
+
+	pendingResponses map[string]chan *jsonrpc.JsonRpcResponse
+	// callbacks for the notifications, by method
+	notificationHandlers map[string]func(params *jsonrpc.JsonRpcParams)
+	// callbacks for the progress of the requests, by progress token
+	progressHandlers  map[string]func(progress *mcp.JsonRpcNotificationProgressParams)
+	lastProgressToken int
+	mutex             sync.Mutex
+}
+
+// Connect starts the transport and initializes the session with the MCP server.
+// ctx controls the lifetime of the connection, the client is closed when it is cancelled.
+func Connect(ctx context.Context, tran types.Transport, options Options) (*Client, error) {
+	logger := options.Logger
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c := &Client{
+		transport:            transport.NewJsonRpcTransport(tran, "client", logger),
+		logger:               logger,
+		cancel:               cancel,
+		done:                 make(chan struct{}),
+		ready:                make(chan struct{}),
+		pendingResponses:     map[string]chan *jsonrpc.JsonRpcResponse{},
+		notificationHandlers: map[string]func(params *jsonrpc.JsonRpcParams){},
+		progressHandlers:     map[string]func(progress *mcp.JsonRpcNotificationProgressParams){},
+	}
+
+	// the SSE transport reports the new sessions opened after a reconnection
+	started := make(chan struct{})
+	c.transport.OnStarted(func() {
+		select {
+		case <-started:
+			c.restartSession(ctx, options)
+		default:
+			close(started)
+		}
+	})
+
+	errChan := make(chan error, 1)
+	go func() {
+		err := c.transport.Start(ctx, func(message transport.JsonRpcMessage, jsonRpcTransport *transport.JsonRpcTransport) {
+			c.handleIncomingMessage(message)
+		})
+		if err != nil && ctx.Err() == nil {
+			c.logger.Error("transport stopped", types.LogArg{
+				"error": err,
+			})
+		}
+		// the requests still waiting will never be answered
+		close(c.done)
+		errChan <- err
+	}()
+
+	select {
+	case <-started:
+	case err := <-errChan:
+		cancel()
+		if err == nil {
+			err = fmt.Errorf("transport closed before being started")
+		}
+		return nil, fmt.Errorf("failed to start transport: %w", err)
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+
+	if err := c.initialize(ctx, options); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// initialize initializes the session, the other requests wait until it is done
+func (c *Client) initialize(ctx context.Context, options Options) error {
+	c.mutex.Lock()
+	ready := c.ready
+	c.mutex.Unlock()
+	defer close(ready)
+
+	clientInfo := mcp.ClientInfo{
+		Name:    options.ClientName,
+		Version: options.ClientVersion,
+	}
+	if clientInfo.Name == "" {
+		clientInfo.Name = "gomcp-client"
+	}
+
+	response, err := c.send(ctx, mcp.RpcRequestMethodInitialize, &mcp.JsonRpcRequestInitializeParams{
+		ProtocolVersion: mcp.ProtocolVersion,
+		ClientInfo:      clientInfo,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	result, err := mcp.ParseJsonRpcResponseInitialize(response)
+	if err != nil {
+		return fmt.Errorf("invalid initialize response: %w", err)
+	}
+	if !mcp.IsSupportedProtocolVersion(result.ProtocolVersion) {
+		return fmt.Errorf("unsupported protocol version %s", result.ProtocolVersion)
+	}
+	c.mutex.Lock()
+	c.initResult = result
+	c.mutex.Unlock()
+
+	return c.transport.SendRequest(&jsonrpc.JsonRpcRequest{
+		JsonRpcVersion: jsonrpc.JsonRpcVersion,
+		Method:         mcp.RpcNotificationMethodInitialized,
+	})
+}
+
+// restartSession initializes the new session opened by the transport after a reconnection,
+// the requests wait until it is initialized and the ones sent to the previous session fail
+func (c *Client) restartSession(ctx context.Context, options Options) {
+	c.logger.Info("the server opened a new session, initializing it", types.LogArg{})
+	c.mutex.Lock()
+	c.ready = make(chan struct{})
+	pendingResponses := c.pendingResponses
+	c.pendingResponses = map[string]chan *jsonrpc.JsonRpcResponse{}
+	c.mutex.Unlock()
+
+	for _, responseChan := range pendingResponses {
+		responseChan <- &jsonrpc.JsonRpcResponse{
+			Error: &jsonrpc.JsonRpcError{
+				Code:    jsonrpc.RpcInternalError,
+				Message: "the session was lost, the request must be sent again",
+			},
+		}
+	}
+
+	// called from the reading loop, the responses are read while initializing
+	go func() {
+		if err := c.initialize(ctx, options); err != nil {
+			c.logger.Error("failed to initialize the new session", types.LogArg{
+				"error": err,
+			})
+		}
+	}()
+}
+
+// Close stops the transport, the pending requests fail
+func (c *Client) Close() {
+	c.cancel()
+	c.transport.Close()
+}
+
+// Done is closed when the connection is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// ProtocolVersion returns the protocol version negotiated with the server
+func (c *Client) ProtocolVersion() string {
+	return c.getInitResult().ProtocolVersion
+}
+
+// ServerInfo returns the name and version of the server
+func (c *Client) ServerInfo() mcp.ServerInfo {
+	return c.getInitResult().ServerInfo
+}
+
+// ServerCapabilities returns the capabilities declared by the server
+func (c *Client) ServerCapabilities() mcp.ServerCapabilities {
+	return c.getInitResult().Capabilities
+}
+
+// getInitResult returns the result of the initialization of the current session
+func (c *Client) getInitResult() *mcp.JsonRpcResponseInitializeResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.initResult
+}
+
+// OnNotification sets the callback called when the server sends a notification
+// with that method, e.g. mcp.RpcNotificationMethodToolsListChanged.
+// The callback is called from the reading loop, it must not block.
+func (c *Client) OnNotification(method string, callback func(params *jsonrpc.JsonRpcParams)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.notificationHandlers[method] = callback
+}
+
+// OnLogMessage sets the callback called when the server sends a log message
+func (c *Client) OnLogMessage(callback func(message *mcp.JsonRpcNotificationMessageParams)) {
+	c.OnNotification(mcp.RpcNotificationMethodMessage, func(params *jsonrpc.JsonRpcParams) {
+		message, err := mcp.ParseJsonRpcNotificationMessageParams(params)
+		if err != nil {
+			c.logger.Error("invalid log message", types.LogArg{
+				"error": err,
+			})
+			return
+		}
+		callback(message)
+	})
+}
+
+// OnResourceUpdated sets the callback called when a subscribed resource is updated
+func (c *Client) OnResourceUpdated(callback func(uri string)) {
+	c.OnNotification(mcp.RpcNotificationMethodResourcesUpdated, func(params *jsonrpc.JsonRpcParams) {
+		updated, err := mcp.ParseJsonRpcNotificationResourcesUpdatedParams(params)
+		if err != nil {
+			c.logger.Error("invalid resource updated notification", types.LogArg{
+				"error": err,
+			})
+			return
+		}
+		callback(updated.Uri)
+	})
+}
+
+func (c *Client) handleIncomingMessage(message transport.JsonRpcMessage) {
+	if message.Response != nil {
+		if !c.deliverResponse(message.Response) {
+			c.logger.Error("received a response nobody is waiting for", types.LogArg{
+				"method": message.Method,
+				"id":     jsonrpc.RequestIdToString(message.Response.Id),
+			})
+		}
+		return
+	}
+	if message.Request == nil {
+		return
+	}
+
+	request := message.Request
+	if request.Id == nil {
+		c.handleNotification(request)
+		return
+	}
+	switch request.Method {
+	case "ping":
+		c.transport.SendResponseWithResults(request.Id, json.RawMessage(`{}`))
+	default:
+		// sampling, roots and elicitation are not supported by the client
+		c.transport.SendError(jsonrpc.RpcMethodNotFound, fmt.Sprintf("method %s not supported", request.Method), request.Id)
+	}
+}
+
+func (c *Client) handleNotification(notification *jsonrpc.JsonRpcRequest) {
+	if notification.Method == mcp.RpcNotificationMethodProgress {
+		progress, err := mcp.ParseJsonRpcNotificationProgressParams(notification.Params)
+		if err != nil {
+			c.logger.Error("invalid progress notification", types.LogArg{
+				"error": err,
+			})
+			return
+		}
+		c.mutex.Lock()
+		progressHandler := c.progressHandlers[fmt.Sprint(progress.ProgressToken)]
+		c.mutex.Unlock()
+		if progressHandler != nil {
+			progressHandler(progress)
+			return
+		}
+	}
+
+	c.mutex.Lock()
+	handler := c.notificationHandlers[notification.Method]
+	c.mutex.Unlock()
+	if handler == nil {
+		c.logger.Debug("ignoring notification", types.LogArg{
+			"method": notification.Method,
+		})
+		return
+	}
+	handler(notification.Params)
+}
+
+// request sends a request to the server once the session is initialized and
+// blocks until the response is received or the context is cancelled.
+// An error response is returned as a *RpcError.
+func (c *Client) request(ctx context.Context, method string, params interface{}) (*jsonrpc.JsonRpcResponse, error) {
+	c.mutex.Lock()
+	ready := c.ready
+	c.mutex.Unlock()
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, fmt.Errorf("connection closed")
+	}
+	return c.send(ctx, method, params)
+}
+
+// send sends a request to the server and blocks until the response is received
+// or the context is cancelled, without waiting for the initialization
+func (c *Client) send(ctx context.Context, method string, params interface{}) (*jsonrpc.JsonRpcResponse, error) {
+	responseChan := make(chan *jsonrpc.JsonRpcResponse, 1)
+
+	// we wait for the response before sending the request, it can't be
+	// received before, and the other requests are not blocked while sending
+	reqId := c.transport.GetNextRequestId()
+	key := jsonrpc.RequestIdToString(reqId)
+	c.mutex.Lock()
+	c.pendingResponses[key] = responseChan
+	c.mutex.Unlock()
+	if err := c.transport.SendRequestWithIdMethodAndParams(reqId, method, params); err != nil {
+		c.removePendingResponse(key)
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		if response.Error != nil {
+			return nil, &RpcError{
+				Code:    response.Error.Code,
+				Message: response.Error.Message,
+				Data:    response.Error.Data,
+			}
+		}
+		return response, nil
+	case <-ctx.Done():
+		c.removePendingResponse(key)
+		// we tell the server that we don't need the response anymore
+		c.transport.SendNotificationWithParams(mcp.RpcNotificationMethodCancelled, &mcp.JsonRpcNotificationCancelledParams{
+			RequestId: jsonrpc.RequestIdToValue(reqId),
+		})
+		return nil, ctx.Err()
+	case <-c.done:
+		c.removePendingResponse(key)
+		return nil, fmt.Errorf("connection closed")
+	}
+}
+
+func (c *Client) removePendingResponse(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.pendingResponses, key)
+}
+
+// deliverResponse passes the response to the goroutine waiting for it,
+// it returns false if nobody is waiting for that response
+func (c *Client) deliverResponse(response *jsonrpc.JsonRpcResponse) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := jsonrpc.RequestIdToString(response.Id)
+	responseChan, ok := c.pendingResponses[key]
+	if !ok {
+		return false
+	}
+	delete(c.pendingResponses, key)
+	responseChan <- response
+	return true
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
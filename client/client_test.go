@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+// fakeServer is a transport answering the requests like an MCP server
+type fakeServer struct {
+	onMessage func(json.RawMessage)
+	onStarted func()
+	// messages sent to the client, in order
+	outgoing chan json.RawMessage
+	closed   chan struct{}
+	// the sending of a "block" request waits for release
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{
+		outgoing: make(chan json.RawMessage, 10),
+		closed:   make(chan struct{}),
+		blocked:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+}
+
+func (f *fakeServer) Start(ctx context.Context) error {
+	f.onStarted()
+	for {
+		select {
+		case message := <-f.outgoing:
+			f.onMessage(message)
+		case <-ctx.Done():
+			return nil
+		case <-f.closed:
+			return nil
+		}
+	}
+}
+
+func (f *fakeServer) Send(message json.RawMessage) error {
+	var request struct {
+		Id     interface{}            `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return err
+	}
+	if request.Id == nil {
+		return nil
+	}
+
+	var result interface{}
+	var rpcError map[string]interface{}
+	switch request.Method {
+	case mcp.RpcRequestMethodInitialize:
+		result = map[string]interface{}{
+			"protocolVersion": mcp.ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+		}
+	case mcp.RpcRequestMethodToolsList:
+		// two pages of one tool
+		name, nextCursor := "first", interface{}("page2")
+		if request.Params["cursor"] == "page2" {
+			name, nextCursor = "second", nil
+		}
+		result = map[string]interface{}{
+			"tools": []interface{}{
+				map[string]interface{}{"name": name, "description": name, "inputSchema": map[string]interface{}{"type": "object"}},
+			},
+			"nextCursor": nextCursor,
+		}
+	case mcp.RpcRequestMethodToolsCall:
+		if request.Params["name"] != "echo" {
+			rpcError = map[string]interface{}{"code": jsonrpc.RpcInvalidParams, "message": "unknown tool"}
+			break
+		}
+		// the progress is sent before the result
+		if meta, ok := request.Params["_meta"].(map[string]interface{}); ok {
+			f.receive(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"method":  mcp.RpcNotificationMethodProgress,
+				"params":  map[string]interface{}{"progressToken": meta["progressToken"], "progress": 1},
+			})
+		}
+		arguments := request.Params["arguments"].(map[string]interface{})
+		result = map[string]interface{}{
+			"content": []interface{}{map[string]interface{}{"type": "text", "text": arguments["text"]}},
+		}
+	case mcp.RpcRequestMethodPromptsGet:
+		result = map[string]interface{}{
+			"messages": []interface{}{
+				map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": "hello"}},
+			},
+		}
+	case "block":
+		close(f.blocked)
+		<-f.release
+		result = map[string]interface{}{}
+	case "hang":
+		// never answered
+		return nil
+	default:
+		rpcError = map[string]interface{}{"code": jsonrpc.RpcMethodNotFound, "message": "method not found"}
+	}
+
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.Id}
+	if rpcError != nil {
+		response["error"] = rpcError
+	} else {
+		response["result"] = result
+	}
+	f.receive(response)
+	return nil
+}
+
+func (f *fakeServer) receive(message interface{}) {
+	raw, _ := json.Marshal(message)
+	f.outgoing <- raw
+}
+
+func (f *fakeServer) OnMessage(callback func(json.RawMessage)) { f.onMessage = callback }
+func (f *fakeServer) Close() {
+	select {
+	case <-f.closed:
+	default:
+		close(f.closed)
+	}
+}
+func (f *fakeServer) OnStarted(callback func())    { f.onStarted = callback }
+func (f *fakeServer) OnClose(callback func())      {}
+func (f *fakeServer) OnError(callback func(error)) {}
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := Connect(ctx, newFakeServer(), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if c.ServerInfo().Name != "fake" || c.ProtocolVersion() != mcp.ProtocolVersion {
+		t.Errorf("unexpected initialize result %+v, %s", c.ServerInfo(), c.ProtocolVersion())
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tools) != 2 || tools[1].Name != "second" {
+		t.Errorf("expected the tools of both pages, got %+v", tools)
+	}
+
+	var progress []float64
+	result, err := c.CallToolWithProgress(ctx, "echo", map[string]interface{}{"text": "hi"}, func(p *mcp.JsonRpcNotificationProgressParams) {
+		progress = append(progress, p.Progress)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content[0].(map[string]interface{})["text"] != "hi" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(progress) != 1 {
+		t.Errorf("expected 1 progress notification, got %v", progress)
+	}
+
+	var rpcErr *RpcError
+	if _, err := c.CallTool(ctx, "unknown", nil); !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc.RpcInvalidParams {
+		t.Errorf("expected an invalid params error, got %v", err)
+	}
+
+	prompt, err := c.GetPrompt(ctx, "greet", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prompt.Messages) != 1 || prompt.Messages[0].Role != "user" {
+		t.Errorf("unexpected prompt %+v", prompt)
+	}
+
+	// a cancelled request returns the context error
+	requestCtx, requestCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer requestCancel()
+	if _, err := c.request(requestCtx, "hang", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+}
+
+func TestRequestsNotBlockedBySending(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server := newFakeServer()
+	c, err := Connect(ctx, server, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	blockedErr := make(chan error, 1)
+	go func() {
+		_, err := c.request(ctx, "block", nil)
+		blockedErr <- err
+	}()
+	<-server.blocked
+
+	// the other requests are sent while the first one is still being sent
+	if _, err := c.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	close(server.release)
+	if err := <-blockedErr; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
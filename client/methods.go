@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+// ListTools returns all the tools of the server, following the pages
+func (c *Client) ListTools(ctx context.Context) ([]mcp.ToolDescription, error) {
+	tools := []mcp.ToolDescription{}
+	var cursor *string
+	for {
+		response, err := c.request(ctx, mcp.RpcRequestMethodToolsList, &mcp.JsonRpcRequestToolsListParams{
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result, err := mcp.ParseJsonRpcResponseToolsList(response)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tools list response: %w", err)
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == nil || *result.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool calls a tool of the server, a tool failure is reported
+// in the result (IsError) and not as an error
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.JsonRpcResponseToolsCallResult, error) {
+	return c.callTool(ctx, name, arguments, nil)
+}
+
+// CallToolWithProgress calls a tool of the server and asks for progress notifications,
+// onProgress is called from the reading loop for each of them until the call returns
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, arguments map[string]interface{}, onProgress func(progress *mcp.JsonRpcNotificationProgressParams)) (*mcp.JsonRpcResponseToolsCallResult, error) {
+	c.mutex.Lock()
+	c.lastProgressToken++
+	progressToken := fmt.Sprintf("progress-%d", c.lastProgressToken)
+	c.progressHandlers[progressToken] = onProgress
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.progressHandlers, progressToken)
+		c.mutex.Unlock()
+	}()
+
+	return c.callTool(ctx, name, arguments, &mcp.RequestMeta{
+		ProgressToken: progressToken,
+	})
+}
+
+func (c *Client) callTool(ctx context.Context, name string, arguments map[string]interface{}, meta *mcp.RequestMeta) (*mcp.JsonRpcResponseToolsCallResult, error) {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	response, err := c.request(ctx, mcp.RpcRequestMethodToolsCall, &mcp.JsonRpcRequestToolsCallParams{
+		Name:      name,
+		Arguments: arguments,
+		Meta:      meta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseJsonRpcResponseToolsCall(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tools call response: %w", err)
+	}
+	return result, nil
+}
+
+// ListPrompts returns all the prompts of the server, following the pages
+func (c *Client) ListPrompts(ctx context.Context) ([]mcp.PromptDescription, error) {
+	prompts := []mcp.PromptDescription{}
+	var cursor *string
+	for {
+		response, err := c.request(ctx, mcp.RpcRequestMethodPromptsList, &mcp.JsonRpcRequestPromptsListParams{
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result, err := mcp.ParseJsonRpcResponsePromptsList(response)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompts list response: %w", err)
+		}
+		prompts = append(prompts, result.Prompts...)
+		if result.NextCursor == nil || *result.NextCursor == "" {
+			return prompts, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// GetPrompt returns the messages of a prompt of the server
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*mcp.JsonRpcResponsePromptsGetResult, error) {
+	promptArguments := map[string]interface{}{}
+	for key, value := range arguments {
+		promptArguments[key] = value
+	}
+	response, err := c.request(ctx, mcp.RpcRequestMethodPromptsGet, &mcp.JsonRpcRequestPromptsGetParams{
+		Name:      name,
+		Arguments: promptArguments,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseJsonRpcResponsePromptsGet(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompts get response: %w", err)
+	}
+	return result, nil
+}
+
+// ListResources returns all the resources of the server, following the pages
+func (c *Client) ListResources(ctx context.Context) ([]mcp.ResourceDescription, error) {
+	resources := []mcp.ResourceDescription{}
+	var cursor *string
+	for {
+		response, err := c.request(ctx, mcp.RpcRequestMethodResourcesList, &mcp.JsonRpcRequestResourcesListParams{
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result, err := mcp.ParseJsonRpcResponseResourcesList(response)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resources list response: %w", err)
+		}
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == nil || *result.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ReadResource returns the contents of a resource of the server
+func (c *Client) ReadResource(ctx context.Context, uri string) (*mcp.JsonRpcResponseResourcesReadResult, error) {
+	response, err := c.request(ctx, mcp.RpcRequestMethodResourcesRead, &mcp.JsonRpcRequestResourcesReadParams{
+		Uri: uri,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseJsonRpcResponseResourcesRead(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resources read response: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// NewSseTransport returns a transport connecting to an MCP server using the
+// HTTP+SSE transport of the 2024-11-05 revision, sseUrl is the URL of its stream
+func NewSseTransport(sseUrl string) types.Transport {
+	return transport.NewSseClientTransport(sseUrl, nopLogger{})
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// startSseServer serves MCP sessions answering tools/call only once they are initialized,
+// the initialize requests are reported on the channel
+func startSseServer(t *testing.T, ctx context.Context) (*httptest.Server, chan struct{}) {
+	initialized := make(chan struct{}, 2)
+	sseTransport := transport.NewSseServerTransport(types.SseOptions{}, nopLogger{})
+	sseTransport.OnSession(func(session types.Transport) {
+		var isInitialized atomic.Bool
+		jsonRpcTransport := transport.NewJsonRpcTransport(session, "server", nopLogger{})
+		go jsonRpcTransport.Start(ctx, func(message transport.JsonRpcMessage, jsonRpcTransport *transport.JsonRpcTransport) {
+			request := message.Request
+			if request == nil {
+				return
+			}
+			switch request.Method {
+			case mcp.RpcRequestMethodInitialize:
+				jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{
+					"protocolVersion": mcp.ProtocolVersion,
+					"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+					"serverInfo":      map[string]interface{}{"name": "sse", "version": "1.0"},
+				})
+				initialized <- struct{}{}
+			case mcp.RpcNotificationMethodInitialized:
+				isInitialized.Store(true)
+			case mcp.RpcRequestMethodToolsCall:
+				if !isInitialized.Load() {
+					jsonRpcTransport.SendError(jsonrpc.RpcInvalidRequest, "session not initialized", request.Id)
+					return
+				}
+				jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{
+					"content": []interface{}{map[string]interface{}{"type": "text", "text": "done"}},
+				})
+			}
+		})
+	})
+	go sseTransport.Start(ctx)
+	server := httptest.NewServer(sseTransport)
+	t.Cleanup(server.Close)
+	return server, initialized
+}
+
+func TestSseReconnection(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server, initialized := startSseServer(t, ctx)
+
+	c, err := Connect(ctx, NewSseTransport(server.URL+"/sse"), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+	<-initialized
+	if _, err := c.CallTool(ctx, "echo", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the stream is dropped, the client initializes the new session of the server
+	server.CloseClientConnections()
+	select {
+	case <-initialized:
+	case <-ctx.Done():
+		t.Fatalf("the new session was not initialized")
+	}
+
+	// the request waits for the end of the initialization
+	result, err := c.CallTool(ctx, "echo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error after the reconnection: %v", err)
+	}
+	if len(result.Content) != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// NewStdioTransport returns a transport that starts the MCP server program
+// and talks to it over its standard input and output
+func NewStdioTransport(programName string, programArgs ...string) types.Transport {
+	return transport.NewStdioProxyClientTransport(&transport.ProxiedMcpServerDescription{
+		ProxyName:   programName,
+		ProgramName: programName,
+		ProgramArgs: programArgs,
+	})
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// NewWebSocketTransport returns a transport connecting to an MCP server
+// using the WebSocket transport, wsUrl is its endpoint (eg ws://localhost:8080/ws)
+func NewWebSocketTransport(wsUrl string) types.Transport {
+	return transport.NewWebSocketClientTransport(wsUrl, nil, nopLogger{})
+}
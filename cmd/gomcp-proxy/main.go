@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,6 +25,7 @@ const (
 var (
 	debug   bool
 	delete  bool
+	sseUrl  string
 	rootCmd = &cobra.Command{
 		Use:   "gomcp-proxy",
 		Short: "A proxy server for MCP connections",
@@ -56,14 +58,21 @@ var (
 			var invalidArgs bool
 			var programName string
 			var programArgs []string
-
-			if len(args) == 0 {
+			var serverUrl string
+
+			if sseUrl != "" {
+				// a remote MCP server, no program to start
+				serverUrl = sseUrl
+				programArgs = []string{}
+				invalidArgs = len(args) != 0
+			} else if len(args) == 0 {
 				// that's ok if we have a config file
-				if proxyConfig == nil {
+				if proxyConfig == nil || (proxyConfig.ProgramName == "" && proxyConfig.ServerUrl == "") {
 					invalidArgs = true
 				} else {
 					programName = proxyConfig.ProgramName
 					programArgs = proxyConfig.ProgramArgs
+					serverUrl = proxyConfig.ServerUrl
 					invalidArgs = false
 				}
 			} else {
@@ -91,7 +100,7 @@ var (
 			}
 
 			if invalidArgs {
-				logger.Error("Please provide a program name as the first argument, and optionally arguments", types.LogArg{"args": args})
+				logger.Error("Please provide a program name as the first argument, and optionally arguments, or the URL of an SSE server with --sse", types.LogArg{"args": args})
 				os.Exit(1)
 			}
 
@@ -114,10 +123,22 @@ var (
 				os.Exit(0)
 			}
 
+			// the registration is signed with the key of the hub
+			muxKey, err := config.LoadMuxKey()
+			if err != nil {
+				logger.Error("Failed to load the mux key of the hub",
+					types.LogArg{
+						"error":   err,
+						"keyPath": config.GetDefaultMuxKeyPath(),
+					})
+				os.Exit(1)
+			}
+
 			logger.Info("MCP Proxy is starting", types.LogArg{
 				"address":     hubConfig.Proxy.ListenAddress,
 				"programName": programName,
 				"programArgs": programArgs,
+				"serverUrl":   serverUrl,
 			})
 
 			if proxyConfig == nil {
@@ -128,6 +149,7 @@ var (
 			// update the proxy config with the current values
 			proxyConfig.ProgramName = programName
 			proxyConfig.ProgramArgs = programArgs
+			proxyConfig.ServerUrl = serverUrl
 			proxyConfig.WhatIsThat = DefaultProxyWhatIsThat
 			proxyConfig.MoreInformation = DefaultProxyMoreInfo
 
@@ -145,12 +167,25 @@ var (
 				os.Exit(1)
 			}
 
+			// with TLS, the proxy verifies the hub and presents the certificate issued for its proxy id
+			var muxTlsConfig *tls.Config = nil
+			if hubConfig.Proxy.Tls != nil {
+				muxTlsConfig, err = hubConfig.Proxy.Tls.ClientTlsConfig(proxyConfig.ProxyId)
+				if err != nil {
+					logger.Error("Failed to configure TLS on the mux link", types.LogArg{"error": err})
+					os.Exit(1)
+				}
+			}
+
 			proxyInformation := proxy.ProxyInformation{
 				ProxyId:                 proxyConfig.ProxyId,
 				MuxAddress:              hubConfig.Proxy.ListenAddress,
 				CurrentWorkingDirectory: currentWorkingDirectory,
 				ProgramName:             programName,
 				Args:                    programArgs,
+				ServerUrl:               serverUrl,
+				MuxKey:                  muxKey,
+				MuxTlsConfig:            muxTlsConfig,
 			}
 
 			client := proxy.NewProxyClient(proxyInformation, debug, logger)
@@ -162,6 +197,7 @@ var (
 func init() {
 	rootCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
 	rootCmd.Flags().BoolVarP(&delete, "delete", "x", false, "Delete the proxy setup")
+	rootCmd.Flags().StringVar(&sseUrl, "sse", "", "URL of a remote MCP server using the HTTP+SSE transport (eg http://localhost:8080/sse), instead of a program")
 }
 
 func loadEnvFile(filename string) error {
@@ -5,12 +5,16 @@ import (
 	"os"
 
 	"github.com/hamstah/gomcp/channels/hub"
+	"github.com/hamstah/gomcp/types"
 	"github.com/spf13/cobra"
 )
 
 var (
-	debug   bool
-	rootCmd = &cobra.Command{
+	debug         bool
+	listenAddress string
+	sseAddress    string
+	wsAddress     string
+	rootCmd       = &cobra.Command{
 		Use:   "gomcp",
 		Short: "A MCP multiplexer server that enables multiple MCP proxy client connections",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -21,8 +25,23 @@ var (
 				os.Exit(1)
 			}
 
-			// start the server
-			transport := mcp.StdioTransport()
+			// start the server, over HTTP if an address is given
+			var transport types.Transport
+			if listenAddress != "" {
+				transport = mcp.StreamableHttpTransport(types.StreamableHttpOptions{
+					HttpServerOptions: types.HttpServerOptions{ListenAddress: listenAddress},
+				})
+			} else if sseAddress != "" {
+				transport = mcp.SseTransport(types.SseOptions{
+					HttpServerOptions: types.HttpServerOptions{ListenAddress: sseAddress},
+				})
+			} else if wsAddress != "" {
+				transport = mcp.WebSocketTransport(types.WebSocketOptions{
+					HttpServerOptions: types.HttpServerOptions{ListenAddress: wsAddress},
+				})
+			} else {
+				transport = mcp.StdioTransport()
+			}
 			err = mcp.Start(transport)
 			if err != nil {
 				fmt.Println("Error starting MCP server:", err)
@@ -34,6 +53,9 @@ var (
 
 func init() {
 	rootCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
+	rootCmd.Flags().StringVar(&listenAddress, "http", "", "Serve the MCP clients with the Streamable HTTP transport on this address (eg localhost:8080)")
+	rootCmd.Flags().StringVar(&sseAddress, "sse", "", "Serve the MCP clients with the legacy HTTP+SSE transport on this address (eg localhost:8080)")
+	rootCmd.Flags().StringVar(&wsAddress, "ws", "", "Serve the MCP clients with the WebSocket transport on this address (eg localhost:8080)")
 }
 
 func main() {
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyCmd = &cobra.Command{
+		Use:   "proxy",
+		Short: "Approve or deny the proxies that the policy of the hub does not allow",
+	}
+	proxyListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the pending, approved and denied proxies",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			approvals := loadProxyApprovals()
+			if len(approvals.Proxies) == 0 {
+				fmt.Println("no pending, approved or denied proxy in", config.GetDefaultProxyApprovalsPath())
+				return
+			}
+			for _, approval := range approvals.Proxies {
+				fmt.Printf("%-8s %s  %s  %s  %s\n", approval.Status, approval.ProxyId, approval.Name, approval.WorkingDirectory, approval.Command)
+			}
+		},
+	}
+	proxyApproveCmd = &cobra.Command{
+		Use:   "approve <proxyId>",
+		Short: "Approve a proxy, the hub completes its registration if it is pending",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			decideProxy(args[0], config.ProxyApprovalApproved)
+		},
+	}
+	proxyDenyCmd = &cobra.Command{
+		Use:   "deny <proxyId>",
+		Short: "Deny a proxy, the hub closes its connection if it is pending",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			decideProxy(args[0], config.ProxyApprovalDenied)
+		},
+	}
+	proxyForgetCmd = &cobra.Command{
+		Use:   "forget <proxyId>",
+		Short: "Forget the decision about a proxy, it is pending again on its next registration",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			updateProxyApprovals(func(approvals *config.ProxyApprovals) (bool, error) {
+				if !approvals.Remove(args[0]) {
+					return false, fmt.Errorf("unknown proxy: %s", args[0])
+				}
+				return true, nil
+			})
+		},
+	}
+)
+
+// decideProxy changes the decision about a proxy known by the hub, the hub reads it within a second
+func decideProxy(proxyId string, status config.ProxyApprovalStatus) {
+	updateProxyApprovals(func(approvals *config.ProxyApprovals) (bool, error) {
+		approval := approvals.Get(proxyId)
+		if approval == nil {
+			return false, fmt.Errorf("unknown proxy (use proxy.policy.allowedProxyIds in the hub configuration to allow it in advance): %s", proxyId)
+		}
+		approval.Status = status
+		approvals.Set(*approval)
+		return true, nil
+	})
+	fmt.Printf("proxy %s %s\n", proxyId, status)
+}
+
+func loadProxyApprovals() *config.ProxyApprovals {
+	approvals, err := config.LoadProxyApprovals()
+	if err != nil {
+		fmt.Println("Error loading the proxy approvals:", err)
+		os.Exit(1)
+	}
+	return approvals
+}
+
+// updateProxyApprovals changes the approvals under the lock shared with the hub
+func updateProxyApprovals(update func(approvals *config.ProxyApprovals) (bool, error)) {
+	if err := config.UpdateProxyApprovals(update); err != nil {
+		fmt.Println("Error updating the proxy approvals:", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	proxyCmd.AddCommand(proxyListCmd, proxyApproveCmd, proxyDenyCmd, proxyForgetCmd)
+	rootCmd.AddCommand(proxyCmd)
+}
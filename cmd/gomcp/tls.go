@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/defaults"
+	"github.com/spf13/cobra"
+)
+
+const muxServerCertificateName = "hub"
+
+var (
+	tlsHosts  []string
+	tlsForce  bool
+	tlsMutual bool
+	tlsCmd    = &cobra.Command{
+		Use:   "tls",
+		Short: "Generate the certificates of the mux link between the hub and the proxies",
+	}
+	tlsInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Generate a self-signed CA and the certificate of the hub in the hub configuration directory",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			caFile, err := config.GenerateMuxCa(tlsForce)
+			if err != nil {
+				fmt.Println("Error generating the CA:", err)
+				os.Exit(1)
+			}
+			fingerprint, err := config.GenerateMuxServerCertificate(muxServerCertificateName, tlsHosts)
+			if err != nil {
+				fmt.Println("Error generating the certificate of the hub:", err)
+				os.Exit(1)
+			}
+
+			certFile, keyFile := config.MuxCertificatePaths(muxServerCertificateName)
+			fmt.Println("CA:", caFile)
+			fmt.Println("certificate of the hub:", certFile)
+			fmt.Println("fingerprint of the certificate of the hub:", fingerprint)
+
+			// the paths are relative to the hub configuration directory
+			tlsInfo := config.MuxTlsInfo{
+				CertFile: relativeTlsPath(certFile),
+				KeyFile:  relativeTlsPath(keyFile),
+				CaFile:   relativeTlsPath(caFile),
+			}
+			if tlsMutual {
+				tlsInfo.ClientCaFile = relativeTlsPath(caFile)
+			}
+			snippet, _ := json.MarshalIndent(map[string]interface{}{"tls": tlsInfo}, "", "  ")
+			fmt.Printf("\nadd to the proxy section of %s:\n%s\n", config.GetDefaultHubConfigurationPath(), snippet)
+			fmt.Println("\nthe proxies on other machines need ca.crt (or the fingerprint) and their client certificate with mutual TLS")
+		},
+	}
+	tlsClientCmd = &cobra.Command{
+		Use:   "client <proxyId>",
+		Short: "Generate the client certificate of a proxy for mutual TLS, signed by the CA",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			proxyId := args[0]
+			// the proxy id names the certificate files
+			if proxyId == "" || strings.ContainsAny(proxyId, `/\`) || strings.HasPrefix(proxyId, ".") {
+				fmt.Println("Invalid proxy id:", proxyId)
+				os.Exit(1)
+			}
+			certFile, err := config.GenerateMuxClientCertificate(proxyId)
+			if err != nil {
+				fmt.Println("Error generating the client certificate:", err)
+				os.Exit(1)
+			}
+			fmt.Println("client certificate of the proxy:", certFile)
+			fmt.Println("the proxy id is in the gomcp-proxy.json file of the directory of the proxy")
+		},
+	}
+)
+
+func relativeTlsPath(path string) string {
+	relative, err := filepath.Rel(defaults.DefaultHubConfigurationDirectory, path)
+	if err != nil {
+		return path
+	}
+	return relative
+}
+
+func init() {
+	tlsInitCmd.Flags().StringSliceVar(&tlsHosts, "host", []string{"localhost", "127.0.0.1"}, "Names and IP addresses the proxies connect to the hub with")
+	tlsInitCmd.Flags().BoolVar(&tlsForce, "force", false, "Replace the existing CA, the certificates it signed must be generated again")
+	tlsInitCmd.Flags().BoolVar(&tlsMutual, "mtls", false, "Require the client certificates of the proxies (mutual TLS)")
+	tlsCmd.AddCommand(tlsInitCmd, tlsClientCmd)
+	rootCmd.AddCommand(tlsCmd)
+}
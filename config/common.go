@@ -23,6 +23,11 @@ type InspectorInfo struct {
 	ListenAddress string `json:"listenAddress"`
 }
 
+type PaginationInfo struct {
+	// maximum number of items returned by the list methods, 0 to disable pagination
+	PageSize int `json:"pageSize"`
+}
+
 type PromptConfig struct {
 	File string `json:"file"`
 }
@@ -34,6 +39,13 @@ type ToolConfig struct {
 	Configuration interface{} `json:"configuration"`
 }
 
+type ResourceConfig struct {
+	Name          string      `json:"name"`
+	IsDisabled    bool        `json:"isDisabled,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Configuration interface{} `json:"configuration"`
+}
+
 func updateFilePath(path string) string {
 	if path == "" {
 		return path
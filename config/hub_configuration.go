@@ -18,11 +18,16 @@ type HubConfiguration struct {
 	Inspector     *InspectorInfo     `json:"inspector,omitempty"`
 	Prompts       *PromptConfig      `json:"prompts,omitempty"`
 	Proxy         *ServerProxyConfig `json:"proxy,omitempty"`
+	Pagination    *PaginationInfo    `json:"pagination,omitempty"`
 }
 
 type ServerProxyConfig struct {
 	Enabled       bool   `json:"enabled"`
 	ListenAddress string `json:"listenAddress"`
+	// TLS of the mux link, used by the hub and the proxies when set
+	Tls *MuxTlsInfo `json:"tls,omitempty"`
+	// proxies accepted without the approval of an operator, all of them when not set
+	Policy *MuxPolicyInfo `json:"policy,omitempty"`
 }
 
 var defaultHubConfigurationPath = filepath.Join(defaults.DefaultHubConfigurationDirectory, "hub.json")
@@ -66,6 +71,9 @@ func LoadHubConfiguration() (*HubConfiguration, error) {
 	if config.Logging != nil {
 		config.Logging.UpdateFilePaths()
 	}
+	if config.Proxy != nil && config.Proxy.Tls != nil {
+		config.Proxy.Tls.UpdateFilePaths()
+	}
 
 	return &config, nil
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	muxCaName                  = "ca"
+	muxCaValidity              = 10 * 365 * 24 * time.Hour
+	muxCertificateValidity     = 2 * 365 * 24 * time.Hour
+	muxCertificateCommonNameCa = "gomcp mux CA"
+)
+
+// MuxCertificatePaths returns the paths of the certificate and key of a name in the TLS directory
+func MuxCertificatePaths(name string) (string, string) {
+	return filepath.Join(GetDefaultTlsDirectory(), name+".crt"), filepath.Join(GetDefaultTlsDirectory(), name+".key")
+}
+
+// GenerateMuxCa generates the self-signed CA of the mux link in the TLS directory,
+// an existing CA is only replaced with force
+func GenerateMuxCa(force bool) (string, error) {
+	certFile, _ := MuxCertificatePaths(muxCaName)
+	if _, err := os.Stat(certFile); err == nil && !force {
+		return "", fmt.Errorf("the CA already exists in %s", certFile)
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: muxCertificateCommonNameCa},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	if _, err := generateCertificate(muxCaName, template, muxCaValidity, nil); err != nil {
+		return "", err
+	}
+	return certFile, nil
+}
+
+// GenerateMuxServerCertificate generates the certificate of the hub signed by the CA,
+// hosts are the names and IP addresses the proxies connect to. It returns its fingerprint.
+func GenerateMuxServerCertificate(name string, hosts []string) (string, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	ca, err := loadMuxCa()
+	if err != nil {
+		return "", err
+	}
+	der, err := generateCertificate(name, template, muxCertificateValidity, ca)
+	if err != nil {
+		return "", err
+	}
+	return CertificateFingerprint(der), nil
+}
+
+// GenerateMuxClientCertificate generates the certificate of a proxy signed by the CA,
+// its common name is the proxy id, the hub only accepts it for that proxy
+func GenerateMuxClientCertificate(proxyId string) (string, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: proxyId},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	ca, err := loadMuxCa()
+	if err != nil {
+		return "", err
+	}
+	if _, err := generateCertificate(proxyId, template, muxCertificateValidity, ca); err != nil {
+		return "", err
+	}
+	certFile, _ := MuxCertificatePaths(proxyId)
+	return certFile, nil
+}
+
+func loadMuxCa() (*tls.Certificate, error) {
+	certFile, keyFile := MuxCertificatePaths(muxCaName)
+	ca, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the CA (generate it first): %w", err)
+	}
+	ca.Leaf, err = x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &ca, nil
+}
+
+// generateCertificate generates a key and its certificate signed by the CA, self-signed without CA,
+// they are written in the TLS directory. It returns the DER encoded certificate.
+func generateCertificate(name string, template *x509.Certificate, validity time.Duration, ca *tls.Certificate) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serialNumber
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(validity)
+
+	parent, signer := template, any(key)
+	if ca != nil {
+		parent, signer = ca.Leaf, ca.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	certFile, keyFile := MuxCertificatePaths(name)
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, err
+	}
+	return der, nil
+}
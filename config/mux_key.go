@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hamstah/gomcp/defaults"
+)
+
+// size of the key shared by the hub and the proxies
+const muxKeySize = 32
+
+var defaultMuxKeyPath = filepath.Join(defaults.DefaultHubConfigurationDirectory, defaults.DefaultMuxKeyFile)
+
+func GetDefaultMuxKeyPath() string {
+	return defaultMuxKeyPath
+}
+
+// LoadMuxKey loads the key the proxies authenticate with, it must only be readable by its owner
+func LoadMuxKey() ([]byte, error) {
+	info, err := os.Stat(defaultMuxKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mux key (start the hub to create it): %w", err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("the permissions of the mux key %s are too open, it must only be readable by its owner (chmod 600)", defaultMuxKeyPath)
+	}
+
+	content, err := os.ReadFile(defaultMuxKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) < muxKeySize {
+		return nil, fmt.Errorf("invalid mux key in %s", defaultMuxKeyPath)
+	}
+	return key, nil
+}
+
+// LoadOrCreateMuxKey loads the key the proxies authenticate with, it is generated on the first start of the hub
+func LoadOrCreateMuxKey() ([]byte, error) {
+	if _, err := os.Stat(defaultMuxKeyPath); err == nil {
+		return LoadMuxKey()
+	}
+
+	key := make([]byte, muxKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(defaultMuxKeyPath), 0700); err != nil {
+		return nil, err
+	}
+	// O_EXCL: a hub started at the same time may have created it
+	file, err := os.OpenFile(defaultMuxKeyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return LoadMuxKey()
+		}
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MuxPolicyInfo restricts the proxies accepted by the hub. Without it all the authenticated
+// proxies are accepted, with it the proxies matching a denied list are refused and the ones
+// matching none of the allowed lists are pending until an operator approves them
+// (gomcp proxy approve or the inspector).
+type MuxPolicyInfo struct {
+	// proxy ids accepted without approval
+	AllowedProxyIds []string `json:"allowedProxyIds,omitempty"`
+	// glob patterns of the working directories of the proxies, /** matches the subdirectories
+	AllowedWorkingDirectories []string `json:"allowedWorkingDirectories,omitempty"`
+	// glob patterns of the commands, or server URLs, run by the proxies
+	AllowedCommands []string `json:"allowedCommands,omitempty"`
+	// the proxies matching the denied lists are refused, even if they match an allowed list
+	DeniedProxyIds           []string `json:"deniedProxyIds,omitempty"`
+	DeniedWorkingDirectories []string `json:"deniedWorkingDirectories,omitempty"`
+	DeniedCommands           []string `json:"deniedCommands,omitempty"`
+}
+
+// Denies returns true if the proxy matches one of the denied lists of the policy
+func (p *MuxPolicyInfo) Denies(proxyId string, workingDirectory string, command string) bool {
+	return matchPolicy(p.DeniedProxyIds, p.DeniedWorkingDirectories, p.DeniedCommands, proxyId, workingDirectory, command)
+}
+
+// Allows returns true if the proxy matches one of the allowed lists of the policy,
+// the denied lists are checked first with Denies
+func (p *MuxPolicyInfo) Allows(proxyId string, workingDirectory string, command string) bool {
+	return matchPolicy(p.AllowedProxyIds, p.AllowedWorkingDirectories, p.AllowedCommands, proxyId, workingDirectory, command)
+}
+
+// matchPolicy returns true if the proxy matches one of the lists
+func matchPolicy(proxyIds []string, workingDirectories []string, commands []string, proxyId string, workingDirectory string, command string) bool {
+	if slices.Contains(proxyIds, proxyId) {
+		return true
+	}
+	for _, pattern := range workingDirectories {
+		if workingDirectory != "" && matchGlob(pattern, filepath.Clean(workingDirectory)) {
+			return true
+		}
+	}
+	for _, pattern := range commands {
+		if command != "" && matchGlob(pattern, command) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a value with a filepath.Match pattern starting with ~/ for the home directory,
+// a pattern ending with /** also matches the subdirectories of what it matches
+func matchGlob(pattern string, value string) bool {
+	if strings.HasPrefix(pattern, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		pattern = filepath.Join(home, pattern[2:])
+	}
+	base, recursive := strings.CutSuffix(pattern, "/**")
+	if !recursive {
+		matched, _ := filepath.Match(pattern, value)
+		return matched
+	}
+	for {
+		if matched, _ := filepath.Match(base, value); matched {
+			return true
+		}
+		parent := filepath.Dir(value)
+		if parent == value {
+			return false
+		}
+		value = parent
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMuxPolicyAllows(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	policy := &MuxPolicyInfo{
+		AllowedProxyIds:           []string{"proxy-1"},
+		AllowedWorkingDirectories: []string{"/work/trusted", "/srv/*/mcp", "~/projects/**"},
+		AllowedCommands:           []string{"/usr/local/bin/mcp-*", "npx"},
+	}
+
+	tests := []struct {
+		name             string
+		proxyId          string
+		workingDirectory string
+		command          string
+		want             bool
+	}{
+		{"allowed proxy id", "proxy-1", "/tmp", "bash", true},
+		{"unknown proxy", "proxy-2", "/tmp", "bash", false},
+		{"exact directory", "proxy-2", "/work/trusted", "bash", true},
+		{"subdirectory without **", "proxy-2", "/work/trusted/sub", "bash", false},
+		{"directory glob", "proxy-2", "/srv/app/mcp", "bash", true},
+		{"home subdirectory", "proxy-2", filepath.Join(home, "projects", "a", "b"), "bash", true},
+		{"home itself", "proxy-2", home, "bash", false},
+		{"command glob", "proxy-2", "/tmp", "/usr/local/bin/mcp-git", true},
+		{"command", "proxy-2", "/tmp", "npx", true},
+		{"other command", "proxy-2", "/tmp", "/usr/bin/npx", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allows(tt.proxyId, tt.workingDirectory, tt.command); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMuxPolicyDenies(t *testing.T) {
+	policy := &MuxPolicyInfo{
+		AllowedProxyIds:          []string{"proxy-1"},
+		AllowedCommands:          []string{"npx"},
+		DeniedProxyIds:           []string{"proxy-1"},
+		DeniedWorkingDirectories: []string{"/tmp/**"},
+		DeniedCommands:           []string{"/usr/bin/*"},
+	}
+
+	tests := []struct {
+		name             string
+		proxyId          string
+		workingDirectory string
+		command          string
+		want             bool
+	}{
+		{"denied proxy id also allowed", "proxy-1", "/work", "npx", true},
+		{"other proxy", "proxy-2", "/work", "npx", false},
+		{"denied directory", "proxy-2", "/tmp/a/b", "npx", true},
+		{"denied command", "proxy-2", "/work", "/usr/bin/npx", true},
+		{"without working directory and command", "proxy-2", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Denies(tt.proxyId, tt.workingDirectory, tt.command); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
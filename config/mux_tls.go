@@ -0,0 +1,132 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hamstah/gomcp/defaults"
+)
+
+// MuxTlsInfo enables TLS on the mux link. The hub uses the certificate and key, the
+// proxies verify it with the CA, the pinned fingerprint or the system roots. With a
+// client CA the hub requires mutual TLS: the proxies present the certificate issued
+// for their proxy id (tls/<proxyId>.crt in the hub configuration directory).
+type MuxTlsInfo struct {
+	// certificate and private key of the hub
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// CA of the certificates of the proxies, requires mutual TLS
+	ClientCaFile string `json:"clientCaFile,omitempty"`
+	// CA the proxies verify the certificate of the hub with
+	CaFile string `json:"caFile,omitempty"`
+	// SHA-256 of the certificate of the hub pinned by the proxies
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// name verified in the certificate of the hub, defaults to the host of the address
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// GetDefaultTlsDirectory returns the directory of the certificates generated for the mux link
+func GetDefaultTlsDirectory() string {
+	return filepath.Join(defaults.DefaultHubConfigurationDirectory, defaults.DefaultTlsDirectory)
+}
+
+// UpdateFilePaths makes the paths relative to the hub configuration directory absolute
+func (t *MuxTlsInfo) UpdateFilePaths() {
+	t.CertFile = updateFilePath(t.CertFile)
+	t.KeyFile = updateFilePath(t.KeyFile)
+	t.ClientCaFile = updateFilePath(t.ClientCaFile)
+	t.CaFile = updateFilePath(t.CaFile)
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+// ServerTlsConfig returns the TLS configuration of the mux server of the hub
+func (t *MuxTlsInfo) ServerTlsConfig() (*tls.Config, error) {
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, fmt.Errorf("the certificate and key of the hub are required for TLS (certFile, keyFile)")
+	}
+	certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the certificate of the hub: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if t.ClientCaFile != "" {
+		clientCas, err := loadCertPool(t.ClientCaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client CA: %w", err)
+		}
+		tlsConfig.ClientCAs = clientCas
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// ClientTlsConfig returns the TLS configuration of a proxy, it presents
+// the certificate issued for its proxy id if there is one
+func (t *MuxTlsInfo) ClientTlsConfig(proxyId string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.CaFile != "" {
+		rootCas, err := loadCertPool(t.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the CA: %w", err)
+		}
+		tlsConfig.RootCAs = rootCas
+	}
+	if t.Fingerprint != "" {
+		fingerprint := normalizeFingerprint(t.Fingerprint)
+		if t.CaFile == "" {
+			// the pinned certificate replaces the verification of the chain
+			tlsConfig.InsecureSkipVerify = true
+		}
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || CertificateFingerprint(rawCerts[0]) != fingerprint {
+				return fmt.Errorf("the certificate of the hub does not match the pinned fingerprint")
+			}
+			return nil
+		}
+	}
+
+	certFile, keyFile := MuxCertificatePaths(proxyId)
+	if _, err := os.Stat(certFile); err == nil {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the certificate of the proxy: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+// CertificateFingerprint returns the hex encoded SHA-256 of a DER encoded certificate
+func CertificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint accepts the fingerprints with colons, in upper case or prefixed with sha256:
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimPrefix(strings.ToLower(fingerprint), "sha256:")
+	return strings.ReplaceAll(fingerprint, ":", "")
+}
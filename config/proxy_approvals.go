@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/hamstah/gomcp/defaults"
+)
+
+type ProxyApprovalStatus string
+
+const (
+	ProxyApprovalPending  ProxyApprovalStatus = "pending"
+	ProxyApprovalApproved ProxyApprovalStatus = "approved"
+	ProxyApprovalDenied   ProxyApprovalStatus = "denied"
+)
+
+// ProxyApproval is the decision of an operator about a proxy that the policy does not allow
+type ProxyApproval struct {
+	ProxyId          string              `json:"proxyId"`
+	Status           ProxyApprovalStatus `json:"status"`
+	Name             string              `json:"name,omitempty"`
+	WorkingDirectory string              `json:"workingDirectory,omitempty"`
+	Command          string              `json:"command,omitempty"`
+	// the decisions about the proxies without persistent proxy id end with their connection
+	Persistent bool   `json:"persistent"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// ProxyApprovals are shared by the hub, which adds the pending proxies, and the
+// gomcp proxy command, which approves or denies them
+type ProxyApprovals struct {
+	Proxies []ProxyApproval `json:"proxies"`
+}
+
+var defaultProxyApprovalsPath = filepath.Join(defaults.DefaultHubConfigurationDirectory, defaults.DefaultProxyApprovalsFile)
+
+func GetDefaultProxyApprovalsPath() string {
+	return defaultProxyApprovalsPath
+}
+
+// LoadProxyApprovals loads the decisions about the proxies, there are none without file
+func LoadProxyApprovals() (*ProxyApprovals, error) {
+	return LoadProxyApprovalsFile(defaultProxyApprovalsPath)
+}
+
+// LoadProxyApprovalsFile loads the decisions about the proxies from a file, the file is
+// replaced at once by the updates so it is read without lock
+func LoadProxyApprovalsFile(path string) (*ProxyApprovals, error) {
+	approvals := &ProxyApprovals{Proxies: []ProxyApproval{}}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return approvals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, approvals); err != nil {
+		return nil, fmt.Errorf("invalid proxy approvals file %s: %w", path, err)
+	}
+	return approvals, nil
+}
+
+// UpdateProxyApprovals changes the decisions about the proxies, see UpdateProxyApprovalsFile
+func UpdateProxyApprovals(update func(approvals *ProxyApprovals) (bool, error)) error {
+	return UpdateProxyApprovalsFile(defaultProxyApprovalsPath, update)
+}
+
+// UpdateProxyApprovalsFile reads the decisions, updates them and saves them if update
+// returns true. The hub and the gomcp proxy command both write the file, the update
+// holds a lock on the .lock file next to it so that none of their changes is lost.
+func UpdateProxyApprovalsFile(path string, update func(approvals *ProxyApprovals) (bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock the proxy approvals file %s: %w", path, err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	approvals, err := LoadProxyApprovalsFile(path)
+	if err != nil {
+		return err
+	}
+	changed, err := update(approvals)
+	if err != nil || !changed {
+		return err
+	}
+	return saveProxyApprovals(path, approvals)
+}
+
+// saveProxyApprovals replaces the file at once, the hub may read it at any time
+func saveProxyApprovals(path string, approvals *ProxyApprovals) error {
+	content, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Get returns the decision about a proxy, nil if there is none
+func (a *ProxyApprovals) Get(proxyId string) *ProxyApproval {
+	for i := range a.Proxies {
+		if a.Proxies[i].ProxyId == proxyId {
+			return &a.Proxies[i]
+		}
+	}
+	return nil
+}
+
+// Set adds or replaces the decision about a proxy
+func (a *ProxyApprovals) Set(approval ProxyApproval) {
+	approval.UpdatedAt = time.Now().Format(time.RFC3339)
+	if existing := a.Get(approval.ProxyId); existing != nil {
+		*existing = approval
+		return
+	}
+	a.Proxies = append(a.Proxies, approval)
+}
+
+// Remove forgets a proxy, it returns false if there was no decision about it
+func (a *ProxyApprovals) Remove(proxyId string) bool {
+	count := len(a.Proxies)
+	a.Proxies = slices.DeleteFunc(a.Proxies, func(approval ProxyApproval) bool {
+		return approval.ProxyId == proxyId
+	})
+	return len(a.Proxies) != count
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateProxyApprovalsFileConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_approvals.json")
+
+	// the updates open the lock file each time, as the hub and the gomcp proxy command do
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := UpdateProxyApprovalsFile(path, func(approvals *ProxyApprovals) (bool, error) {
+				// leaves the time to the other updates to read the file
+				time.Sleep(time.Millisecond)
+				approvals.Set(ProxyApproval{ProxyId: fmt.Sprintf("proxy-%d", i), Status: ProxyApprovalPending})
+				return true, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	approvals, err := LoadProxyApprovalsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(approvals.Proxies) != 20 {
+		t.Errorf("expected the 20 proxies, got %d", len(approvals.Proxies))
+	}
+
+	// the file is not written when the update fails
+	err = UpdateProxyApprovalsFile(path, func(approvals *ProxyApprovals) (bool, error) {
+		approvals.Remove("proxy-0")
+		return false, fmt.Errorf("unknown proxy")
+	})
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+	approvals, _ = LoadProxyApprovalsFile(path)
+	if approvals.Get("proxy-0") == nil {
+		t.Errorf("expected the proxy to be kept")
+	}
+}
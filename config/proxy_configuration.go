@@ -21,6 +21,7 @@ type ProxyConfiguration struct {
 	ProxyId               string   `json:"proxy_id"`
 	ProgramName           string   `json:"program_name"`
 	ProgramArgs           []string `json:"program_args"`
+	ServerUrl             string   `json:"server_url,omitempty"`
 	LastStarted           string   `json:"last_started"`
 }
 
@@ -10,12 +10,14 @@ import (
 )
 
 type ServerConfiguration struct {
-	ConfigVersion int            `json:"v"`
-	ServerInfo    ServerInfo     `json:"serverInfo"`
-	Logging       *LoggingInfo   `json:"logging,omitempty"`
-	Inspector     *InspectorInfo `json:"inspector,omitempty"`
-	Tools         []ToolConfig   `json:"tools,omitempty"`
-	Prompts       *PromptConfig  `json:"prompts,omitempty"`
+	ConfigVersion int              `json:"v"`
+	ServerInfo    ServerInfo       `json:"serverInfo"`
+	Logging       *LoggingInfo     `json:"logging,omitempty"`
+	Inspector     *InspectorInfo   `json:"inspector,omitempty"`
+	Tools         []ToolConfig     `json:"tools,omitempty"`
+	Resources     []ResourceConfig `json:"resources,omitempty"`
+	Prompts       *PromptConfig    `json:"prompts,omitempty"`
+	Pagination    *PaginationInfo  `json:"pagination,omitempty"`
 }
 
 func LoadServerConfig(configFilePath string) (*ServerConfiguration, error) {
@@ -11,6 +11,10 @@ const (
 	DefaultWsPort              = 8080
 	DefaultProxyConfigPath     = "gomcp-proxy.json"
 	DefaultProxyToolsDirectory = "proxy_tools"
+	DefaultMuxKeyFile          = "mux.key"
+	DefaultTlsDirectory        = "tls"
+	DefaultProxyApprovalsFile  = "proxy_approvals.json"
+	DefaultListPageSize        = 100
 )
 
 var DefaultHubConfigurationDirectory = filepath.Join(os.Getenv("HOME"), ".gomcp")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/hamstah/gomcp/channels/hub"
+	"github.com/hamstah/gomcp/protocol/mcp"
 	"github.com/hamstah/gomcp/tools"
 	"github.com/hamstah/gomcp/types"
 )
@@ -20,3 +21,61 @@ func NewModelContextProtocolServer(configFilePath string) (types.ModelContextPro
 func GetLogger(ctx context.Context) types.Logger {
 	return tools.GetLogger(ctx)
 }
+
+// ReportProgress sends a progress notification to the client for the current tool call.
+// total is 0 if unknown, message is optional. Progress must increase with each call.
+// Nothing is sent if the client did not ask for progress notifications.
+func ReportProgress(ctx context.Context, progress float64, total float64, message string) {
+	tools.ReportProgress(ctx, progress, total, message)
+}
+
+// HttpServerOptions configures the HTTP server of the Streamable HTTP, SSE and WebSocket transports
+type HttpServerOptions = types.HttpServerOptions
+
+// StreamableHttpOptions configures the Streamable HTTP transport of the server
+type StreamableHttpOptions = types.StreamableHttpOptions
+
+// SseOptions configures the legacy HTTP+SSE transport of the server
+type SseOptions = types.SseOptions
+
+// WebSocketOptions configures the WebSocket transport of the server
+type WebSocketOptions = types.WebSocketOptions
+
+type Root = mcp.Root
+
+// GetRoots returns the roots (directories or files) of the client, they are refreshed
+// when the client notifies a change. It returns nil if the client does not support roots.
+func GetRoots(ctx context.Context) []Root {
+	return tools.GetRoots(ctx)
+}
+
+type CreateMessageRequest = mcp.JsonRpcRequestSamplingCreateMessageParams
+type CreateMessageResult = mcp.JsonRpcResponseSamplingCreateMessageResult
+type SamplingMessage = mcp.SamplingMessage
+type SamplingContent = mcp.SamplingContent
+type ModelPreferences = mcp.ModelPreferences
+type ModelHint = mcp.ModelHint
+
+// CreateMessage asks the LLM of the client to generate a message (sampling) during a tool call.
+// It blocks until the client answers or the context is cancelled.
+// An error is returned if the client does not support sampling.
+func CreateMessage(ctx context.Context, request *CreateMessageRequest) (*CreateMessageResult, error) {
+	return tools.CreateMessage(ctx, request)
+}
+
+type ElicitationAction = mcp.ElicitationAction
+
+const (
+	ElicitationActionAccept  = mcp.ElicitationActionAccept
+	ElicitationActionDecline = mcp.ElicitationActionDecline
+	ElicitationActionCancel  = mcp.ElicitationActionCancel
+)
+
+// Elicit asks the user of the client for structured input during a tool call.
+// The schema of the request is derived from target, a pointer to a struct with
+// string, number or boolean fields, that is filled when the user accepts.
+// It blocks until the client answers or the context is cancelled.
+// An error is returned if the client does not support elicitation.
+func Elicit(ctx context.Context, message string, target interface{}) (ElicitationAction, error) {
+	return tools.Elicit(ctx, message, target)
+}
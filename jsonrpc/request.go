@@ -47,7 +47,7 @@ func ParseJsonRpcRequest(rawJson JsonRpcRawMessage) (*JsonRpcRequest, *JsonRpcRe
 			version, ok := value.(string)
 			if !ok {
 				return nil, requestId, &JsonRpcError{
-					Code:    RpcParseError,
+					Code:    RpcInvalidRequest,
 					Message: "invalid JSON-RPC version",
 				}
 			}
@@ -13,8 +13,9 @@ type RawJsonRpcResponseMessage struct {
 }
 
 type RawJsonError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    *json.RawMessage `json:"data,omitempty"`
 }
 
 type RawJsonRpcErrorMessage struct {
@@ -55,7 +56,7 @@ func MarshalJsonRpcResponse(response *JsonRpcResponse) ([]byte, error) {
 	if response.Error != nil {
 		return json.Marshal(RawJsonRpcErrorMessage{
 			JsonRpcVersion: JsonRpcVersion,
-			Error:          RawJsonError{Code: response.Error.Code, Message: response.Error.Message},
+			Error:          RawJsonError{Code: response.Error.Code, Message: response.Error.Message, Data: response.Error.Data},
 			Id:             responseId,
 		})
 	}
@@ -151,6 +152,14 @@ func parseJsonRpcError(error map[string]interface{}) *JsonRpcError {
 				return nil
 			}
 			jsonRpcError.Message = message
+		case "data":
+			// the data can be any JSON value
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil
+			}
+			rawData := json.RawMessage(data)
+			jsonRpcError.Data = &rawData
 		}
 	}
 	return jsonRpcError
@@ -79,6 +79,31 @@ func TestMarshalJsonRpcResponse(t *testing.T) {
 		assert.Equal(t, "Invalid Request", parsed.Error.Message)
 	})
 
+	t.Run("error response with data and null id", func(t *testing.T) {
+		errorData := json.RawMessage(`"unexpected end of JSON input"`)
+		response := &JsonRpcResponse{
+			Id: nil,
+			Error: &JsonRpcError{
+				Code:    RpcParseError,
+				Message: "Parse error",
+				Data:    &errorData,
+			},
+		}
+
+		data, err := MarshalJsonRpcResponse(response)
+		if debugTestResponse {
+			fmt.Printf("[%s]: %s\n", t.Name(), string(data))
+		}
+		assert.NoError(t, err)
+
+		var parsed RawJsonRpcErrorMessage
+		err = json.Unmarshal(data, &parsed)
+		assert.NoError(t, err)
+		assert.Equal(t, json.RawMessage(`null`), parsed.Id)
+		assert.Equal(t, RpcParseError, parsed.Error.Code)
+		assert.Equal(t, &errorData, parsed.Error.Data)
+	})
+
 	t.Run("null id", func(t *testing.T) {
 		result := json.RawMessage(`"hello"`)
 		response := &JsonRpcResponse{
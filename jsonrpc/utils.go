@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 func extractId(rawJson map[string]interface{}) *JsonRpcRequestId {
@@ -11,8 +12,12 @@ func extractId(rawJson map[string]interface{}) *JsonRpcRequestId {
 	if !ok {
 		return nil
 	}
+	return RequestIdFromValue(value)
+}
 
-	// the id can be a number or a string
+// RequestIdFromValue converts a decoded JSON value to a request id,
+// the id can be a number or a string
+func RequestIdFromValue(value interface{}) *JsonRpcRequestId {
 	switch v := value.(type) {
 	case int:
 		return &JsonRpcRequestId{Number: &v}
@@ -26,6 +31,21 @@ func extractId(rawJson map[string]interface{}) *JsonRpcRequestId {
 	}
 }
 
+// RequestIdToValue converts a request id to a value that can be
+// marshalled in the params of a message
+func RequestIdToValue(reqId *JsonRpcRequestId) interface{} {
+	if reqId == nil {
+		return nil
+	}
+	if reqId.Number != nil {
+		return *reqId.Number
+	}
+	if reqId.String != nil {
+		return *reqId.String
+	}
+	return nil
+}
+
 func RequestIdToString(register *JsonRpcRequestId) string {
 	if register == nil {
 		return "X"
@@ -77,6 +97,7 @@ type reqIdMappingEntry struct {
 
 type ReqIdMapping struct {
 	entries []reqIdMappingEntry
+	mutex   sync.Mutex
 }
 
 func NewReqIdMapping() *ReqIdMapping {
@@ -86,10 +107,14 @@ func NewReqIdMapping() *ReqIdMapping {
 }
 
 func (m *ReqIdMapping) AddMapping(reqIdA *JsonRpcRequestId, reqIdB *JsonRpcRequestId) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.entries = append(m.entries, reqIdMappingEntry{reqIdA, reqIdB})
 }
 
 func (m *ReqIdMapping) GetMapping(reqId *JsonRpcRequestId) *JsonRpcRequestId {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	var foundEntry *JsonRpcRequestId = nil
 	var ixEntry int = -1
 	for ix, entry := range m.entries {
@@ -107,6 +132,21 @@ func (m *ReqIdMapping) GetMapping(reqId *JsonRpcRequestId) *JsonRpcRequestId {
 	return foundEntry
 }
 
+// GetReverseMapping is the same as GetMapping but looks up the entry
+// from the second request id
+func (m *ReqIdMapping) GetReverseMapping(reqId *JsonRpcRequestId) *JsonRpcRequestId {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for ix, entry := range m.entries {
+		if equalRequestIds(entry.reqIdB, reqId) {
+			// delete the entry from the list
+			m.entries = append(m.entries[:ix], m.entries[ix+1:]...)
+			return entry.reqIdA
+		}
+	}
+	return nil
+}
+
 func equalRequestIds(a, b *JsonRpcRequestId) bool {
 	if a == nil || b == nil {
 		return a == b
@@ -71,3 +71,59 @@ func TestReqIdMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestReqIdReverseMapping(t *testing.T) {
+	mapping := NewReqIdMapping()
+	reqIdA := &JsonRpcRequestId{Number: intPtr(1)}
+	reqIdB := &JsonRpcRequestId{String: stringPtr("b")}
+	mapping.AddMapping(reqIdA, reqIdB)
+
+	result := mapping.GetReverseMapping(&JsonRpcRequestId{String: stringPtr("b")})
+	if !equalRequestIds(result, reqIdA) {
+		t.Errorf("Expected request ID %v, got %v", RequestIdToString(reqIdA), RequestIdToString(result))
+	}
+	// the entry is removed in both directions
+	if result := mapping.GetMapping(reqIdA); result != nil {
+		t.Errorf("Expected nil after reverse retrieval, got %v", RequestIdToString(result))
+	}
+}
+
+func TestRequestIdValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  *JsonRpcRequestId
+	}{
+		{
+			name:  "number",
+			value: float64(3),
+			want:  &JsonRpcRequestId{Number: intPtr(3)},
+		},
+		{
+			name:  "string",
+			value: "abc",
+			want:  &JsonRpcRequestId{String: stringPtr("abc")},
+		},
+		{
+			name:  "invalid",
+			value: true,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqId := RequestIdFromValue(tt.value)
+			if !equalRequestIds(reqId, tt.want) {
+				t.Fatalf("Expected request ID %v, got %v", RequestIdToString(tt.want), RequestIdToString(reqId))
+			}
+			if tt.want == nil {
+				return
+			}
+			// converting back gives a value that can be parsed again
+			if again := RequestIdFromValue(RequestIdToValue(reqId)); !equalRequestIds(again, tt.want) {
+				t.Errorf("Expected request ID %v after round trip, got %v", RequestIdToString(tt.want), RequestIdToString(again))
+			}
+		})
+	}
+}
@@ -26,6 +26,8 @@ type ArgumentDefinition struct {
 	Name        string `json:"name" yaml:"name"`
 	Description string `json:"description" yaml:"description"`
 	Required    bool   `json:"required" yaml:"required"`
+	// static list of values used to complete the argument
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
 }
 
 // loadPrompts reads and parses the prompts.yaml file
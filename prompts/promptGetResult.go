@@ -116,6 +116,37 @@ func (r *PromptGetResultImpl) AddImageContent(role types.Role, mimeType string,
 	})
 }
 
+func (r *PromptGetResultImpl) AddAudioContent(role types.Role, mimeType string, base64Data string) {
+	r.Messages = append(r.Messages, map[string]interface{}{
+		"role": role,
+		"content": map[string]interface{}{
+			"type":     "audio",
+			"data":     base64Data,
+			"mimeType": mimeType,
+		},
+	})
+}
+
+// AddResourceLinkContent references a resource without inlining its content,
+// the description and the mime type are optional
+func (r *PromptGetResultImpl) AddResourceLinkContent(role types.Role, uri string, name string, description string, mimeType string) {
+	content := map[string]interface{}{
+		"type": "resource_link",
+		"uri":  uri,
+		"name": name,
+	}
+	if description != "" {
+		content["description"] = description
+	}
+	if mimeType != "" {
+		content["mimeType"] = mimeType
+	}
+	r.Messages = append(r.Messages, map[string]interface{}{
+		"role":    role,
+		"content": content,
+	})
+}
+
 func (r *PromptGetResultImpl) AddEmbeddedResourceTextContent(role types.Role, uri string, mimeType string, text string) {
 	r.Messages = append(r.Messages, map[string]interface{}{
 		"role": role,
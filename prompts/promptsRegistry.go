@@ -2,18 +2,37 @@ package prompts
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/hamstah/gomcp/types"
 )
 
 type PromptsRegistry struct {
 	prompts []PromptDefinition
+	// completion handlers by prompt name and argument name
+	completionHandlers map[string]map[string]types.CompletionHandler
+	// prompts of the MCP servers behind the proxies, in registration order,
+	// they are added and removed while the hub is running
+	proxyPrompts []proxyPrompts
+	mutex        sync.RWMutex
+}
+
+// proxyPrompts are the prompts of a proxy, the hub forwards their requests to the proxy
+type proxyPrompts struct {
+	proxyId string
+	prompts []PromptDefinition
 }
 
 func NewEmptyPromptsRegistry() *PromptsRegistry {
-	return &PromptsRegistry{prompts: []PromptDefinition{}}
+	return &PromptsRegistry{
+		prompts:            []PromptDefinition{},
+		completionHandlers: map[string]map[string]types.CompletionHandler{},
+	}
 }
 
 func NewPromptsRegistry(promptYamlFilePath string) (*PromptsRegistry, error) {
@@ -21,11 +40,91 @@ func NewPromptsRegistry(promptYamlFilePath string) (*PromptsRegistry, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &PromptsRegistry{prompts: prompts.Prompts}, nil
+	return &PromptsRegistry{
+		prompts:            prompts.Prompts,
+		completionHandlers: map[string]map[string]types.CompletionHandler{},
+	}, nil
 }
 
+// GetListOfPrompts returns the prompts of the hub followed by the prompts of the proxies
 func (r *PromptsRegistry) GetListOfPrompts() []PromptDefinition {
-	return r.prompts
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	prompts := slices.Clone(r.prompts)
+	for _, proxy := range r.proxyPrompts {
+		prompts = append(prompts, proxy.prompts...)
+	}
+	return prompts
+}
+
+// SetProxyPrompts replaces the prompts of a proxy, it returns the names of the prompts
+// that are skipped because the hub or another proxy already has a prompt with that name
+func (r *PromptsRegistry) SetProxyPrompts(proxyId string, prompts []PromptDefinition) []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := []PromptDefinition{}
+	skipped := []string{}
+	for _, prompt := range prompts {
+		owner, found := r.findPromptOwner(prompt.Name)
+		duplicate := slices.ContainsFunc(kept, func(keptPrompt PromptDefinition) bool {
+			return keptPrompt.Name == prompt.Name
+		})
+		if (found && owner != proxyId) || duplicate {
+			skipped = append(skipped, prompt.Name)
+			continue
+		}
+		kept = append(kept, prompt)
+	}
+
+	index := slices.IndexFunc(r.proxyPrompts, func(proxy proxyPrompts) bool { return proxy.proxyId == proxyId })
+	if index == -1 {
+		r.proxyPrompts = append(r.proxyPrompts, proxyPrompts{proxyId: proxyId, prompts: kept})
+	} else {
+		r.proxyPrompts[index].prompts = kept
+	}
+	return skipped
+}
+
+// RemoveProxyPrompts forgets the prompts of a proxy that disconnected,
+// it returns false if the proxy had no prompts
+func (r *PromptsRegistry) RemoveProxyPrompts(proxyId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	hadPrompts := false
+	r.proxyPrompts = slices.DeleteFunc(r.proxyPrompts, func(proxy proxyPrompts) bool {
+		if proxy.proxyId != proxyId {
+			return false
+		}
+		hadPrompts = len(proxy.prompts) > 0
+		return true
+	})
+	return hadPrompts
+}
+
+// GetPromptProxyId returns the id of the proxy serving the prompt,
+// empty for the prompts of the hub and the unknown prompts
+func (r *PromptsRegistry) GetPromptProxyId(name string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	proxyId, _ := r.findPromptOwner(name)
+	return proxyId
+}
+
+// findPromptOwner returns the proxy id of a prompt, empty for a prompt of the hub,
+// and false if no prompt has that name. The mutex must be held.
+func (r *PromptsRegistry) findPromptOwner(name string) (string, bool) {
+	if r.findPrompt(name) != nil {
+		return "", true
+	}
+	for _, proxy := range r.proxyPrompts {
+		for _, prompt := range proxy.prompts {
+			if prompt.Name == name {
+				return proxy.proxyId, true
+			}
+		}
+	}
+	return "", false
 }
 
 func (r *PromptsRegistry) findPrompt(name string) *PromptDefinition {
@@ -74,3 +173,54 @@ func (r *PromptsRegistry) GetPrompt(promptName string, arguments map[string]stri
 
 	return output, nil
 }
+
+func findArgument(prompt *PromptDefinition, argumentName string) *ArgumentDefinition {
+	for _, argument := range prompt.Arguments {
+		if argument.Name == argumentName {
+			return &argument
+		}
+	}
+	return nil
+}
+
+// AddPromptArgumentCompletion registers the function that completes an argument of a prompt,
+// it replaces the static list of values of the argument
+func (r *PromptsRegistry) AddPromptArgumentCompletion(promptName string, argumentName string, completionHandler types.CompletionHandler) error {
+	prompt := r.findPrompt(promptName)
+	if prompt == nil {
+		return fmt.Errorf("prompt %s not found", promptName)
+	}
+	if findArgument(prompt, argumentName) == nil {
+		return fmt.Errorf("prompt %s has no argument %s", promptName, argumentName)
+	}
+	if r.completionHandlers[promptName] == nil {
+		r.completionHandlers[promptName] = map[string]types.CompletionHandler{}
+	}
+	r.completionHandlers[promptName][argumentName] = completionHandler
+	return nil
+}
+
+// CompletePromptArgument returns the possible values of a prompt argument
+func (r *PromptsRegistry) CompletePromptArgument(ctx context.Context, promptName string, argumentName string, value string, arguments map[string]string) ([]string, error) {
+	prompt := r.findPrompt(promptName)
+	if prompt == nil {
+		return nil, fmt.Errorf("prompt %s not found", promptName)
+	}
+	argument := findArgument(prompt, argumentName)
+	if argument == nil {
+		return nil, fmt.Errorf("prompt %s has no argument %s", promptName, argumentName)
+	}
+
+	if completionHandler, ok := r.completionHandlers[promptName][argumentName]; ok {
+		return completionHandler(ctx, value, arguments)
+	}
+
+	// the static values are filtered with the value typed by the user
+	values := []string{}
+	for _, candidate := range argument.Values {
+		if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(value)) {
+			values = append(values, candidate)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,142 @@
+package prompts
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCompletePromptArgument(t *testing.T) {
+	registry := NewEmptyPromptsRegistry()
+	registry.prompts = []PromptDefinition{
+		{
+			Name: "review",
+			Arguments: []ArgumentDefinition{
+				{Name: "language", Values: []string{"Go", "Python", "golang"}},
+				{Name: "file"},
+			},
+		},
+	}
+	err := registry.AddPromptArgumentCompletion("review", "file", func(ctx context.Context, value string, arguments map[string]string) ([]string, error) {
+		return []string{arguments["language"] + "/" + value}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		promptName string
+		argument   string
+		value      string
+		arguments  map[string]string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "static values filtered by prefix",
+			promptName: "review",
+			argument:   "language",
+			value:      "go",
+			want:       []string{"Go", "golang"},
+		},
+		{
+			name:       "static values without match",
+			promptName: "review",
+			argument:   "language",
+			value:      "rust",
+			want:       []string{},
+		},
+		{
+			name:       "completion handler",
+			promptName: "review",
+			argument:   "file",
+			value:      "main",
+			arguments:  map[string]string{"language": "Go"},
+			want:       []string{"Go/main"},
+		},
+		{
+			name:       "unknown argument",
+			promptName: "review",
+			argument:   "unknown",
+			wantErr:    true,
+		},
+		{
+			name:       "unknown prompt",
+			promptName: "unknown",
+			argument:   "language",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := registry.CompletePromptArgument(context.Background(), tt.promptName, tt.argument, tt.value, tt.arguments)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, values)
+			}
+		})
+	}
+}
+
+func promptNames(registry *PromptsRegistry) []string {
+	names := []string{}
+	for _, prompt := range registry.GetListOfPrompts() {
+		names = append(names, prompt.Name)
+	}
+	return names
+}
+
+func TestProxyPrompts(t *testing.T) {
+	registry := NewEmptyPromptsRegistry()
+	registry.prompts = []PromptDefinition{{Name: "local"}}
+
+	skipped := registry.SetProxyPrompts("proxy-1", []PromptDefinition{{Name: "a"}, {Name: "local"}, {Name: "a"}})
+	if !reflect.DeepEqual(skipped, []string{"local", "a"}) {
+		t.Errorf("expected the prompts with an existing name to be skipped, got %v", skipped)
+	}
+	registry.SetProxyPrompts("proxy-2", []PromptDefinition{{Name: "b"}, {Name: "a"}})
+	if got := promptNames(registry); !reflect.DeepEqual(got, []string{"local", "a", "b"}) {
+		t.Errorf("unexpected prompts: %v", got)
+	}
+
+	tests := []struct {
+		prompt      string
+		wantProxyId string
+	}{
+		{prompt: "local", wantProxyId: ""},
+		{prompt: "a", wantProxyId: "proxy-1"},
+		{prompt: "b", wantProxyId: "proxy-2"},
+		{prompt: "unknown", wantProxyId: ""},
+	}
+	for _, tt := range tests {
+		if got := registry.GetPromptProxyId(tt.prompt); got != tt.wantProxyId {
+			t.Errorf("expected the proxy %q for %s, got %q", tt.wantProxyId, tt.prompt, got)
+		}
+	}
+
+	// the prompts of a proxy are replaced when it sends them again, their order is kept
+	registry.SetProxyPrompts("proxy-1", []PromptDefinition{{Name: "c"}})
+	if got := promptNames(registry); !reflect.DeepEqual(got, []string{"local", "c", "b"}) {
+		t.Errorf("unexpected prompts: %v", got)
+	}
+
+	if !registry.RemoveProxyPrompts("proxy-1") {
+		t.Errorf("expected the proxy to have prompts")
+	}
+	if registry.RemoveProxyPrompts("proxy-1") {
+		t.Errorf("expected the prompts of the proxy to be removed")
+	}
+	if got := promptNames(registry); !reflect.DeepEqual(got, []string{"local", "b"}) {
+		t.Errorf("unexpected prompts: %v", got)
+	}
+}
@@ -1,5 +1,13 @@
 package mcp
 
 const (
-	ProtocolVersion = "2024-11-05"
+	// latest protocol version supported, used by the proxy
+	// when initializing the proxied MCP server
+	ProtocolVersion = ProtocolVersion20250618
+)
+
+const (
+	// MCP specific error codes
+	// https://spec.modelcontextprotocol.io/specification/server/resources/#error-handling
+	RpcResourceNotFound = -32002
 )
@@ -0,0 +1,50 @@
+package mcp
+
+import "fmt"
+
+// types of the content blocks of tool results and prompt messages
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResource     = "resource"
+	ContentTypeResourceLink = "resource_link"
+)
+
+// DowngradeContent replaces the content blocks not supported by the
+// negotiated version with text blocks, the other blocks are unchanged
+func DowngradeContent(version string, content []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(content))
+	for _, item := range content {
+		result = append(result, DowngradeContentBlock(version, item))
+	}
+	return result
+}
+
+// DowngradeContentBlock replaces a content block not supported by the
+// negotiated version with a text block describing it
+func DowngradeContentBlock(version string, item interface{}) interface{} {
+	block, ok := item.(map[string]interface{})
+	if !ok {
+		return item
+	}
+	switch block["type"] {
+	case ContentTypeAudio:
+		// audio was added in 2025-03-26
+		if !SupportsFeature(version, FeatureAudioContent) {
+			return map[string]interface{}{
+				"type": ContentTypeText,
+				"text": fmt.Sprintf("[audio content (%v)]", block["mimeType"]),
+			}
+		}
+	case ContentTypeResourceLink:
+		// resource links were added in 2025-06-18
+		if !SupportsFeature(version, FeatureResourceLinks) {
+			return map[string]interface{}{
+				"type": ContentTypeText,
+				"text": fmt.Sprintf("[resource %v: %v]", block["name"], block["uri"]),
+			}
+		}
+	}
+	return item
+}
@@ -0,0 +1,60 @@
+package mcp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestDowngradeContentBlock(t *testing.T) {
+	audio := map[string]interface{}{"type": "audio", "data": "aGVsbG8=", "mimeType": "audio/wav"}
+	resourceLink := map[string]interface{}{"type": "resource_link", "uri": "file:///report.pdf", "name": "report"}
+	text := map[string]interface{}{"type": "text", "text": "hello"}
+
+	tests := []struct {
+		name    string
+		version string
+		block   interface{}
+		want    interface{}
+	}{
+		{
+			name:    "text is unchanged",
+			version: mcp.ProtocolVersion20241105,
+			block:   text,
+			want:    text,
+		},
+		{
+			name:    "audio supported",
+			version: mcp.ProtocolVersion20250326,
+			block:   audio,
+			want:    audio,
+		},
+		{
+			name:    "audio not supported",
+			version: mcp.ProtocolVersion20241105,
+			block:   audio,
+			want:    map[string]interface{}{"type": "text", "text": "[audio content (audio/wav)]"},
+		},
+		{
+			name:    "resource link supported",
+			version: mcp.ProtocolVersion20250618,
+			block:   resourceLink,
+			want:    resourceLink,
+		},
+		{
+			name:    "resource link not supported",
+			version: mcp.ProtocolVersion20250326,
+			block:   resourceLink,
+			want:    map[string]interface{}{"type": "text", "text": "[resource report: file:///report.pdf]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mcp.DowngradeContentBlock(tt.version, tt.block); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DowngradeContentBlock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// _meta field of the requests
+type RequestMeta struct {
+	// string or number, set by the client to receive progress notifications
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+func parseRequestMeta(namedParams map[string]interface{}) (*RequestMeta, error) {
+	meta := protocol.GetOptionalObjectField(namedParams, "_meta")
+	if meta == nil {
+		return nil, nil
+	}
+
+	requestMeta := &RequestMeta{}
+	if progressToken, ok := meta["progressToken"]; ok {
+		err := checkProgressToken(progressToken)
+		if err != nil {
+			return nil, err
+		}
+		requestMeta.ProgressToken = progressToken
+	}
+	return requestMeta, nil
+}
+
+func checkProgressToken(progressToken interface{}) error {
+	switch progressToken.(type) {
+	case string, float64:
+		return nil
+	default:
+		return fmt.Errorf("progressToken must be a string or a number")
+	}
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// the cursor is opaque for the client, it is the base64 encoding
+// of the list it was issued for and the offset of the next page
+type paginationCursor struct {
+	List   string `json:"l"`
+	Offset int    `json:"o"`
+}
+
+func encodeCursor(list string, offset int) string {
+	cursorBytes, err := json.Marshal(&paginationCursor{List: list, Offset: offset})
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+func decodeCursor(list string, cursor string) (int, error) {
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	var decoded paginationCursor
+	err = json.Unmarshal(cursorBytes, &decoded)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	if decoded.List != list {
+		return 0, fmt.Errorf("invalid cursor, not issued for %s", list)
+	}
+	if decoded.Offset <= 0 {
+		return 0, fmt.Errorf("invalid cursor offset")
+	}
+	return decoded.Offset, nil
+}
+
+// Paginate returns the bounds [start, end) of the page of a list of totalItems
+// items for the cursor sent by the client, and the cursor of the next page
+// (nil if this is the last page). list is the name of the list method, so that
+// a cursor issued for a list cannot be used for another one.
+func Paginate(list string, cursor *string, totalItems int, pageSize int) (int, int, *string, error) {
+	start := 0
+	if cursor != nil {
+		offset, err := decodeCursor(list, *cursor)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		// the list may have shrunk since the cursor was issued
+		if offset > totalItems {
+			return 0, 0, nil, fmt.Errorf("invalid cursor, out of range")
+		}
+		start = offset
+	}
+
+	// a page size of 0 means no pagination
+	if pageSize <= 0 || start+pageSize >= totalItems {
+		return start, totalItems, nil, nil
+	}
+
+	end := start + pageSize
+	nextCursor := encodeCursor(list, end)
+	return start, end, &nextCursor, nil
+}
@@ -0,0 +1,82 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestPaginate(t *testing.T) {
+	// walk through the list page by page
+	var cursor *string
+	pages := [][2]int{}
+	for {
+		start, end, nextCursor, err := mcp.Paginate(mcp.RpcRequestMethodToolsList, cursor, 7, 3)
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		pages = append(pages, [2]int{start, end})
+		if nextCursor == nil {
+			break
+		}
+		cursor = nextCursor
+	}
+	want := [][2]int{{0, 3}, {3, 6}, {6, 7}}
+	if len(pages) != len(want) {
+		t.Fatalf("Paginate() pages = %v, want %v", pages, want)
+	}
+	for ix := range want {
+		if pages[ix] != want[ix] {
+			t.Errorf("Paginate() pages = %v, want %v", pages, want)
+		}
+	}
+}
+
+func TestPaginateInvalidCursor(t *testing.T) {
+	_, _, toolsCursor, err := mcp.Paginate(mcp.RpcRequestMethodToolsList, nil, 10, 5)
+	if err != nil || toolsCursor == nil {
+		t.Fatalf("Paginate() cursor = %v, error = %v", toolsCursor, err)
+	}
+
+	tests := []struct {
+		name       string
+		list       string
+		cursor     string
+		totalItems int
+	}{
+		{
+			name:       "not base64",
+			list:       mcp.RpcRequestMethodToolsList,
+			cursor:     "not a cursor!",
+			totalItems: 10,
+		},
+		{
+			name:       "not json",
+			list:       mcp.RpcRequestMethodToolsList,
+			cursor:     "bm90IGpzb24",
+			totalItems: 10,
+		},
+		{
+			name:       "issued for another list",
+			list:       mcp.RpcRequestMethodPromptsList,
+			cursor:     *toolsCursor,
+			totalItems: 10,
+		},
+		{
+			name:       "out of range",
+			list:       mcp.RpcRequestMethodToolsList,
+			cursor:     *toolsCursor,
+			totalItems: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := tt.cursor
+			_, _, _, err := mcp.Paginate(tt.list, &cursor, tt.totalItems, 5)
+			if err == nil {
+				t.Errorf("Paginate() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcNotificationMethodCancelled = "notifications/cancelled"
+)
+
+type JsonRpcNotificationCancelledParams struct {
+	// id of the request to cancel, string or number
+	RequestId interface{} `json:"requestId"`
+	Reason    *string     `json:"reason,omitempty"`
+}
+
+func ParseJsonRpcNotificationCancelledParams(params *jsonrpc.JsonRpcParams) (*JsonRpcNotificationCancelledParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := params.NamedParams
+
+	requestId := namedParams["requestId"]
+	if jsonrpc.RequestIdFromValue(requestId) == nil {
+		return nil, fmt.Errorf("requestId must be a string or a number")
+	}
+
+	return &JsonRpcNotificationCancelledParams{
+		RequestId: requestId,
+		Reason:    protocol.GetOptionalStringField(namedParams, "reason"),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcNotificationMethodMessage = "notifications/message"
+)
+
+type JsonRpcNotificationMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+func ParseJsonRpcNotificationMessageParams(params *jsonrpc.JsonRpcParams) (*JsonRpcNotificationMessageParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := params.NamedParams
+
+	level, err := protocol.GetStringField(namedParams, "level")
+	if err != nil {
+		return nil, fmt.Errorf("level is required")
+	}
+	if LoggingLevelSeverity(level) < 0 {
+		return nil, fmt.Errorf("unknown level %s", level)
+	}
+
+	message := &JsonRpcNotificationMessageParams{
+		Level: level,
+		Data:  namedParams["data"],
+	}
+	if logger := protocol.GetOptionalStringField(namedParams, "logger"); logger != nil {
+		message.Logger = *logger
+	}
+
+	return message, nil
+}
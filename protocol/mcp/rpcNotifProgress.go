@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcNotificationMethodProgress = "notifications/progress"
+)
+
+type JsonRpcNotificationProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         *float64    `json:"total,omitempty"`
+	Message       *string     `json:"message,omitempty"`
+}
+
+func ParseJsonRpcNotificationProgressParams(params *jsonrpc.JsonRpcParams) (*JsonRpcNotificationProgressParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := params.NamedParams
+
+	progressToken := namedParams["progressToken"]
+	err := checkProgressToken(progressToken)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := protocol.GetNumberField(namedParams, "progress")
+	if err != nil {
+		return nil, fmt.Errorf("progress is required")
+	}
+
+	return &JsonRpcNotificationProgressParams{
+		ProgressToken: progressToken,
+		Progress:      progress,
+		Total:         protocol.GetOptionalNumberField(namedParams, "total"),
+		Message:       protocol.GetOptionalStringField(namedParams, "message"),
+	}, nil
+}
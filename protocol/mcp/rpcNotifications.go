@@ -3,5 +3,7 @@ package mcp
 const (
 	RpcNotificationMethodInitialized          = "notifications/initialized"
 	RpcNotificationMethodToolsListChanged     = "notifications/tools/list_changed"
+	RpcNotificationMethodPromptsListChanged   = "notifications/prompts/list_changed"
 	RpcNotificationMethodResourcesListChanged = "notifications/resources/list_changed"
+	RpcNotificationMethodRootsListChanged     = "notifications/roots/list_changed"
 )
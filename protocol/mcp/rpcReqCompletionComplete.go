@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// specification
+// https://modelcontextprotocol.io/specification/2025-06-18/server/utilities/completion
+
+const (
+	RpcRequestMethodCompletionComplete = "completion/complete"
+)
+
+// the kind of object the argument belongs to
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+)
+
+type JsonRpcRequestCompletionCompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	// values of the arguments already resolved by the client
+	Context *CompletionContext `json:"context,omitempty"`
+}
+
+type CompletionReference struct {
+	Type string `json:"type"`
+	// name of the prompt for ref/prompt
+	Name string `json:"name,omitempty"`
+	// uri template of the resource for ref/resource
+	Uri string `json:"uri,omitempty"`
+}
+
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+func ParseJsonRpcRequestCompletionComplete(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestCompletionCompleteParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := params.NamedParams
+
+	req := JsonRpcRequestCompletionCompleteParams{}
+
+	// read the reference
+	ref, err := protocol.GetObjectField(namedParams, "ref")
+	if err != nil {
+		return nil, err
+	}
+	req.Ref.Type, err = protocol.GetStringField(ref, "type")
+	if err != nil {
+		return nil, err
+	}
+	switch req.Ref.Type {
+	case CompletionRefPrompt:
+		req.Ref.Name, err = protocol.GetStringField(ref, "name")
+		if err != nil {
+			return nil, err
+		}
+	case CompletionRefResource:
+		req.Ref.Uri, err = protocol.GetStringField(ref, "uri")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid ref type %s", req.Ref.Type)
+	}
+
+	// read the argument
+	argument, err := protocol.GetObjectField(namedParams, "argument")
+	if err != nil {
+		return nil, err
+	}
+	req.Argument.Name, err = protocol.GetStringField(argument, "name")
+	if err != nil {
+		return nil, err
+	}
+	req.Argument.Value, err = protocol.GetStringField(argument, "value")
+	if err != nil {
+		return nil, err
+	}
+
+	// read the optional context
+	context := protocol.GetOptionalObjectField(namedParams, "context")
+	if context != nil {
+		req.Context = &CompletionContext{
+			Arguments: map[string]string{},
+		}
+		for key, value := range protocol.GetOptionalObjectField(context, "arguments") {
+			valueStr, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("context argument %s must be a string", key)
+			}
+			req.Context.Arguments[key] = valueStr
+		}
+	}
+
+	return &req, nil
+}
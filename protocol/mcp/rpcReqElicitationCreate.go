@@ -0,0 +1,16 @@
+package mcp
+
+// specification
+// https://modelcontextprotocol.io/specification/2025-06-18/client/elicitation
+
+// this request is sent by the server to the client
+const (
+	RpcRequestMethodElicitationCreate = "elicitation/create"
+)
+
+type JsonRpcRequestElicitationCreateParams struct {
+	// the message presented to the user
+	Message string `json:"message"`
+	// flat object schema with primitive properties only
+	RequestedSchema interface{} `json:"requestedSchema"`
+}
@@ -20,18 +20,23 @@ type JsonRpcRequestInitializeParams struct {
 	ClientInfo      ClientInfo         `json:"clientInfo"`
 }
 
+// a nil capability is not supported by the client
 type ClientCapabilities struct {
-	Roots    ClientCapabilitiesRoots    `json:"roots"`
-	Sampling ClientCapabilitiesSampling `json:"sampling"`
+	Roots       *ClientCapabilitiesRoots       `json:"roots,omitempty"`
+	Sampling    *ClientCapabilitiesSampling    `json:"sampling,omitempty"`
+	Elicitation *ClientCapabilitiesElicitation `json:"elicitation,omitempty"`
 }
 
 type ClientCapabilitiesRoots struct {
-	ListChanged bool `json:"listChanged"`
+	ListChanged *bool `json:"listChanged,omitempty"`
 }
 
 type ClientCapabilitiesSampling struct {
 }
 
+type ClientCapabilitiesElicitation struct {
+}
+
 type ClientInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -72,7 +77,22 @@ func ParseJsonRpcRequestInitialize(request *jsonrpc.JsonRpcRequest) (*JsonRpcReq
 	}
 	req.ClientInfo.Version = version
 
-	// TODO: read capabilities and roots
+	// read capabilities, the client may not declare any
+	capabilities := protocol.GetOptionalObjectField(namedParams, "capabilities")
+	if capabilities != nil {
+		roots := protocol.GetOptionalObjectField(capabilities, "roots")
+		if roots != nil {
+			req.Capabilities.Roots = &ClientCapabilitiesRoots{
+				ListChanged: protocol.GetOptionalBoolField(roots, "listChanged"),
+			}
+		}
+		if protocol.GetOptionalObjectField(capabilities, "sampling") != nil {
+			req.Capabilities.Sampling = &ClientCapabilitiesSampling{}
+		}
+		if protocol.GetOptionalObjectField(capabilities, "elicitation") != nil {
+			req.Capabilities.Elicitation = &ClientCapabilitiesElicitation{}
+		}
+	}
 
 	return &req, nil
 }
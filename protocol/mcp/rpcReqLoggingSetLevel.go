@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcRequestMethodLoggingSetLevel = "logging/setLevel"
+)
+
+// logging levels, as defined in RFC 5424 (syslog)
+const (
+	LoggingLevelDebug     = "debug"
+	LoggingLevelInfo      = "info"
+	LoggingLevelNotice    = "notice"
+	LoggingLevelWarning   = "warning"
+	LoggingLevelError     = "error"
+	LoggingLevelCritical  = "critical"
+	LoggingLevelAlert     = "alert"
+	LoggingLevelEmergency = "emergency"
+)
+
+// levels ordered by increasing severity
+var loggingLevels = []string{
+	LoggingLevelDebug,
+	LoggingLevelInfo,
+	LoggingLevelNotice,
+	LoggingLevelWarning,
+	LoggingLevelError,
+	LoggingLevelCritical,
+	LoggingLevelAlert,
+	LoggingLevelEmergency,
+}
+
+// LoggingLevelSeverity returns the severity of a level (higher is more severe)
+// or -1 if the level is unknown
+func LoggingLevelSeverity(level string) int {
+	for ix, loggingLevel := range loggingLevels {
+		if loggingLevel == level {
+			return ix
+		}
+	}
+	return -1
+}
+
+type JsonRpcRequestLoggingSetLevelParams struct {
+	Level string `json:"level"`
+}
+
+func ParseJsonRpcRequestLoggingSetLevel(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestLoggingSetLevelParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, no parameters provided")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+
+	level, err := protocol.GetStringField(params.NamedParams, "level")
+	if err != nil {
+		return nil, fmt.Errorf("invalid call parameters, level is not a string")
+	}
+	if LoggingLevelSeverity(level) < 0 {
+		return nil, fmt.Errorf("invalid call parameters, unknown level %s", level)
+	}
+
+	return &JsonRpcRequestLoggingSetLevelParams{
+		Level: level,
+	}, nil
+}
@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcRequestMethodResourcesTemplatesList = "resources/templates/list"
+)
+
+type JsonRpcRequestResourcesTemplatesListParams struct {
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+func ParseJsonRpcRequestResourcesTemplatesList(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestResourcesTemplatesListParams, error) {
+	resp := &JsonRpcRequestResourcesTemplatesListParams{}
+
+	// check if we have params
+	if params != nil {
+		if !params.IsNamed() {
+			return nil, fmt.Errorf("invalid call parameters, not an object")
+		}
+		cursor := protocol.GetOptionalStringField(params.NamedParams, "cursor")
+		resp.Cursor = cursor
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+const (
+	RpcRequestMethodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+type JsonRpcRequestResourcesUnsubscribeParams struct {
+	Uri string `json:"uri"`
+}
+
+func ParseJsonRpcRequestResourcesUnsubscribe(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestResourcesUnsubscribeParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, no parameters provided")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+
+	uri, err := protocol.GetStringField(params.NamedParams, "uri")
+	if err != nil {
+		return nil, fmt.Errorf("invalid call parameters, uri is not a string")
+	}
+
+	return &JsonRpcRequestResourcesUnsubscribeParams{
+		Uri: uri,
+	}, nil
+}
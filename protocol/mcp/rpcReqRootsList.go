@@ -0,0 +1,12 @@
+package mcp
+
+// specification
+// https://modelcontextprotocol.io/specification/2025-06-18/client/roots
+
+// this request is sent by the server to the client
+const (
+	RpcRequestMethodRootsList = "roots/list"
+)
+
+type JsonRpcRequestRootsListParams struct {
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// specification
+// https://modelcontextprotocol.io/specification/2025-06-18/client/sampling
+
+// this request is sent by the server to the client
+const (
+	RpcRequestMethodSamplingCreateMessage = "sampling/createMessage"
+)
+
+type JsonRpcRequestSamplingCreateMessageParams struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     *string           `json:"systemPrompt,omitempty"`
+	// "none", "thisServer" or "allServers"
+	IncludeContext *string                `json:"includeContext,omitempty"`
+	Temperature    *float64               `json:"temperature,omitempty"`
+	MaxTokens      int                    `json:"maxTokens"`
+	StopSequences  []string               `json:"stopSequences,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type SamplingMessage struct {
+	Role    string          `json:"role"` // "user" or "assistant"
+	Content SamplingContent `json:"content"`
+}
+
+// text, image or audio content
+type SamplingContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         *float64    `json:"costPriority,omitempty"`
+	SpeedPriority        *float64    `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"`
+}
+
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+func ParseJsonRpcRequestSamplingCreateMessage(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestSamplingCreateMessageParams, error) {
+	if params == nil {
+		return nil, fmt.Errorf("invalid call parameters, not an object")
+	}
+	if !params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := params.NamedParams
+
+	req := JsonRpcRequestSamplingCreateMessageParams{
+		SystemPrompt:   protocol.GetOptionalStringField(namedParams, "systemPrompt"),
+		IncludeContext: protocol.GetOptionalStringField(namedParams, "includeContext"),
+		Temperature:    protocol.GetOptionalNumberField(namedParams, "temperature"),
+		Metadata:       protocol.GetOptionalObjectField(namedParams, "metadata"),
+	}
+
+	messages, err := protocol.GetArrayField(namedParams, "messages")
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		messageMap, err := protocol.CheckIsObject(message, "message")
+		if err != nil {
+			return nil, err
+		}
+		role, err := protocol.GetStringField(messageMap, "role")
+		if err != nil {
+			return nil, err
+		}
+		contentMap, err := protocol.GetObjectField(messageMap, "content")
+		if err != nil {
+			return nil, err
+		}
+		content, err := parseSamplingContent(contentMap)
+		if err != nil {
+			return nil, err
+		}
+		req.Messages = append(req.Messages, SamplingMessage{
+			Role:    role,
+			Content: *content,
+		})
+	}
+
+	maxTokens, err := protocol.GetNumberField(namedParams, "maxTokens")
+	if err != nil {
+		return nil, err
+	}
+	req.MaxTokens = int(maxTokens)
+
+	for _, stopSequence := range protocol.GetOptionalArrayField(namedParams, "stopSequences") {
+		stopSequenceStr, ok := stopSequence.(string)
+		if !ok {
+			return nil, fmt.Errorf("stopSequences must be an array of strings")
+		}
+		req.StopSequences = append(req.StopSequences, stopSequenceStr)
+	}
+
+	modelPreferences := protocol.GetOptionalObjectField(namedParams, "modelPreferences")
+	if modelPreferences != nil {
+		req.ModelPreferences = &ModelPreferences{
+			CostPriority:         protocol.GetOptionalNumberField(modelPreferences, "costPriority"),
+			SpeedPriority:        protocol.GetOptionalNumberField(modelPreferences, "speedPriority"),
+			IntelligencePriority: protocol.GetOptionalNumberField(modelPreferences, "intelligencePriority"),
+		}
+		for _, hint := range protocol.GetOptionalArrayField(modelPreferences, "hints") {
+			hintMap, err := protocol.CheckIsObject(hint, "hint")
+			if err != nil {
+				return nil, err
+			}
+			name := protocol.GetOptionalStringField(hintMap, "name")
+			if name != nil {
+				req.ModelPreferences.Hints = append(req.ModelPreferences.Hints, ModelHint{Name: *name})
+			}
+		}
+	}
+
+	return &req, nil
+}
+
+func parseSamplingContent(content map[string]interface{}) (*SamplingContent, error) {
+	contentType, err := protocol.GetStringField(content, "type")
+	if err != nil {
+		return nil, err
+	}
+	samplingContent := &SamplingContent{Type: contentType}
+	switch contentType {
+	case "text":
+		samplingContent.Text, err = protocol.GetStringField(content, "text")
+		if err != nil {
+			return nil, err
+		}
+	case "image", "audio":
+		samplingContent.Data, err = protocol.GetStringField(content, "data")
+		if err != nil {
+			return nil, err
+		}
+		samplingContent.MimeType, err = protocol.GetStringField(content, "mimeType")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", contentType)
+	}
+	return samplingContent, nil
+}
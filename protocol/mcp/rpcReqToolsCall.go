@@ -14,6 +14,7 @@ const (
 type JsonRpcRequestToolsCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
 }
 
 func ParseJsonRpcRequestToolsCallParams(params *jsonrpc.JsonRpcParams) (*JsonRpcRequestToolsCallParams, error) {
@@ -41,5 +42,12 @@ func ParseJsonRpcRequestToolsCallParams(params *jsonrpc.JsonRpcParams) (*JsonRpc
 	}
 	toolCall.Arguments = arguments
 
+	// check if _meta is present
+	meta, err := parseRequestMeta(namedParams)
+	if err != nil {
+		return nil, err
+	}
+	toolCall.Meta = meta
+
 	return toolCall, nil
 }
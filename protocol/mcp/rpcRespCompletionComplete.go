@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// maximum number of values in a completion result
+const CompletionMaxValues = 100
+
+type JsonRpcResponseCompletionCompleteResult struct {
+	Completion CompletionResult `json:"completion"`
+}
+
+type CompletionResult struct {
+	Values []string `json:"values"`
+	// total number of values available, can be more than the values sent
+	Total   *int  `json:"total,omitempty"`
+	HasMore *bool `json:"hasMore,omitempty"`
+}
+
+// NewCompletionResult truncates the values to the maximum allowed
+func NewCompletionResult(values []string) JsonRpcResponseCompletionCompleteResult {
+	total := len(values)
+	hasMore := total > CompletionMaxValues
+	if hasMore {
+		values = values[:CompletionMaxValues]
+	}
+	if values == nil {
+		values = []string{}
+	}
+	return JsonRpcResponseCompletionCompleteResult{
+		Completion: CompletionResult{
+			Values:  values,
+			Total:   &total,
+			HasMore: &hasMore,
+		},
+	}
+}
+
+// ParseJsonRpcResponseCompletionComplete reads the completion of an MCP server, eg behind a proxy
+func ParseJsonRpcResponseCompletionComplete(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseCompletionCompleteResult, error) {
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+	completion, err := protocol.GetObjectField(result, "completion")
+	if err != nil {
+		return nil, err
+	}
+	values, err := protocol.GetArrayField(completion, "values")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := JsonRpcResponseCompletionCompleteResult{
+		Completion: CompletionResult{
+			Values:  make([]string, 0, len(values)),
+			HasMore: protocol.GetOptionalBoolField(completion, "hasMore"),
+		},
+	}
+	for _, value := range values {
+		value, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("completion values must be strings")
+		}
+		resp.Completion.Values = append(resp.Completion.Values, value)
+	}
+	if total := protocol.GetOptionalNumberField(completion, "total"); total != nil {
+		count := int(*total)
+		resp.Completion.Total = &count
+	}
+	return &resp, nil
+}
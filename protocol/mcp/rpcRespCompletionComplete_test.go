@@ -0,0 +1,72 @@
+package mcp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestParseJsonRpcResponseCompletionComplete(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     interface{}
+		wantErr    bool
+		wantValues []string
+		wantTotal  int
+	}{
+		{
+			name: "values and total",
+			result: map[string]interface{}{
+				"completion": map[string]interface{}{"values": []interface{}{"dev", "prod"}, "total": float64(3), "hasMore": true},
+			},
+			wantValues: []string{"dev", "prod"},
+			wantTotal:  3,
+		},
+		{
+			name: "values only",
+			result: map[string]interface{}{
+				"completion": map[string]interface{}{"values": []interface{}{}},
+			},
+			wantValues: []string{},
+		},
+		{
+			name:    "missing completion",
+			result:  map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name: "value not a string",
+			result: map[string]interface{}{
+				"completion": map[string]interface{}{"values": []interface{}{1}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mcp.ParseJsonRpcResponseCompletionComplete(&jsonrpc.JsonRpcResponse{Result: tt.result})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Completion.Values, tt.wantValues) {
+				t.Errorf("expected values %v, got %v", tt.wantValues, result.Completion.Values)
+			}
+			total := 0
+			if result.Completion.Total != nil {
+				total = *result.Completion.Total
+			}
+			if total != tt.wantTotal {
+				t.Errorf("expected total %d, got %d", tt.wantTotal, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+type ElicitationAction string
+
+const (
+	// the user submitted the form
+	ElicitationActionAccept ElicitationAction = "accept"
+	// the user explicitly declined the request
+	ElicitationActionDecline ElicitationAction = "decline"
+	// the user dismissed the request without making a choice
+	ElicitationActionCancel ElicitationAction = "cancel"
+)
+
+type JsonRpcResponseElicitationCreateResult struct {
+	Action ElicitationAction `json:"action"`
+	// the submitted data, only set when the action is "accept"
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+func ParseJsonRpcResponseElicitationCreate(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseElicitationCreateResult, error) {
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := protocol.GetStringField(result, "action")
+	if err != nil {
+		return nil, err
+	}
+	resp := JsonRpcResponseElicitationCreateResult{
+		Action: ElicitationAction(action),
+	}
+	switch resp.Action {
+	case ElicitationActionAccept:
+		resp.Content = protocol.GetOptionalObjectField(result, "content")
+		if resp.Content == nil {
+			// the form may have no field
+			resp.Content = map[string]interface{}{}
+		}
+	case ElicitationActionDecline, ElicitationActionCancel:
+	default:
+		return nil, fmt.Errorf("invalid elicitation action: %s", action)
+	}
+
+	return &resp, nil
+}
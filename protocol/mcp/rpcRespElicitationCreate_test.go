@@ -0,0 +1,71 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestParseJsonRpcResponseElicitationCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      interface{}
+		wantErr     bool
+		wantAction  mcp.ElicitationAction
+		wantContent bool
+	}{
+		{
+			name: "accept",
+			result: map[string]interface{}{
+				"action":  "accept",
+				"content": map[string]interface{}{"workspace": "dev"},
+			},
+			wantAction:  mcp.ElicitationActionAccept,
+			wantContent: true,
+		},
+		{
+			name:       "decline",
+			result:     map[string]interface{}{"action": "decline"},
+			wantAction: mcp.ElicitationActionDecline,
+		},
+		{
+			name:       "cancel",
+			result:     map[string]interface{}{"action": "cancel"},
+			wantAction: mcp.ElicitationActionCancel,
+		},
+		{
+			name:    "unknown action",
+			result:  map[string]interface{}{"action": "maybe"},
+			wantErr: true,
+		},
+		{
+			name:    "missing action",
+			result:  map[string]interface{}{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mcp.ParseJsonRpcResponseElicitationCreate(&jsonrpc.JsonRpcResponse{
+				Result: tt.result,
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("expected action %s, got %s", tt.wantAction, result.Action)
+			}
+			if (result.Content != nil) != tt.wantContent {
+				t.Errorf("expected content %v, got %v", tt.wantContent, result.Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package mcp
+
+// result of the requests that don't return anything
+// eg "resources/subscribe"
+type JsonRpcResponseEmptyResult struct{}
@@ -17,10 +17,11 @@ type ServerInfo struct {
 }
 
 type ServerCapabilities struct {
-	Tools     *ServerCapabilitiesTools     `json:"tools,omitempty"`
-	Prompts   *ServerCapabilitiesPrompts   `json:"prompts,omitempty"`
-	Logging   *ServerCapabilitiesLogging   `json:"logging,omitempty"`
-	Resources *ServerCapabilitiesResources `json:"resources,omitempty"`
+	Tools       *ServerCapabilitiesTools       `json:"tools,omitempty"`
+	Prompts     *ServerCapabilitiesPrompts     `json:"prompts,omitempty"`
+	Logging     *ServerCapabilitiesLogging     `json:"logging,omitempty"`
+	Resources   *ServerCapabilitiesResources   `json:"resources,omitempty"`
+	Completions *ServerCapabilitiesCompletions `json:"completions,omitempty"`
 }
 
 type ServerCapabilitiesTools struct {
@@ -34,6 +35,9 @@ type ServerCapabilitiesPrompts struct {
 type ServerCapabilitiesLogging struct {
 }
 
+type ServerCapabilitiesCompletions struct {
+}
+
 type ServerCapabilitiesResources struct {
 	ListChanged *bool `json:"listChanged,omitempty"`
 	Subscribe   *bool `json:"subscribe,omitempty"`
@@ -75,7 +79,7 @@ func ParseJsonRpcResponseInitialize(response *jsonrpc.JsonRpcResponse) (*JsonRpc
 	resp.ServerInfo.Version = version
 
 	// read capabilities
-	capabilities, err := protocol.CheckIsObject(result, "capabilities")
+	capabilities, err := protocol.GetObjectField(result, "capabilities")
 	if err != nil {
 		return nil, err
 	}
@@ -136,5 +140,10 @@ func ParseJsonRpcResponseInitialize(response *jsonrpc.JsonRpcResponse) (*JsonRpc
 		resp.Capabilities.Logging = nil
 	}
 
+	// check if completions capability is present
+	if protocol.GetOptionalObjectField(capabilities, "completions") != nil {
+		resp.Capabilities.Completions = &ServerCapabilitiesCompletions{}
+	}
+
 	return &resp, nil
 }
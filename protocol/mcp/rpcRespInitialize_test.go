@@ -0,0 +1,43 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestParseJsonRpcResponseInitializeCapabilities(t *testing.T) {
+	result, err := mcp.ParseJsonRpcResponseInitialize(&jsonrpc.JsonRpcResponse{Result: map[string]interface{}{
+		"protocolVersion": mcp.ProtocolVersion,
+		"capabilities": map[string]interface{}{
+			"prompts":     map[string]interface{}{"listChanged": true},
+			"completions": map[string]interface{}{},
+			"logging":     map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{"name": "server", "version": "1.0"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Capabilities.Prompts == nil || result.Capabilities.Prompts.ListChanged == nil || !*result.Capabilities.Prompts.ListChanged {
+		t.Errorf("expected the prompts capability with listChanged, got %+v", result.Capabilities.Prompts)
+	}
+	if result.Capabilities.Completions == nil {
+		t.Errorf("expected the completions capability")
+	}
+	if result.Capabilities.Logging == nil {
+		t.Errorf("expected the logging capability")
+	}
+	if result.Capabilities.Tools != nil || result.Capabilities.Resources != nil {
+		t.Errorf("expected no tools and resources capabilities, got %+v", result.Capabilities)
+	}
+
+	// the capabilities are required
+	if _, err := mcp.ParseJsonRpcResponseInitialize(&jsonrpc.JsonRpcResponse{Result: map[string]interface{}{
+		"protocolVersion": mcp.ProtocolVersion,
+		"serverInfo":      map[string]interface{}{"name": "server", "version": "1.0"},
+	}}); err == nil {
+		t.Errorf("expected an error without capabilities")
+	}
+}
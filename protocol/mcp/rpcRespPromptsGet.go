@@ -1,11 +1,66 @@
 package mcp
 
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
 type JsonRpcResponsePromptsGetResult struct {
 	Description string          `json:"description"`
 	Messages    []PromptMessage `json:"messages"`
 }
 
 type PromptMessage struct {
-	Role    string `json:"role"` // "user" or "assistant"
-	Content string `json:"content"`
+	Role string `json:"role"` // "user" or "assistant"
+	// a content block (text, image, audio, resource or resource_link)
+	Content interface{} `json:"content"`
+}
+
+func ParseJsonRpcResponsePromptsGet(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponsePromptsGetResult, error) {
+	resp := JsonRpcResponsePromptsGetResult{
+		Messages: []PromptMessage{},
+	}
+
+	// parse params
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	// the description is optional
+	if description := protocol.GetOptionalStringField(result, "description"); description != nil {
+		resp.Description = *description
+	}
+
+	// read messages
+	messages, err := protocol.GetArrayField(result, "messages")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range messages {
+		message, err := protocol.CheckIsObject(item, "message")
+		if err != nil {
+			return nil, err
+		}
+		role, err := protocol.GetStringField(message, "role")
+		if err != nil {
+			return nil, err
+		}
+		if role != "user" && role != "assistant" {
+			return nil, fmt.Errorf("invalid role %s", role)
+		}
+		content, err := protocol.GetObjectField(message, "content")
+		if err != nil {
+			return nil, err
+		}
+		resp.Messages = append(resp.Messages, PromptMessage{
+			Role:    role,
+			Content: content,
+		})
+	}
+
+	return &resp, nil
 }
@@ -47,15 +47,12 @@ func ParseJsonRpcResponsePromptsList(response *jsonrpc.JsonRpcResponse) (*JsonRp
 			return nil, err
 		}
 
-		description, err := protocol.GetStringField(prompt, "description")
-		if err != nil {
-			return nil, err
-		}
-
-		arguments, err := protocol.GetArrayField(prompt, "arguments")
-		if err != nil {
-			return nil, err
+		// the description and the arguments are optional
+		description := ""
+		if value := protocol.GetOptionalStringField(prompt, "description"); value != nil {
+			description = *value
 		}
+		arguments := protocol.GetOptionalArrayField(prompt, "arguments")
 
 		promptArguments := make([]PromptArgumentDescription, 0)
 		for _, argument := range arguments {
@@ -67,19 +64,16 @@ func ParseJsonRpcResponsePromptsList(response *jsonrpc.JsonRpcResponse) (*JsonRp
 			if err != nil {
 				return nil, err
 			}
-			description, err := protocol.GetStringField(argument, "description")
-			if err != nil {
-				return nil, err
+			promptArgument := PromptArgumentDescription{
+				Name: name,
 			}
-			required, err := protocol.GetBoolField(argument, "required")
-			if err != nil {
-				return nil, err
+			if description := protocol.GetOptionalStringField(argument, "description"); description != nil {
+				promptArgument.Description = *description
+			}
+			if required := protocol.GetOptionalBoolField(argument, "required"); required != nil {
+				promptArgument.Required = *required
 			}
-			promptArguments = append(promptArguments, PromptArgumentDescription{
-				Name:        name,
-				Description: description,
-				Required:    required,
-			})
+			promptArguments = append(promptArguments, promptArgument)
 		}
 
 		resp.Prompts = append(resp.Prompts, PromptDescription{
@@ -1,11 +1,73 @@
 package mcp
 
+import (
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
 type JsonRpcResponseResourcesListResult struct {
-	Resources []ResourceDescription `json:"resources"`
+	Resources  []ResourceDescription `json:"resources"`
+	NextCursor *string               `json:"nextCursor,omitempty"`
 }
+
 type ResourceDescription struct {
 	Uri         string `json:"uri"`
 	Name        string `json:"name"`
-	Description string `json:"description"`
-	MimeType    string `json:"mimeType"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+func ParseJsonRpcResponseResourcesList(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseResourcesListResult, error) {
+	resp := JsonRpcResponseResourcesListResult{
+		Resources: []ResourceDescription{},
+	}
+
+	// parse params
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	// read resources
+	resources, err := protocol.GetArrayField(result, "resources")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range resources {
+		resource, err := protocol.CheckIsObject(item, "resource")
+		if err != nil {
+			return nil, err
+		}
+		uri, err := protocol.GetStringField(resource, "uri")
+		if err != nil {
+			return nil, err
+		}
+		name, err := protocol.GetStringField(resource, "name")
+		if err != nil {
+			return nil, err
+		}
+
+		description := ""
+		if value := protocol.GetOptionalStringField(resource, "description"); value != nil {
+			description = *value
+		}
+		mimeType := ""
+		if value := protocol.GetOptionalStringField(resource, "mimeType"); value != nil {
+			mimeType = *value
+		}
+
+		resp.Resources = append(resp.Resources, ResourceDescription{
+			Uri:         uri,
+			Name:        name,
+			Description: description,
+			MimeType:    mimeType,
+		})
+	}
+
+	// read next cursor
+	nextCursor := protocol.GetOptionalStringField(result, "nextCursor")
+	resp.NextCursor = nextCursor
+
+	return &resp, nil
 }
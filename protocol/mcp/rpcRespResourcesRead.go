@@ -0,0 +1,28 @@
+package mcp
+
+import (
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+type JsonRpcResponseResourcesReadResult struct {
+	Contents []interface{} `json:"contents"`
+}
+
+func ParseJsonRpcResponseResourcesRead(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseResourcesReadResult, error) {
+	// parse params
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	// read contents
+	contents, err := protocol.GetArrayField(result, "contents")
+	if err != nil {
+		return nil, err
+	}
+
+	return &JsonRpcResponseResourcesReadResult{
+		Contents: contents,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+type JsonRpcResponseResourcesTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplateDescription `json:"resourceTemplates"`
+	NextCursor        *string                       `json:"nextCursor,omitempty"`
+}
+
+type ResourceTemplateDescription struct {
+	UriTemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+func ParseJsonRpcResponseResourcesTemplatesList(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseResourcesTemplatesListResult, error) {
+	resp := JsonRpcResponseResourcesTemplatesListResult{
+		ResourceTemplates: []ResourceTemplateDescription{},
+	}
+
+	// parse params
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	// read resource templates
+	templates, err := protocol.GetArrayField(result, "resourceTemplates")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range templates {
+		template, err := protocol.CheckIsObject(item, "resourceTemplate")
+		if err != nil {
+			return nil, err
+		}
+		uriTemplate, err := protocol.GetStringField(template, "uriTemplate")
+		if err != nil {
+			return nil, err
+		}
+		name, err := protocol.GetStringField(template, "name")
+		if err != nil {
+			return nil, err
+		}
+
+		description := ""
+		if value := protocol.GetOptionalStringField(template, "description"); value != nil {
+			description = *value
+		}
+		mimeType := ""
+		if value := protocol.GetOptionalStringField(template, "mimeType"); value != nil {
+			mimeType = *value
+		}
+
+		resp.ResourceTemplates = append(resp.ResourceTemplates, ResourceTemplateDescription{
+			UriTemplate: uriTemplate,
+			Name:        name,
+			Description: description,
+			MimeType:    mimeType,
+		})
+	}
+
+	// read next cursor
+	nextCursor := protocol.GetOptionalStringField(result, "nextCursor")
+	resp.NextCursor = nextCursor
+
+	return &resp, nil
+}
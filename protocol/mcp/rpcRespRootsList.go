@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+type JsonRpcResponseRootsListResult struct {
+	Roots []Root `json:"roots"`
+}
+
+// a directory or a file the server can operate on
+type Root struct {
+	// file:// uri
+	Uri  string  `json:"uri"`
+	Name *string `json:"name,omitempty"`
+}
+
+func ParseJsonRpcResponseRootsList(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseRootsListResult, error) {
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := protocol.GetArrayField(result, "roots")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := JsonRpcResponseRootsListResult{
+		Roots: make([]Root, 0, len(roots)),
+	}
+	for _, item := range roots {
+		root, err := protocol.CheckIsObject(item, "root")
+		if err != nil {
+			return nil, err
+		}
+		uri, err := protocol.GetStringField(root, "uri")
+		if err != nil {
+			return nil, err
+		}
+		resp.Roots = append(resp.Roots, Root{
+			Uri:  uri,
+			Name: protocol.GetOptionalStringField(root, "name"),
+		})
+	}
+
+	return &resp, nil
+}
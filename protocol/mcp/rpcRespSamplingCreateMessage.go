@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+type JsonRpcResponseSamplingCreateMessageResult struct {
+	Role    string          `json:"role"`
+	Content SamplingContent `json:"content"`
+	// name of the model used by the client
+	Model string `json:"model"`
+	// "endTurn", "stopSequence", "maxTokens" or any other reason
+	StopReason *string `json:"stopReason,omitempty"`
+}
+
+func ParseJsonRpcResponseSamplingCreateMessage(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseSamplingCreateMessageResult, error) {
+	result, err := protocol.CheckIsObject(response.Result, "result")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := JsonRpcResponseSamplingCreateMessageResult{
+		StopReason: protocol.GetOptionalStringField(result, "stopReason"),
+	}
+
+	resp.Role, err = protocol.GetStringField(result, "role")
+	if err != nil {
+		return nil, err
+	}
+	resp.Model, err = protocol.GetStringField(result, "model")
+	if err != nil {
+		return nil, err
+	}
+
+	contentMap, err := protocol.GetObjectField(result, "content")
+	if err != nil {
+		return nil, err
+	}
+	content, err := parseSamplingContent(contentMap)
+	if err != nil {
+		return nil, err
+	}
+	resp.Content = *content
+
+	return &resp, nil
+}
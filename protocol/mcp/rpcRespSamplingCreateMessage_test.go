@@ -0,0 +1,84 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestParseJsonRpcResponseSamplingCreateMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    interface{}
+		wantErr   bool
+		wantModel string
+		wantText  string
+	}{
+		{
+			name: "text content",
+			result: map[string]interface{}{
+				"role":       "assistant",
+				"content":    map[string]interface{}{"type": "text", "text": "hello"},
+				"model":      "model-1",
+				"stopReason": "endTurn",
+			},
+			wantModel: "model-1",
+			wantText:  "hello",
+		},
+		{
+			name: "image content",
+			result: map[string]interface{}{
+				"role":    "assistant",
+				"content": map[string]interface{}{"type": "image", "data": "aGVsbG8=", "mimeType": "image/png"},
+				"model":   "model-2",
+			},
+			wantModel: "model-2",
+		},
+		{
+			name: "missing model",
+			result: map[string]interface{}{
+				"role":    "assistant",
+				"content": map[string]interface{}{"type": "text", "text": "hello"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported content type",
+			result: map[string]interface{}{
+				"role":    "assistant",
+				"content": map[string]interface{}{"type": "video"},
+				"model":   "model-1",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			result:  "hello",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mcp.ParseJsonRpcResponseSamplingCreateMessage(&jsonrpc.JsonRpcResponse{
+				Result: tt.result,
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Model != tt.wantModel {
+				t.Errorf("expected model %s, got %s", tt.wantModel, result.Model)
+			}
+			if result.Content.Text != tt.wantText {
+				t.Errorf("expected text %s, got %s", tt.wantText, result.Content.Text)
+			}
+		})
+	}
+}
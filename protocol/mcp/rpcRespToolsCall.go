@@ -6,8 +6,9 @@ import (
 )
 
 type JsonRpcResponseToolsCallResult struct {
-	Content []interface{} `json:"content"`
-	IsError *bool         `json:"isError,omitempty"`
+	Content           []interface{} `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           *bool         `json:"isError,omitempty"`
 }
 
 func ParseJsonRpcResponseToolsCall(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseToolsCallResult, error) {
@@ -30,6 +31,11 @@ func ParseJsonRpcResponseToolsCall(response *jsonrpc.JsonRpcResponse) (*JsonRpcR
 
 	resp.Content = content
 
+	// the structured content is optional (added in 2025-06-18)
+	if structuredContent := protocol.GetOptionalObjectField(result, "structuredContent"); structuredContent != nil {
+		resp.StructuredContent = structuredContent
+	}
+
 	isError := protocol.GetOptionalBoolField(result, "isError")
 	if isError != nil {
 		resp.IsError = isError
@@ -11,9 +11,21 @@ type JsonRpcResponseToolsListResult struct {
 }
 
 type ToolDescription struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema interface{} `json:"inputSchema"`
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description"`
+	InputSchema  interface{}      `json:"inputSchema"`
+	OutputSchema interface{}      `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about the behavior of a tool (added in 2025-03-26)
+type ToolAnnotations struct {
+	Title           *string `json:"title,omitempty"`
+	ReadOnlyHint    *bool   `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool   `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool   `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool   `json:"openWorldHint,omitempty"`
 }
 
 func ParseJsonRpcResponseToolsList(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseToolsListResult, error) {
@@ -51,11 +63,34 @@ func ParseJsonRpcResponseToolsList(response *jsonrpc.JsonRpcResponse) (*JsonRpcR
 			return nil, err
 		}
 
-		resp.Tools = append(resp.Tools, ToolDescription{
+		// title and annotations are optional
+		title := ""
+		if optionalTitle := protocol.GetOptionalStringField(tool, "title"); optionalTitle != nil {
+			title = *optionalTitle
+		}
+		var annotations *ToolAnnotations
+		if annotationsObject := protocol.GetOptionalObjectField(tool, "annotations"); annotationsObject != nil {
+			annotations = &ToolAnnotations{
+				Title:           protocol.GetOptionalStringField(annotationsObject, "title"),
+				ReadOnlyHint:    protocol.GetOptionalBoolField(annotationsObject, "readOnlyHint"),
+				DestructiveHint: protocol.GetOptionalBoolField(annotationsObject, "destructiveHint"),
+				IdempotentHint:  protocol.GetOptionalBoolField(annotationsObject, "idempotentHint"),
+				OpenWorldHint:   protocol.GetOptionalBoolField(annotationsObject, "openWorldHint"),
+			}
+		}
+
+		toolDescription := ToolDescription{
 			Name:        name,
+			Title:       title,
 			Description: description,
 			InputSchema: inputSchema,
-		})
+			Annotations: annotations,
+		}
+		// the output schema is optional (added in 2025-06-18)
+		if outputSchema := protocol.GetOptionalObjectField(tool, "outputSchema"); outputSchema != nil {
+			toolDescription.OutputSchema = outputSchema
+		}
+		resp.Tools = append(resp.Tools, toolDescription)
 	}
 
 	// read next cursor
@@ -0,0 +1,68 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestParseJsonRpcResponseToolsList(t *testing.T) {
+	tests := []struct {
+		name            string
+		tool            map[string]interface{}
+		wantTitle       string
+		wantAnnotations bool
+		wantReadOnly    *bool
+	}{
+		{
+			name: "no title or annotations",
+			tool: map[string]interface{}{
+				"name":        "echo",
+				"description": "echo tool",
+				"inputSchema": map[string]interface{}{"type": "object"},
+			},
+		},
+		{
+			name: "title and annotations",
+			tool: map[string]interface{}{
+				"name":        "echo",
+				"title":       "Echo",
+				"description": "echo tool",
+				"inputSchema": map[string]interface{}{"type": "object"},
+				"annotations": map[string]interface{}{
+					"readOnlyHint": true,
+				},
+			},
+			wantTitle:       "Echo",
+			wantAnnotations: true,
+			wantReadOnly:    jsonrpc.BoolPtr(true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mcp.ParseJsonRpcResponseToolsList(&jsonrpc.JsonRpcResponse{
+				Result: map[string]interface{}{
+					"tools": []interface{}{tt.tool},
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Tools) != 1 {
+				t.Fatalf("expected 1 tool, got %d", len(result.Tools))
+			}
+			tool := result.Tools[0]
+			if tool.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, tool.Title)
+			}
+			if (tool.Annotations != nil) != tt.wantAnnotations {
+				t.Fatalf("expected annotations %v, got %+v", tt.wantAnnotations, tool.Annotations)
+			}
+			if tt.wantReadOnly != nil && (tool.Annotations.ReadOnlyHint == nil || *tool.Annotations.ReadOnlyHint != *tt.wantReadOnly) {
+				t.Errorf("expected readOnlyHint %v, got %v", *tt.wantReadOnly, tool.Annotations.ReadOnlyHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+// revisions of the MCP specification
+// https://modelcontextprotocol.io/specification/versioning
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+)
+
+// ProtocolFeature is a behavior that depends on the negotiated protocol version
+type ProtocolFeature string
+
+const (
+	// JSON-RPC batches (added in 2025-03-26, removed in 2025-06-18)
+	FeatureJsonRpcBatch ProtocolFeature = "jsonRpcBatch"
+	// readOnlyHint, destructiveHint, ... on tools
+	FeatureToolAnnotations ProtocolFeature = "toolAnnotations"
+	// audio content in tool results and prompts
+	FeatureAudioContent ProtocolFeature = "audioContent"
+	// "completion/complete" request
+	FeatureCompletions ProtocolFeature = "completions"
+	// message field in progress notifications
+	FeatureProgressMessage ProtocolFeature = "progressMessage"
+	// title field on tools, prompts and resources
+	FeatureTitle ProtocolFeature = "title"
+	// outputSchema and structuredContent on tools
+	FeatureStructuredOutput ProtocolFeature = "structuredOutput"
+	// resource_link content in tool results and prompts
+	FeatureResourceLinks ProtocolFeature = "resourceLinks"
+	// "elicitation/create" request
+	FeatureElicitation ProtocolFeature = "elicitation"
+)
+
+type protocolRevision struct {
+	version  string
+	features []ProtocolFeature
+}
+
+// supported revisions, from the oldest to the latest
+var supportedProtocolRevisions = []protocolRevision{
+	{
+		version:  ProtocolVersion20241105,
+		features: []ProtocolFeature{},
+	},
+	{
+		version: ProtocolVersion20250326,
+		features: []ProtocolFeature{
+			FeatureJsonRpcBatch,
+			FeatureToolAnnotations,
+			FeatureAudioContent,
+			FeatureCompletions,
+			FeatureProgressMessage,
+		},
+	},
+	{
+		version: ProtocolVersion20250618,
+		features: []ProtocolFeature{
+			FeatureToolAnnotations,
+			FeatureAudioContent,
+			FeatureCompletions,
+			FeatureProgressMessage,
+			FeatureTitle,
+			FeatureStructuredOutput,
+			FeatureResourceLinks,
+			FeatureElicitation,
+		},
+	},
+}
+
+func findProtocolRevision(version string) *protocolRevision {
+	for ix := range supportedProtocolRevisions {
+		if supportedProtocolRevisions[ix].version == version {
+			return &supportedProtocolRevisions[ix]
+		}
+	}
+	return nil
+}
+
+// SupportedProtocolVersions returns the supported versions, from the oldest to the latest
+func SupportedProtocolVersions() []string {
+	versions := make([]string, 0, len(supportedProtocolRevisions))
+	for _, revision := range supportedProtocolRevisions {
+		versions = append(versions, revision.version)
+	}
+	return versions
+}
+
+func IsSupportedProtocolVersion(version string) bool {
+	return findProtocolRevision(version) != nil
+}
+
+// NegotiateProtocolVersion returns the version to answer to an initialize request:
+// the version requested by the client if we support it, otherwise our latest version
+// and the client decides if it can use it
+func NegotiateProtocolVersion(requestedVersion string) string {
+	if IsSupportedProtocolVersion(requestedVersion) {
+		return requestedVersion
+	}
+	return supportedProtocolRevisions[len(supportedProtocolRevisions)-1].version
+}
+
+// SupportsFeature checks if a feature is available with the negotiated version
+func SupportsFeature(version string, feature ProtocolFeature) bool {
+	revision := findProtocolRevision(version)
+	if revision == nil {
+		return false
+	}
+	for _, revisionFeature := range revision.features {
+		if revisionFeature == feature {
+			return true
+		}
+	}
+	return false
+}
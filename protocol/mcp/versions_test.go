@@ -0,0 +1,109 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestedVersion string
+		wantVersion      string
+	}{
+		{
+			name:             "oldest version",
+			requestedVersion: "2024-11-05",
+			wantVersion:      "2024-11-05",
+		},
+		{
+			name:             "intermediate version",
+			requestedVersion: "2025-03-26",
+			wantVersion:      "2025-03-26",
+		},
+		{
+			name:             "latest version",
+			requestedVersion: "2025-06-18",
+			wantVersion:      "2025-06-18",
+		},
+		{
+			name:             "unknown future version",
+			requestedVersion: "2099-01-01",
+			wantVersion:      "2025-06-18",
+		},
+		{
+			name:             "empty version",
+			requestedVersion: "",
+			wantVersion:      "2025-06-18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mcp.NegotiateProtocolVersion(tt.requestedVersion); got != tt.wantVersion {
+				t.Errorf("NegotiateProtocolVersion() = %v, want %v", got, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSupportsFeature(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		feature mcp.ProtocolFeature
+		want    bool
+	}{
+		{
+			name:    "no batch in 2024-11-05",
+			version: mcp.ProtocolVersion20241105,
+			feature: mcp.FeatureJsonRpcBatch,
+			want:    false,
+		},
+		{
+			name:    "batch in 2025-03-26",
+			version: mcp.ProtocolVersion20250326,
+			feature: mcp.FeatureJsonRpcBatch,
+			want:    true,
+		},
+		{
+			name:    "batch removed in 2025-06-18",
+			version: mcp.ProtocolVersion20250618,
+			feature: mcp.FeatureJsonRpcBatch,
+			want:    false,
+		},
+		{
+			name:    "annotations in 2025-03-26",
+			version: mcp.ProtocolVersion20250326,
+			feature: mcp.FeatureToolAnnotations,
+			want:    true,
+		},
+		{
+			name:    "no structured output in 2025-03-26",
+			version: mcp.ProtocolVersion20250326,
+			feature: mcp.FeatureStructuredOutput,
+			want:    false,
+		},
+		{
+			name:    "structured output in 2025-06-18",
+			version: mcp.ProtocolVersion20250618,
+			feature: mcp.FeatureStructuredOutput,
+			want:    true,
+		},
+		{
+			name:    "unknown version",
+			version: "2099-01-01",
+			feature: mcp.FeatureToolAnnotations,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mcp.SupportsFeature(tt.version, tt.feature); got != tt.want {
+				t.Errorf("SupportsFeature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
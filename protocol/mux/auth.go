@@ -0,0 +1,45 @@
+package mux
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// ProxyAuthentication proves that the proxy has the key of the hub
+// without sending it: an HMAC-SHA256 of the proxy id, a nonce and a timestamp
+type ProxyAuthentication struct {
+	Nonce     string `json:"nonce"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+// SignProxyRegistration returns the hex encoded HMAC-SHA256 of the registration
+func SignProxyRegistration(key []byte, proxyId string, nonce string, timestamp int64) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(proxyId + "\n" + nonce + "\n" + strconv.FormatInt(timestamp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewProxyAuthentication signs the registration of a proxy with a random nonce
+func NewProxyAuthentication(key []byte, proxyId string) (*ProxyAuthentication, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	auth := ProxyAuthentication{
+		Nonce:     hex.EncodeToString(nonce),
+		Timestamp: time.Now().Unix(),
+	}
+	auth.Signature = SignProxyRegistration(key, proxyId, auth.Nonce, auth.Timestamp)
+	return &auth, nil
+}
+
+// VerifyProxyRegistration checks the signature of the registration in constant time
+func VerifyProxyRegistration(key []byte, proxyId string, auth *ProxyAuthentication) bool {
+	expected := SignProxyRegistration(key, proxyId, auth.Nonce, auth.Timestamp)
+	return hmac.Equal([]byte(expected), []byte(auth.Signature))
+}
@@ -0,0 +1,40 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// cancellation of a tool call sent by the hub to the proxy
+const (
+	RpcNotificationMethodCancelled = "notifications/cancelled"
+)
+
+type JsonRpcNotificationCancelledParams struct {
+	// mux request id of the tool call
+	RequestId interface{} `json:"requestId"`
+	Reason    *string     `json:"reason,omitempty"`
+}
+
+func ParseJsonRpcNotificationCancelledParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcNotificationCancelledParams, error) {
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	requestId, ok := namedParams["requestId"]
+	if !ok || jsonrpc.RequestIdFromValue(requestId) == nil {
+		return nil, fmt.Errorf("missing requestId")
+	}
+
+	return &JsonRpcNotificationCancelledParams{
+		RequestId: requestId,
+		Reason:    protocol.GetOptionalStringField(namedParams, "reason"),
+	}, nil
+}
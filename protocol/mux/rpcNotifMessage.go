@@ -0,0 +1,45 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// log message sent by the proxied MCP server, forwarded to the hub
+const (
+	RpcNotificationMethodMessage = "notifications/message"
+)
+
+type JsonRpcNotificationMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+func ParseJsonRpcNotificationMessageParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcNotificationMessageParams, error) {
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	level, err := protocol.GetStringField(namedParams, "level")
+	if err != nil {
+		return nil, fmt.Errorf("missing level")
+	}
+
+	message := &JsonRpcNotificationMessageParams{
+		Level: level,
+		Data:  namedParams["data"],
+	}
+	if logger := protocol.GetOptionalStringField(namedParams, "logger"); logger != nil {
+		message.Logger = *logger
+	}
+
+	return message, nil
+}
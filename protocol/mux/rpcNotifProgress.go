@@ -0,0 +1,48 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// progress of a tool call sent by the proxied MCP server, forwarded to the hub
+const (
+	RpcNotificationMethodProgress = "notifications/progress"
+)
+
+type JsonRpcNotificationProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         *float64    `json:"total,omitempty"`
+	Message       *string     `json:"message,omitempty"`
+}
+
+func ParseJsonRpcNotificationProgressParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcNotificationProgressParams, error) {
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	progressToken, ok := namedParams["progressToken"]
+	if !ok {
+		return nil, fmt.Errorf("missing progressToken")
+	}
+
+	progress, err := protocol.GetNumberField(namedParams, "progress")
+	if err != nil {
+		return nil, fmt.Errorf("missing progress")
+	}
+
+	return &JsonRpcNotificationProgressParams{
+		ProgressToken: progressToken,
+		Progress:      progress,
+		Total:         protocol.GetOptionalNumberField(namedParams, "total"),
+		Message:       protocol.GetOptionalStringField(namedParams, "message"),
+	}, nil
+}
@@ -0,0 +1,57 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// roots of the MCP client sent by the hub to the proxies
+const (
+	RpcNotificationMethodRootsUpdated = "notifications/roots/updated"
+)
+
+type JsonRpcNotificationRootsUpdatedParams struct {
+	Roots []Root `json:"roots"`
+}
+
+type Root struct {
+	Uri  string  `json:"uri"`
+	Name *string `json:"name,omitempty"`
+}
+
+func ParseJsonRpcNotificationRootsUpdatedParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcNotificationRootsUpdatedParams, error) {
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	roots, err := protocol.GetArrayField(namedParams, "roots")
+	if err != nil {
+		return nil, fmt.Errorf("missing roots")
+	}
+
+	params := JsonRpcNotificationRootsUpdatedParams{
+		Roots: make([]Root, 0, len(roots)),
+	}
+	for _, item := range roots {
+		root, err := protocol.CheckIsObject(item, "root")
+		if err != nil {
+			return nil, err
+		}
+		uri, err := protocol.GetStringField(root, "uri")
+		if err != nil {
+			return nil, fmt.Errorf("missing root uri")
+		}
+		params.Roots = append(params.Roots, Root{
+			Uri:  uri,
+			Name: protocol.GetOptionalStringField(root, "name"),
+		})
+	}
+	return &params, nil
+}
@@ -0,0 +1,62 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// the hub completes an argument of a prompt of the MCP server of the proxy,
+// the result of the MCP server is sent back unchanged
+const (
+	RpcRequestMethodCompletionComplete = "completion/complete"
+)
+
+type JsonRpcRequestCompletionCompleteParams struct {
+	Prompt   string `json:"prompt"`
+	Argument string `json:"argument"`
+	Value    string `json:"value"`
+	// values of the arguments already resolved by the client
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+func ParseJsonRpcRequestCompletionCompleteParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcRequestCompletionCompleteParams, error) {
+	var err error
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	req := JsonRpcRequestCompletionCompleteParams{
+		Arguments: map[string]string{},
+	}
+
+	req.Prompt, err = protocol.GetStringField(namedParams, "prompt")
+	if err != nil {
+		return nil, fmt.Errorf("missing prompt")
+	}
+	req.Argument, err = protocol.GetStringField(namedParams, "argument")
+	if err != nil {
+		return nil, fmt.Errorf("missing argument")
+	}
+	req.Value, err = protocol.GetStringField(namedParams, "value")
+	if err != nil {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	// read the optional arguments
+	for key, value := range protocol.GetOptionalObjectField(namedParams, "arguments") {
+		value, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("arguments must be strings")
+		}
+		req.Arguments[key] = value
+	}
+
+	return &req, nil
+}
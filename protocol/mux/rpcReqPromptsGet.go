@@ -0,0 +1,47 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// the hub gets a prompt of the MCP server of the proxy, the result
+// of the MCP server is sent back unchanged
+const (
+	RpcRequestMethodPromptsGet = "prompts/get"
+)
+
+type JsonRpcRequestPromptsGetParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+func ParseJsonRpcRequestPromptsGetParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcRequestPromptsGetParams, error) {
+	var err error
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	req := JsonRpcRequestPromptsGetParams{}
+
+	// read prompt name
+	req.Name, err = protocol.GetStringField(namedParams, "name")
+	if err != nil {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	// read arguments
+	req.Arguments, err = protocol.GetObjectField(namedParams, "arguments")
+	if err != nil {
+		return nil, fmt.Errorf("missing arguments")
+	}
+
+	return &req, nil
+}
@@ -0,0 +1,98 @@
+package mux
+
+import (
+	"fmt"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol"
+)
+
+// the proxy sends the prompts of its MCP server once it is registered,
+// and again when they change
+const (
+	RpcRequestMethodPromptsRegister = "prompts/register"
+)
+
+type JsonRpcRequestPromptsRegisterParams struct {
+	Prompts []PromptDescription `json:"prompts"`
+}
+
+// the hub answers with an empty result
+type JsonRpcResponsePromptsRegisterResult struct{}
+
+type PromptDescription struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Arguments   []PromptArgumentDescription `json:"arguments"`
+}
+
+type PromptArgumentDescription struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+func ParseJsonRpcRequestPromptsRegisterParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcRequestPromptsRegisterParams, error) {
+	// parse params
+	if request.Params == nil {
+		return nil, fmt.Errorf("missing params")
+	}
+	if !request.Params.IsNamed() {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	namedParams := request.Params.NamedParams
+
+	req := JsonRpcRequestPromptsRegisterParams{
+		Prompts: []PromptDescription{},
+	}
+
+	// read prompts
+	prompts, err := protocol.GetArrayField(namedParams, "prompts")
+	if err != nil {
+		return nil, fmt.Errorf("missing prompts")
+	}
+	for _, prompt := range prompts {
+		promptMap, ok := prompt.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("prompt must be an object")
+		}
+		promptDescription := PromptDescription{
+			Arguments: []PromptArgumentDescription{},
+		}
+		promptDescription.Name, err = protocol.GetStringField(promptMap, "name")
+		if err != nil {
+			return nil, fmt.Errorf("prompt.name must be a string")
+		}
+		promptDescription.Description, err = protocol.GetStringField(promptMap, "description")
+		if err != nil {
+			return nil, fmt.Errorf("prompt.description must be a string")
+		}
+		arguments, err := protocol.GetArrayField(promptMap, "arguments")
+		if err != nil {
+			return nil, fmt.Errorf("prompt.arguments must be an array")
+		}
+		for _, argument := range arguments {
+			argumentMap, ok := argument.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("prompt argument must be an object")
+			}
+			argumentDescription := PromptArgumentDescription{}
+			argumentDescription.Name, err = protocol.GetStringField(argumentMap, "name")
+			if err != nil {
+				return nil, fmt.Errorf("argument.name must be a string")
+			}
+			argumentDescription.Description, err = protocol.GetStringField(argumentMap, "description")
+			if err != nil {
+				return nil, fmt.Errorf("argument.description must be a string")
+			}
+			argumentDescription.Required, err = protocol.GetBoolField(argumentMap, "required")
+			if err != nil {
+				return nil, fmt.Errorf("argument.required must be a boolean")
+			}
+			promptDescription.Arguments = append(promptDescription.Arguments, argumentDescription)
+		}
+		req.Prompts = append(req.Prompts, promptDescription)
+	}
+
+	return &req, nil
+}
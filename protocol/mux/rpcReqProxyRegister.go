@@ -17,6 +17,8 @@ type JsonRpcRequestProxyRegisterParams struct {
 	Persistent      bool             `json:"persistent"`
 	Proxy           ProxyDescription `json:"proxy"`
 	ServerInfo      ServerInfo       `json:"serverInfo"`
+	// required by the hub, proves that the proxy has its key
+	Authentication *ProxyAuthentication `json:"authentication,omitempty"`
 }
 
 type ProxyDescription struct {
@@ -91,5 +93,24 @@ func ParseJsonRpcRequestProxyRegisterParams(request *jsonrpc.JsonRpcRequest) (*J
 		return nil, fmt.Errorf("serverInfo.version must be a string")
 	}
 
+	// read authentication (optional, the hub denies the registrations without it)
+	authentication := protocol.GetOptionalObjectField(namedParams, "authentication")
+	if authentication != nil {
+		req.Authentication = &ProxyAuthentication{}
+		req.Authentication.Nonce, err = protocol.GetStringField(authentication, "nonce")
+		if err != nil {
+			return nil, fmt.Errorf("authentication.nonce must be a string")
+		}
+		timestamp, err := protocol.GetNumberField(authentication, "timestamp")
+		if err != nil {
+			return nil, fmt.Errorf("authentication.timestamp must be a number")
+		}
+		req.Authentication.Timestamp = int64(timestamp)
+		req.Authentication.Signature, err = protocol.GetStringField(authentication, "signature")
+		if err != nil {
+			return nil, fmt.Errorf("authentication.signature must be a string")
+		}
+	}
+
 	return &req, nil
 }
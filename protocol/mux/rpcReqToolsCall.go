@@ -15,6 +15,8 @@ const (
 type JsonRpcRequestToolsCallParams struct {
 	Name string                 `json:"name"`
 	Args map[string]interface{} `json:"args"`
+	// progress token sent by the MCP client, if any
+	ProgressToken interface{} `json:"progressToken,omitempty"`
 }
 
 func ParseJsonRpcRequestToolsCallParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcRequestToolsCallParams, error) {
@@ -48,5 +50,8 @@ func ParseJsonRpcRequestToolsCallParams(request *jsonrpc.JsonRpcRequest) (*JsonR
 		req.Args[key] = value
 	}
 
+	// read optional progress token
+	req.ProgressToken = namedParams["progressToken"]
+
 	return &req, nil
 }
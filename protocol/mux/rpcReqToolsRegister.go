@@ -5,6 +5,7 @@ import (
 
 	"github.com/hamstah/gomcp/jsonrpc"
 	"github.com/hamstah/gomcp/protocol"
+	"github.com/hamstah/gomcp/types"
 )
 
 const (
@@ -16,9 +17,13 @@ type JsonRpcRequestToolsRegisterParams struct {
 }
 
 type ToolDescription struct {
-	Name        string      `json:"name"`
+	Name string `json:"name"`
+	// the title of the MCP server, or the one of its annotations
+	Title       string      `json:"title,omitempty"`
 	Description string      `json:"description"`
 	InputSchema interface{} `json:"inputSchema"`
+	// hints about the behavior of the tool, kept for the hub clients
+	Annotations *types.ToolAnnotations `json:"annotations,omitempty"`
 }
 
 func ParseJsonRpcRequestToolsRegisterParams(request *jsonrpc.JsonRpcRequest) (*JsonRpcRequestToolsRegisterParams, error) {
@@ -58,6 +63,18 @@ func ParseJsonRpcRequestToolsRegisterParams(request *jsonrpc.JsonRpcRequest) (*J
 		if err != nil {
 			return nil, fmt.Errorf("tool.inputSchema must be an object")
 		}
+		// title and annotations are optional
+		if title := protocol.GetOptionalStringField(toolMap, "title"); title != nil {
+			toolDescription.Title = *title
+		}
+		if annotations := protocol.GetOptionalObjectField(toolMap, "annotations"); annotations != nil {
+			toolDescription.Annotations = &types.ToolAnnotations{
+				ReadOnlyHint:    protocol.GetOptionalBoolField(annotations, "readOnlyHint"),
+				DestructiveHint: protocol.GetOptionalBoolField(annotations, "destructiveHint"),
+				IdempotentHint:  protocol.GetOptionalBoolField(annotations, "idempotentHint"),
+				OpenWorldHint:   protocol.GetOptionalBoolField(annotations, "openWorldHint"),
+			}
+		}
 		req.Tools = append(req.Tools, toolDescription)
 	}
 
@@ -0,0 +1,75 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mux"
+	"github.com/hamstah/gomcp/types"
+)
+
+func TestParseJsonRpcRequestToolsRegisterParams(t *testing.T) {
+	readOnly := true
+	tests := []struct {
+		name    string
+		tool    mux.ToolDescription
+		wantErr bool
+	}{
+		{
+			name: "without title and annotations",
+			tool: mux.ToolDescription{
+				Name:        "list",
+				Description: "List the files",
+				InputSchema: map[string]interface{}{"type": "object"},
+			},
+		},
+		{
+			name: "with title and annotations",
+			tool: mux.ToolDescription{
+				Name:        "list",
+				Title:       "List files",
+				Description: "List the files",
+				InputSchema: map[string]interface{}{"type": "object"},
+				Annotations: &types.ToolAnnotations{ReadOnlyHint: &readOnly},
+			},
+		},
+		{
+			name: "without input schema",
+			tool: mux.ToolDescription{
+				Name:        "list",
+				Description: "List the files",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the params are read as sent over the mux link
+			data, err := json.Marshal(mux.JsonRpcRequestToolsRegisterParams{Tools: []mux.ToolDescription{tt.tool}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			params := &jsonrpc.JsonRpcParams{}
+			if err := json.Unmarshal(data, &params.NamedParams); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := mux.ParseJsonRpcRequestToolsRegisterParams(&jsonrpc.JsonRpcRequest{Params: params})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := []mux.ToolDescription{tt.tool}; !reflect.DeepEqual(result.Tools, want) {
+				t.Errorf("expected the tools %+v, got %+v", want, result.Tools)
+			}
+		})
+	}
+}
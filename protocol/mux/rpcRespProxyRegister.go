@@ -12,6 +12,8 @@ type JsonRpcResponseProxyRegisterResult struct {
 	ProxyId    string `json:"proxyId"`
 	Persistent bool   `json:"persistent"`
 	Denied     bool   `json:"denied"`
+	// why the registration was denied
+	Reason string `json:"reason,omitempty"`
 }
 
 func ParseJsonRpcResponseProxyRegister(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseProxyRegisterResult, error) {
@@ -48,10 +50,17 @@ func ParseJsonRpcResponseProxyRegister(response *jsonrpc.JsonRpcResponse) (*Json
 		return nil, err
 	}
 
-	return &JsonRpcResponseProxyRegisterResult{
+	// read reason (optional)
+	reason := protocol.GetOptionalStringField(result, "reason")
+
+	registerResult := JsonRpcResponseProxyRegisterResult{
 		SessionId:  sessionId,
 		ProxyId:    proxyId,
 		Persistent: persistent,
 		Denied:     denied,
-	}, nil
+	}
+	if reason != nil {
+		registerResult.Reason = *reason
+	}
+	return &registerResult, nil
 }
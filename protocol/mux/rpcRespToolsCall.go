@@ -8,8 +8,9 @@ import (
 )
 
 type JsonRpcResponseToolsCallResult struct {
-	Content []interface{} `json:"content"`
-	IsError *bool         `json:"isError,omitempty"`
+	Content           []interface{} `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           *bool         `json:"isError,omitempty"`
 }
 
 func ParseJsonRpcResponseToolsCall(response *jsonrpc.JsonRpcResponse) (*JsonRpcResponseToolsCallResult, error) {
@@ -31,8 +32,13 @@ func ParseJsonRpcResponseToolsCall(response *jsonrpc.JsonRpcResponse) (*JsonRpcR
 	// read isError
 	isError := protocol.GetOptionalBoolField(result, "isError")
 
-	return &JsonRpcResponseToolsCallResult{
+	resp := &JsonRpcResponseToolsCallResult{
 		Content: content,
 		IsError: isError,
-	}, nil
+	}
+	// read the optional structured content
+	if structuredContent := protocol.GetOptionalObjectField(result, "structuredContent"); structuredContent != nil {
+		resp.StructuredContent = structuredContent
+	}
+	return resp, nil
 }
@@ -89,3 +89,19 @@ func GetOptionalArrayField(result map[string]interface{}, name string) []interfa
 	}
 	return field
 }
+
+func GetNumberField(result map[string]interface{}, name string) (float64, error) {
+	field, ok := result[name].(float64)
+	if !ok {
+		return 0, fmt.Errorf("missing property %s", name)
+	}
+	return field, nil
+}
+
+func GetOptionalNumberField(result map[string]interface{}, name string) *float64 {
+	field, ok := result[name].(float64)
+	if !ok {
+		return nil
+	}
+	return &field
+}
@@ -0,0 +1,352 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/hamstah/gomcp/types"
+	"github.com/hamstah/gomcp/utils"
+	"github.com/invopop/jsonschema"
+)
+
+type ResourceDefinition struct {
+	Uri                     string
+	Name                    string
+	Description             string
+	MimeType                string
+	ResourceHandlerFunction interface{}
+}
+
+type ResourceTemplateDefinition struct {
+	UriTemplate             string
+	Name                    string
+	Description             string
+	MimeType                string
+	ResourceHandlerFunction interface{}
+	InputSchema             *jsonschema.Schema
+	InputTypeName           string
+	template                *UriTemplate
+	// completion handlers by variable name
+	completionHandlers map[string]types.CompletionHandler
+}
+
+type ResourceProvider struct {
+	providerName         string
+	isDisabled           bool
+	configSchema         *jsonschema.Schema
+	configTypeName       string
+	configType           reflect.Type
+	resourceInitFunction interface{}
+	contextType          reflect.Type
+	contextTypeName      string
+	resourceDefinitions  []*ResourceDefinition
+	templateDefinitions  []*ResourceTemplateDefinition
+	// the resource context retrieved from the resource init function
+	resourceContext interface{}
+	// the registry the provider is registered in, used for notifications
+	registry *ResourcesRegistry
+}
+
+func DeclareResourceProvider(providerName string, resourceInitFunction interface{}) (*ResourceProvider, error) {
+	// we initialize the resource provider with nil values
+	resourceProvider := &ResourceProvider{
+		providerName:         providerName,
+		isDisabled:           false,
+		configSchema:         nil,
+		configTypeName:       "",
+		configType:           nil,
+		resourceInitFunction: resourceInitFunction,
+		contextType:          nil,
+		contextTypeName:      "",
+		resourceDefinitions:  []*ResourceDefinition{},
+		templateDefinitions:  []*ResourceTemplateDefinition{},
+	}
+
+	// Validate that resourceInitFunction is a function
+	fnType := reflect.TypeOf(resourceInitFunction)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("resourceInitFunction must be a function")
+	}
+
+	// the function must have 1 or 2 arguments: context and optional config
+	if fnType.NumIn() != 1 && fnType.NumIn() != 2 {
+		return nil, fmt.Errorf("resourceInitFunction must have 1 or 2 arguments")
+	}
+
+	// the first argument must be a golang context
+	goContextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	if fnType.In(0) != goContextType {
+		return nil, fmt.Errorf("first argument must be context.Context")
+	}
+
+	// if a second argument is provided, it must be a pointer to a struct
+	if fnType.NumIn() == 2 {
+		if fnType.In(1).Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("resourceInitFunction argument must be a pointer to a struct")
+		}
+		configType := fnType.In(1)
+		configSchema, configTypeName, err := utils.GetSchemaFromType(configType)
+		if err != nil {
+			return nil, fmt.Errorf("error generating schema for resourceInitFunction argument")
+		}
+		// we store the config schema, type name and type
+		resourceProvider.configSchema = configSchema
+		resourceProvider.configTypeName = configTypeName
+		resourceProvider.configType = configType
+	}
+
+	// the function must return a resource context, error
+	if fnType.NumOut() != 2 || fnType.Out(0).Kind() != reflect.Ptr || fnType.Out(1).String() != "error" {
+		return nil, fmt.Errorf("resourceInitFunction must return a context, error")
+	}
+
+	// the context must be a struct
+	if fnType.Out(0).Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("resourceInitFunction first return value must be a pointer to a struct")
+	}
+	resourceProvider.contextType = fnType.Out(0).Elem()
+	resourceProvider.contextTypeName = fnType.Out(0).Elem().Name()
+
+	return resourceProvider, nil
+}
+
+func (rp *ResourceProvider) AddResource(uri string, name string, description string, mimeType string, resourceHandler interface{}) error {
+	// Validate that resourceHandler is a function
+	fnType := reflect.TypeOf(resourceHandler)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("resourceHandler must be a function")
+	}
+
+	// the function must have 3 arguments:
+	// the golang context
+	// the resource context
+	// the output
+	if fnType.NumIn() != 3 {
+		return fmt.Errorf("resourceHandler for %s must have 3 arguments", uri)
+	}
+
+	// the first argument must be a golang context
+	goContextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	if fnType.In(0) != goContextType {
+		return fmt.Errorf("resourceHandler for %s first argument must be a golang context", uri)
+	}
+
+	// the second argument must be a pointer to the resource context type
+	if fnType.In(1).Kind() != reflect.Ptr || fnType.In(1).Elem() != rp.contextType {
+		return fmt.Errorf("resourceHandler for %s second argument must be a pointer to the context type: %s", uri, rp.contextTypeName)
+	}
+
+	// the third argument must be an implementation of types.ResourceReadResult
+	resourceReadResultType := reflect.TypeOf((*types.ResourceReadResult)(nil)).Elem()
+	if !fnType.In(2).Implements(resourceReadResultType) {
+		return fmt.Errorf("resourceHandler for %s third argument must implement types.ResourceReadResult but is %s", uri, fnType.In(2).String())
+	}
+
+	// the function must return an error
+	if fnType.NumOut() != 1 || fnType.Out(0).String() != "error" {
+		return fmt.Errorf("resourceHandler for %s must return an error", uri)
+	}
+
+	resourceDefinition := &ResourceDefinition{
+		Uri:                     uri,
+		Name:                    name,
+		Description:             description,
+		MimeType:                mimeType,
+		ResourceHandlerFunction: resourceHandler,
+	}
+	// once the provider is registered, the resource is added under the lock of the registry
+	if rp.registry != nil {
+		return rp.registry.addResource(rp, resourceDefinition)
+	}
+	return rp.addResourceDefinition(resourceDefinition)
+}
+
+// RemoveResource removes a resource, the clients are told with NotifyResourceListChanged
+func (rp *ResourceProvider) RemoveResource(uri string) error {
+	if rp.registry != nil {
+		return rp.registry.removeResource(rp, uri)
+	}
+	if !rp.removeResourceDefinition(uri) {
+		return fmt.Errorf("resource %s not declared", uri)
+	}
+	return nil
+}
+
+func (rp *ResourceProvider) addResourceDefinition(resourceDefinition *ResourceDefinition) error {
+	// we need to check if the resource uri is already registered
+	for _, resource := range rp.resourceDefinitions {
+		if resource.Uri == resourceDefinition.Uri {
+			return fmt.Errorf("resource %s already declared", resourceDefinition.Uri)
+		}
+	}
+	rp.resourceDefinitions = append(rp.resourceDefinitions, resourceDefinition)
+	return nil
+}
+
+func (rp *ResourceProvider) removeResourceDefinition(uri string) bool {
+	count := len(rp.resourceDefinitions)
+	rp.resourceDefinitions = slices.DeleteFunc(rp.resourceDefinitions, func(resource *ResourceDefinition) bool {
+		return resource.Uri == uri
+	})
+	return len(rp.resourceDefinitions) != count
+}
+
+func (rp *ResourceProvider) AddResourceTemplate(uriTemplate string, name string, description string, mimeType string, resourceHandler interface{}) error {
+	template, err := ParseUriTemplate(uriTemplate)
+	if err != nil {
+		return err
+	}
+
+	// Validate that resourceHandler is a function
+	fnType := reflect.TypeOf(resourceHandler)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("resourceHandler must be a function")
+	}
+
+	// the function must have 4 arguments:
+	// the golang context
+	// the resource context
+	// the input built from the template variables
+	// the output
+	if fnType.NumIn() != 4 {
+		return fmt.Errorf("resourceHandler for %s must have 4 arguments", uriTemplate)
+	}
+
+	// the first argument must be a golang context
+	goContextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	if fnType.In(0) != goContextType {
+		return fmt.Errorf("resourceHandler for %s first argument must be a golang context", uriTemplate)
+	}
+
+	// the second argument must be a pointer to the resource context type
+	if fnType.In(1).Kind() != reflect.Ptr || fnType.In(1).Elem() != rp.contextType {
+		return fmt.Errorf("resourceHandler for %s second argument must be a pointer to the context type: %s", uriTemplate, rp.contextTypeName)
+	}
+
+	// the third argument must be a pointer to a struct
+	if fnType.In(2).Kind() != reflect.Ptr || fnType.In(2).Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("resourceHandler for %s third argument must be a pointer to a struct", uriTemplate)
+	}
+	// we need to get the schema of the third argument
+	inputSchema, inputTypeName, err := utils.GetSchemaFromType(fnType.In(2))
+	if err != nil {
+		return fmt.Errorf("error generating schema for resourceHandler for %s third argument", uriTemplate)
+	}
+
+	// every variable of the template must be a property of the input
+	for _, variable := range template.Variables() {
+		if inputSchema.Properties == nil {
+			return fmt.Errorf("resourceHandler for %s input %s has no property", uriTemplate, inputTypeName)
+		}
+		if _, ok := inputSchema.Properties.Get(variable); !ok {
+			return fmt.Errorf("resourceHandler for %s input %s has no property for variable %s", uriTemplate, inputTypeName, variable)
+		}
+	}
+
+	// the fourth argument must be an implementation of types.ResourceReadResult
+	resourceReadResultType := reflect.TypeOf((*types.ResourceReadResult)(nil)).Elem()
+	if !fnType.In(3).Implements(resourceReadResultType) {
+		return fmt.Errorf("resourceHandler for %s fourth argument must implement types.ResourceReadResult but is %s", uriTemplate, fnType.In(3).String())
+	}
+
+	// the function must return an error
+	if fnType.NumOut() != 1 || fnType.Out(0).String() != "error" {
+		return fmt.Errorf("resourceHandler for %s must return an error", uriTemplate)
+	}
+
+	templateDefinition := &ResourceTemplateDefinition{
+		UriTemplate:             uriTemplate,
+		Name:                    name,
+		Description:             description,
+		MimeType:                mimeType,
+		ResourceHandlerFunction: resourceHandler,
+		InputSchema:             inputSchema,
+		InputTypeName:           inputTypeName,
+		template:                template,
+		completionHandlers:      map[string]types.CompletionHandler{},
+	}
+	if rp.registry != nil {
+		return rp.registry.addResourceTemplate(rp, templateDefinition)
+	}
+	rp.templateDefinitions = append(rp.templateDefinitions, templateDefinition)
+	return nil
+}
+
+// AddResourceTemplateCompletion registers the function that completes a variable of a resource template
+func (rp *ResourceProvider) AddResourceTemplateCompletion(uriTemplate string, variable string, completionHandler types.CompletionHandler) error {
+	// once the provider is registered, the completion is added under the lock of the registry
+	if rp.registry != nil {
+		return rp.registry.addResourceTemplateCompletion(rp, uriTemplate, variable, completionHandler)
+	}
+	return rp.addCompletionHandler(uriTemplate, variable, completionHandler)
+}
+
+func (rp *ResourceProvider) addCompletionHandler(uriTemplate string, variable string, completionHandler types.CompletionHandler) error {
+	for _, templateDefinition := range rp.templateDefinitions {
+		if templateDefinition.UriTemplate != uriTemplate {
+			continue
+		}
+		for _, templateVariable := range templateDefinition.template.Variables() {
+			if templateVariable == variable {
+				templateDefinition.completionHandlers[variable] = completionHandler
+				return nil
+			}
+		}
+		return fmt.Errorf("resource template %s has no variable %s", uriTemplate, variable)
+	}
+	return fmt.Errorf("resource template %s not declared", uriTemplate)
+}
+
+// NotifyResourceUpdated tells the subscribed clients that the content
+// of the resource has changed
+func (rp *ResourceProvider) NotifyResourceUpdated(uri string) {
+	if rp.registry == nil || rp.isDisabled {
+		return
+	}
+	rp.registry.notifyResourceUpdated(uri)
+}
+
+// NotifyResourceListChanged tells the clients that the list of resources
+// or resource templates has changed, after AddResource or RemoveResource
+func (rp *ResourceProvider) NotifyResourceListChanged() {
+	if rp.registry == nil || rp.isDisabled {
+		return
+	}
+	rp.registry.notifyResourceListChanged()
+}
+
+// templateArguments converts the values matched in the uri to the types
+// declared by the input schema, so that they can be decoded in the input struct
+func templateArguments(inputSchema *jsonschema.Schema, values map[string]string) map[string]interface{} {
+	arguments := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		arguments[name] = value
+		if inputSchema.Properties == nil {
+			continue
+		}
+		property, ok := inputSchema.Properties.Get(name)
+		if !ok {
+			continue
+		}
+		// if the conversion fails, we keep the string and let
+		// the schema validation report the error
+		switch property.Type {
+		case "integer":
+			if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+				arguments[name] = number
+			}
+		case "number":
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				arguments[name] = number
+			}
+		case "boolean":
+			if boolean, err := strconv.ParseBool(value); err == nil {
+				arguments[name] = boolean
+			}
+		}
+	}
+	return arguments
+}
@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"encoding/json"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+/*
+ response must be:
+
+export const ReadResourceResultSchema = ResultSchema.extend({
+  contents: z.array(
+    z.union([TextResourceContentsSchema, BlobResourceContentsSchema]),
+  ),
+});
+
+export const ResourceContentsSchema = z
+  .object({
+    // The URI of this resource.
+    uri: z.string(),
+    // The MIME type of this resource, if known.
+    mimeType: z.optional(z.string()),
+  })
+  .passthrough();
+
+export const TextResourceContentsSchema = ResourceContentsSchema.extend({
+  // The text of the item. This must only be set if the item can actually be represented as text (not binary data).
+  text: z.string(),
+});
+
+export const BlobResourceContentsSchema = ResourceContentsSchema.extend({
+  // A base64-encoded string representing the binary data of the item.
+  blob: z.string().base64(),
+});
+*/
+
+type ResourceReadResultImpl struct {
+	Contents []interface{} `json:"contents"`
+}
+
+func NewResourceReadResult() types.ResourceReadResult {
+	return &ResourceReadResultImpl{
+		Contents: []interface{}{},
+	}
+}
+
+func (r *ResourceReadResultImpl) AddTextContent(uri string, mimeType string, text string) {
+	content := map[string]interface{}{
+		"uri":  uri,
+		"text": text,
+	}
+	if mimeType != "" {
+		content["mimeType"] = mimeType
+	}
+	r.Contents = append(r.Contents, content)
+}
+
+func (r *ResourceReadResultImpl) AddJSONTextContent(uri string, content interface{}) {
+	// let's marshal the content
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		panic(err)
+	}
+	r.Contents = append(r.Contents, map[string]interface{}{
+		"uri":      uri,
+		"mimeType": "application/json",
+		"text":     string(contentBytes),
+	})
+}
+
+func (r *ResourceReadResultImpl) AddBlobContent(uri string, mimeType string, base64Data string) {
+	content := map[string]interface{}{
+		"uri":  uri,
+		"blob": base64Data,
+	}
+	if mimeType != "" {
+		content["mimeType"] = mimeType
+	}
+	r.Contents = append(r.Contents, content)
+}
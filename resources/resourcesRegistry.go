@@ -0,0 +1,426 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/hamstah/gomcp/config"
+	"github.com/hamstah/gomcp/tools"
+	"github.com/hamstah/gomcp/types"
+	"github.com/hamstah/gomcp/utils"
+)
+
+type resourceProviderPrepared struct {
+	ResourceProvider   *ResourceProvider
+	ResourceDefinition *ResourceDefinition
+}
+
+type resourceTemplateProviderPrepared struct {
+	ResourceProvider           *ResourceProvider
+	ResourceTemplateDefinition *ResourceTemplateDefinition
+}
+
+type ResourcesRegistry struct {
+	ResourceProviders []*ResourceProvider
+	// resources are kept in a slice so that the list order is stable
+	Resources []*resourceProviderPrepared
+	// templates are matched in declaration order, after the static resources
+	ResourceTemplates []*resourceTemplateProviderPrepared
+	// the providers add and remove resources after Prepare,
+	// the mutex also guards the definitions of the providers
+	mutex      sync.RWMutex
+	isPrepared bool
+	logger     types.Logger
+	// handlers called when a resource provider notifies a change
+	onResourceUpdated     func(uri string)
+	onResourceListChanged func()
+}
+
+func NewResourcesRegistry(logger types.Logger) *ResourcesRegistry {
+	return &ResourcesRegistry{
+		ResourceProviders: []*ResourceProvider{},
+		Resources:         []*resourceProviderPrepared{},
+		ResourceTemplates: []*resourceTemplateProviderPrepared{},
+		logger:            logger,
+	}
+}
+
+func (r *ResourcesRegistry) RegisterResourceProvider(resourceProvider *ResourceProvider) error {
+	r.ResourceProviders = append(r.ResourceProviders, resourceProvider)
+	resourceProvider.registry = r
+	r.logger.Info("registered resource provider", types.LogArg{
+		"provider":        resourceProvider.providerName,
+		"configTypeName":  resourceProvider.configTypeName,
+		"contextTypeName": resourceProvider.contextTypeName,
+	})
+	return nil
+}
+
+func findResourceConfig(resourceConfigs []config.ResourceConfig, providerName string) *config.ResourceConfig {
+	for ix := range resourceConfigs {
+		if resourceConfigs[ix].Name == providerName {
+			return &resourceConfigs[ix]
+		}
+	}
+	return nil
+}
+
+func (r *ResourcesRegistry) checkConfiguration(resourceConfigs []config.ResourceConfig) error {
+	// we go through all the resource providers and check if the configuration is valid
+	for _, resourceProvider := range r.ResourceProviders {
+		resourceConfig := findResourceConfig(resourceConfigs, resourceProvider.providerName)
+		if resourceConfig != nil && resourceConfig.IsDisabled {
+			// the resource provider is configured to be disabled
+			r.logger.Info("resource provider is configured to be disabled", types.LogArg{
+				"provider": resourceProvider.providerName,
+			})
+			resourceProvider.isDisabled = true
+			continue
+		}
+
+		if resourceProvider.configSchema == nil {
+			r.logger.Info("no config schema for resource provider", types.LogArg{
+				"provider": resourceProvider.providerName,
+			})
+			continue
+		}
+
+		r.logger.Info("checking config schema for resource provider", types.LogArg{
+			"provider": resourceProvider.providerName,
+		})
+		if resourceConfig == nil {
+			return fmt.Errorf("resource config %s not found for resource provider %s", resourceProvider.providerName, resourceProvider.providerName)
+		}
+		err := utils.ValidateJsonSchemaWithObject(resourceProvider.configSchema, resourceConfig.Configuration)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ResourcesRegistry) initializeProviders(ctx context.Context, resourceConfigs []config.ResourceConfig) error {
+	for _, resourceProvider := range r.ResourceProviders {
+		if resourceProvider.isDisabled {
+			continue
+		}
+
+		logger := types.NewSubLogger(r.logger, types.LogArg{
+			"provider": resourceProvider.providerName,
+		})
+		ctx := tools.MakeContextWithLogger(ctx, logger)
+
+		// the init function takes the configuration only if it declares one
+		args := []interface{}{ctx}
+		if resourceProvider.configSchema != nil {
+			resourceConfig := findResourceConfig(resourceConfigs, resourceProvider.providerName)
+			args = append(args, resourceConfig.Configuration)
+		}
+
+		result, callErr, err := utils.CallFunction(resourceProvider.resourceInitFunction, args...)
+		if err != nil {
+			return err
+		}
+		if callErr != nil {
+			return callErr
+		}
+		logger.Info("resource provider initialized", types.LogArg{
+			"result": result,
+		})
+		// we store the resource context
+		resourceProvider.resourceContext = result
+	}
+	return nil
+}
+
+func (r *ResourcesRegistry) Prepare(ctx context.Context, resourceConfigs []config.ResourceConfig) error {
+	// we check that the configuration for each resource provider is valid
+	err := r.checkConfiguration(resourceConfigs)
+	if err != nil {
+		return fmt.Errorf("error checking configuration: %w", err)
+	}
+
+	// let's prepare the different resources for each resource provider
+	r.mutex.Lock()
+	for _, resourceProvider := range r.ResourceProviders {
+		if resourceProvider.isDisabled {
+			continue
+		}
+		for _, resourceDefinition := range resourceProvider.resourceDefinitions {
+			if err := r.prepareResource(resourceProvider, resourceDefinition); err != nil {
+				r.mutex.Unlock()
+				return err
+			}
+		}
+		for _, templateDefinition := range resourceProvider.templateDefinitions {
+			if err := r.prepareResourceTemplate(resourceProvider, templateDefinition); err != nil {
+				r.mutex.Unlock()
+				return err
+			}
+		}
+	}
+	r.isPrepared = true
+	r.mutex.Unlock()
+
+	// now, we can initialize the resource providers with their configuration
+	err = r.initializeProviders(ctx, resourceConfigs)
+	if err != nil {
+		return fmt.Errorf("error initializing resource providers: %w", err)
+	}
+
+	return nil
+}
+
+// prepareResource makes a resource of a provider available, the mutex must be held
+func (r *ResourcesRegistry) prepareResource(resourceProvider *ResourceProvider, resourceDefinition *ResourceDefinition) error {
+	// check that we don't already have a resource with this uri
+	if _, _, err := r.getResource(resourceDefinition.Uri); err == nil {
+		return fmt.Errorf("resource %s already registered", resourceDefinition.Uri)
+	}
+	r.Resources = append(r.Resources, &resourceProviderPrepared{
+		ResourceProvider:   resourceProvider,
+		ResourceDefinition: resourceDefinition,
+	})
+	return nil
+}
+
+// prepareResourceTemplate makes a resource template of a provider available, the mutex must be held
+func (r *ResourcesRegistry) prepareResourceTemplate(resourceProvider *ResourceProvider, templateDefinition *ResourceTemplateDefinition) error {
+	// check that we don't already have a template with this uri template
+	for _, template := range r.ResourceTemplates {
+		if template.ResourceTemplateDefinition.UriTemplate == templateDefinition.UriTemplate {
+			return fmt.Errorf("resource template %s already registered", templateDefinition.UriTemplate)
+		}
+	}
+	r.ResourceTemplates = append(r.ResourceTemplates, &resourceTemplateProviderPrepared{
+		ResourceProvider:           resourceProvider,
+		ResourceTemplateDefinition: templateDefinition,
+	})
+	return nil
+}
+
+// addResource adds a resource to a registered provider, it is listed
+// right away once the registry is prepared
+func (r *ResourcesRegistry) addResource(resourceProvider *ResourceProvider, resourceDefinition *ResourceDefinition) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err := resourceProvider.addResourceDefinition(resourceDefinition); err != nil {
+		return err
+	}
+	if r.isPrepared && !resourceProvider.isDisabled {
+		if err := r.prepareResource(resourceProvider, resourceDefinition); err != nil {
+			resourceProvider.removeResourceDefinition(resourceDefinition.Uri)
+			return err
+		}
+	}
+	return nil
+}
+
+// addResourceTemplate adds a resource template to a registered provider, it is listed
+// right away once the registry is prepared
+func (r *ResourcesRegistry) addResourceTemplate(resourceProvider *ResourceProvider, templateDefinition *ResourceTemplateDefinition) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.isPrepared && !resourceProvider.isDisabled {
+		if err := r.prepareResourceTemplate(resourceProvider, templateDefinition); err != nil {
+			return err
+		}
+	}
+	resourceProvider.templateDefinitions = append(resourceProvider.templateDefinitions, templateDefinition)
+	return nil
+}
+
+// addResourceTemplateCompletion adds the completion of a variable to a resource template of a registered provider
+func (r *ResourcesRegistry) addResourceTemplateCompletion(resourceProvider *ResourceProvider, uriTemplate string, variable string, completionHandler types.CompletionHandler) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return resourceProvider.addCompletionHandler(uriTemplate, variable, completionHandler)
+}
+
+// removeResource removes a resource of a registered provider
+func (r *ResourcesRegistry) removeResource(resourceProvider *ResourceProvider, uri string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !resourceProvider.removeResourceDefinition(uri) {
+		return fmt.Errorf("resource %s not declared", uri)
+	}
+	r.Resources = slices.DeleteFunc(r.Resources, func(resource *resourceProviderPrepared) bool {
+		return resource.ResourceProvider == resourceProvider && resource.ResourceDefinition.Uri == uri
+	})
+	return nil
+}
+
+func (r *ResourcesRegistry) SetNotificationHandlers(onResourceUpdated func(uri string), onResourceListChanged func()) {
+	r.onResourceUpdated = onResourceUpdated
+	r.onResourceListChanged = onResourceListChanged
+}
+
+func (r *ResourcesRegistry) notifyResourceUpdated(uri string) {
+	if r.onResourceUpdated != nil {
+		r.onResourceUpdated(uri)
+	}
+}
+
+func (r *ResourcesRegistry) notifyResourceListChanged() {
+	if r.onResourceListChanged != nil {
+		r.onResourceListChanged()
+	}
+}
+
+func (r *ResourcesRegistry) GetListOfResources() []*ResourceDefinition {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	resources := make([]*ResourceDefinition, 0, len(r.Resources))
+	for _, resource := range r.Resources {
+		resources = append(resources, resource.ResourceDefinition)
+	}
+	return resources
+}
+
+func (r *ResourcesRegistry) GetListOfResourceTemplates() []*ResourceTemplateDefinition {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	templates := make([]*ResourceTemplateDefinition, 0, len(r.ResourceTemplates))
+	for _, template := range r.ResourceTemplates {
+		templates = append(templates, template.ResourceTemplateDefinition)
+	}
+	return templates
+}
+
+// getResource returns the resource with the uri, the mutex must be held
+func (r *ResourcesRegistry) getResource(uri string) (*ResourceDefinition, *ResourceProvider, error) {
+	for _, resource := range r.Resources {
+		if resource.ResourceDefinition.Uri == uri {
+			return resource.ResourceDefinition, resource.ResourceProvider, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("resource %s not found", uri)
+}
+
+// findResource returns the resource with the uri
+func (r *ResourcesRegistry) findResource(uri string) (*ResourceDefinition, *ResourceProvider, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.getResource(uri)
+}
+
+// matchResourceTemplate returns the first template matching the uri
+// with the values of its variables
+func (r *ResourcesRegistry) matchResourceTemplate(uri string) (*ResourceTemplateDefinition, *ResourceProvider, map[string]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, template := range r.ResourceTemplates {
+		values, ok := template.ResourceTemplateDefinition.template.Match(uri)
+		if ok {
+			return template.ResourceTemplateDefinition, template.ResourceProvider, values, nil
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("resource %s not found", uri)
+}
+
+// CompleteResourceTemplateArgument returns the possible values of a variable of a resource template
+func (r *ResourcesRegistry) CompleteResourceTemplateArgument(ctx context.Context, uriTemplate string, variable string, value string, arguments map[string]string) ([]string, error) {
+	r.mutex.RLock()
+	templates := slices.Clone(r.ResourceTemplates)
+	r.mutex.RUnlock()
+	for _, template := range templates {
+		templateDefinition := template.ResourceTemplateDefinition
+		if templateDefinition.UriTemplate != uriTemplate {
+			continue
+		}
+		// the completions can be added after the registration
+		r.mutex.RLock()
+		completionHandler, ok := templateDefinition.completionHandlers[variable]
+		r.mutex.RUnlock()
+		if !ok {
+			// nothing to suggest
+			return []string{}, nil
+		}
+		logger := types.NewSubLogger(r.logger, types.LogArg{
+			"provider": template.ResourceProvider.providerName,
+		})
+		return completionHandler(tools.MakeContextWithLogger(ctx, logger), value, arguments)
+	}
+	return nil, fmt.Errorf("resource template %s not found", uriTemplate)
+}
+
+func (r *ResourcesRegistry) HasResource(uri string) bool {
+	if _, _, err := r.findResource(uri); err == nil {
+		return true
+	}
+	_, _, _, err := r.matchResourceTemplate(uri)
+	return err == nil
+}
+
+func (r *ResourcesRegistry) ReadResource(ctx context.Context, uri string) (interface{}, error) {
+	// static resources take precedence over templates
+	resourceDefinition, resourceProvider, err := r.findResource(uri)
+	if err != nil {
+		return r.readResourceTemplate(ctx, uri)
+	}
+
+	// let's call the resource handler
+	logger := types.NewSubLogger(r.logger, types.LogArg{
+		"provider": resourceProvider.providerName,
+		"uri":      uri,
+	})
+	// the logs are sent to the client of the session reading the resource
+	if logForwarder := tools.GetLogForwarder(ctx); logForwarder != nil {
+		logger = types.NewForwardingLogger(logger, resourceProvider.providerName, logForwarder)
+	}
+	goCtx := tools.MakeContextWithLogger(ctx, logger)
+
+	// let's create the output
+	output := NewResourceReadResult()
+
+	_, callErr, err := utils.CallFunction(resourceDefinition.ResourceHandlerFunction, goCtx, resourceProvider.resourceContext, output)
+	if err != nil {
+		return nil, err
+	}
+	if callErr != nil {
+		return nil, callErr
+	}
+
+	return output, nil
+}
+
+func (r *ResourcesRegistry) readResourceTemplate(ctx context.Context, uri string) (interface{}, error) {
+	templateDefinition, resourceProvider, values, err := r.matchResourceTemplate(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	// the values extracted from the uri must match the input schema
+	arguments := templateArguments(templateDefinition.InputSchema, values)
+	err = utils.ValidateJsonSchemaWithObject(templateDefinition.InputSchema, arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri %s for template %s: %w", uri, templateDefinition.UriTemplate, err)
+	}
+
+	// let's call the resource handler
+	logger := types.NewSubLogger(r.logger, types.LogArg{
+		"provider":    resourceProvider.providerName,
+		"uri":         uri,
+		"uriTemplate": templateDefinition.UriTemplate,
+	})
+	// the logs are sent to the client of the session reading the resource
+	if logForwarder := tools.GetLogForwarder(ctx); logForwarder != nil {
+		logger = types.NewForwardingLogger(logger, resourceProvider.providerName, logForwarder)
+	}
+	goCtx := tools.MakeContextWithLogger(ctx, logger)
+
+	// let's create the output
+	output := NewResourceReadResult()
+
+	_, callErr, err := utils.CallFunction(templateDefinition.ResourceHandlerFunction, goCtx, resourceProvider.resourceContext, arguments, output)
+	if err != nil {
+		return nil, err
+	}
+	if callErr != nil {
+		return nil, callErr
+	}
+
+	return output, nil
+}
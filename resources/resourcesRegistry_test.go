@@ -0,0 +1,139 @@
+package resources
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+type testResourceContext struct{}
+
+func testResourceInit(ctx context.Context) (*testResourceContext, error) {
+	return &testResourceContext{}, nil
+}
+
+func testReadResource(ctx context.Context, resourceCtx *testResourceContext, output types.ResourceReadResult) error {
+	return nil
+}
+
+func listedUris(registry *ResourcesRegistry) []string {
+	uris := []string{}
+	for _, resource := range registry.GetListOfResources() {
+		uris = append(uris, resource.Uri)
+	}
+	return uris
+}
+
+func TestResourcesAddedAfterPrepare(t *testing.T) {
+	registry := NewResourcesRegistry(nopLogger{})
+	provider, err := DeclareResourceProvider("test", testResourceInit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, _ := DeclareResourceProvider("other", testResourceInit)
+	registry.RegisterResourceProvider(provider)
+	registry.RegisterResourceProvider(other)
+	if err := provider.AddResource("test://a", "a", "", "text/plain", testReadResource); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Prepare(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the resources added once the registry is prepared are listed and read
+	if err := provider.AddResource("test://b", "b", "", "text/plain", testReadResource); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := listedUris(registry); !reflect.DeepEqual(got, []string{"test://a", "test://b"}) {
+		t.Errorf("unexpected resources: %v", got)
+	}
+	if _, err := registry.ReadResource(context.Background(), "test://b"); err != nil {
+		t.Errorf("unexpected error reading the added resource: %v", err)
+	}
+
+	// the uris are unique across the providers
+	if err := other.AddResource("test://b", "b", "", "text/plain", testReadResource); err == nil {
+		t.Errorf("expected an error for a resource already registered")
+	}
+	if err := other.RemoveResource("test://b"); err == nil {
+		t.Errorf("expected an error removing the resource of another provider")
+	}
+
+	if err := provider.RemoveResource("test://a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := listedUris(registry); !reflect.DeepEqual(got, []string{"test://b"}) {
+		t.Errorf("unexpected resources: %v", got)
+	}
+	if registry.HasResource("test://a") {
+		t.Errorf("expected the removed resource to be unknown")
+	}
+	// a removed uri can be added again
+	if err := provider.AddResource("test://a", "a", "", "text/plain", testReadResource); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type testTemplateInput struct {
+	Name string `json:"name"`
+}
+
+func testReadTemplate(ctx context.Context, resourceCtx *testResourceContext, input *testTemplateInput, output types.ResourceReadResult) error {
+	return nil
+}
+
+func TestResourceTemplateCompletionAddedConcurrently(t *testing.T) {
+	registry := NewResourcesRegistry(nopLogger{})
+	provider, err := DeclareResourceProvider("test", testResourceInit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry.RegisterResourceProvider(provider)
+	if err := provider.AddResourceTemplate("test://{name}", "name", "", "text/plain", testReadTemplate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Prepare(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the clients complete the variable while the provider adds its completion
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if _, err := registry.CompleteResourceTemplateArgument(context.Background(), "test://{name}", "name", "", nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+	}()
+	err = provider.AddResourceTemplateCompletion("test://{name}", "name", func(ctx context.Context, value string, arguments map[string]string) ([]string, error) {
+		return []string{"a"}, nil
+	})
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values, err := registry.CompleteResourceTemplateArgument(context.Background(), "test://{name}", "name", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(values, []string{"a"}) {
+		t.Errorf("expected the values of the completion, got %v", values)
+	}
+	if err := provider.AddResourceTemplateCompletion("test://{name}", "unknown", nil); err == nil {
+		t.Errorf("expected an error for an unknown variable")
+	}
+}
@@ -0,0 +1,248 @@
+package resources
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// UriTemplate is a RFC 6570 URI template used to match resource URIs
+// https://datatracker.ietf.org/doc/html/rfc6570
+//
+// matching is the reverse operation of the expansion described in the RFC:
+// given a URI, we extract the values of the variables. Values are returned
+// as strings, exploded and prefixed variables are matched as plain strings.
+type UriTemplate struct {
+	template    string
+	expressions []*uriTemplateExpression
+	regex       *regexp.Regexp
+}
+
+type uriTemplateVariable struct {
+	name    string
+	explode bool
+	prefix  int
+	// index of the capture group in the regex (0 for query variables)
+	group int
+}
+
+type uriTemplateExpression struct {
+	operator  byte
+	variables []*uriTemplateVariable
+	// index of the capture group for query expressions
+	queryGroup int
+}
+
+var uriTemplateVariableNameRegex = regexp.MustCompile(`^([A-Za-z0-9_]|%[0-9A-Fa-f]{2})([A-Za-z0-9_.]|%[0-9A-Fa-f]{2})*$`)
+
+func ParseUriTemplate(template string) (*UriTemplate, error) {
+	uriTemplate := &UriTemplate{
+		template:    template,
+		expressions: []*uriTemplateExpression{},
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	group := 0
+
+	rest := template
+	for len(rest) > 0 {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("invalid uri template %s: unexpected '}'", template)
+			}
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		literal := rest[:start]
+		if strings.IndexByte(literal, '}') >= 0 {
+			return nil, fmt.Errorf("invalid uri template %s: unexpected '}'", template)
+		}
+		pattern.WriteString(regexp.QuoteMeta(literal))
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid uri template %s: unclosed expression", template)
+		}
+		expression, err := parseUriTemplateExpression(rest[start+1 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid uri template %s: %v", template, err)
+		}
+		pattern.WriteString(expression.pattern(&group))
+		uriTemplate.expressions = append(uriTemplate.expressions, expression)
+
+		rest = rest[start+end+1:]
+	}
+	pattern.WriteString("$")
+
+	regex, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri template %s: %v", template, err)
+	}
+	uriTemplate.regex = regex
+
+	return uriTemplate, nil
+}
+
+func parseUriTemplateExpression(content string) (*uriTemplateExpression, error) {
+	if content == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	expression := &uriTemplateExpression{
+		operator:  0,
+		variables: []*uriTemplateVariable{},
+	}
+	switch content[0] {
+	case '+', '#', '.', '/', ';', '?', '&':
+		expression.operator = content[0]
+		content = content[1:]
+	case '=', ',', '!', '@', '|':
+		return nil, fmt.Errorf("reserved operator %c", content[0])
+	}
+
+	for _, spec := range strings.Split(content, ",") {
+		variable := &uriTemplateVariable{
+			name: spec,
+		}
+		if strings.HasSuffix(spec, "*") {
+			variable.explode = true
+			variable.name = strings.TrimSuffix(spec, "*")
+		} else if ix := strings.IndexByte(spec, ':'); ix >= 0 {
+			variable.name = spec[:ix]
+			_, err := fmt.Sscanf(spec[ix+1:], "%d", &variable.prefix)
+			if err != nil || variable.prefix <= 0 || variable.prefix >= 10000 {
+				return nil, fmt.Errorf("invalid prefix modifier in %s", spec)
+			}
+		}
+		if !uriTemplateVariableNameRegex.MatchString(variable.name) {
+			return nil, fmt.Errorf("invalid variable name %s", variable.name)
+		}
+		expression.variables = append(expression.variables, variable)
+	}
+	return expression, nil
+}
+
+// pattern returns the regular expression matching the expansion of the expression
+// group is the index of the last capture group used so far
+func (e *uriTemplateExpression) pattern(group *int) string {
+	var pattern strings.Builder
+
+	switch e.operator {
+	case '?', '&':
+		// the query parameters are captured as a whole and parsed after the match
+		*group++
+		e.queryGroup = *group
+		pattern.WriteString(fmt.Sprintf(`(?:%s([^#]*))?`, regexp.QuoteMeta(string(e.operator))))
+		return pattern.String()
+	}
+
+	// simple and reserved expansions are matched lazily so that a following
+	// optional expansion (eg "{name}{.ext}") gets its part of the uri
+	for ix, variable := range e.variables {
+		*group++
+		variable.group = *group
+
+		switch e.operator {
+		case 0:
+			if ix == 0 {
+				pattern.WriteString(`([^/?#,]*?)`)
+			} else {
+				pattern.WriteString(`(?:,([^/?#,]*))?`)
+			}
+		case '+':
+			if ix == 0 {
+				pattern.WriteString(`([^?#,]*?)`)
+			} else {
+				pattern.WriteString(`(?:,([^?#,]*))?`)
+			}
+		case '#':
+			if ix == 0 {
+				pattern.WriteString(`(?:#([^,]*)`)
+			} else {
+				pattern.WriteString(`(?:,([^,]*))?`)
+			}
+		case '.':
+			pattern.WriteString(`(?:\.([^/?#.]*))?`)
+		case '/':
+			if variable.explode {
+				pattern.WriteString(`(?:/([^?#]*))?`)
+			} else {
+				pattern.WriteString(`(?:/([^/?#]*))?`)
+			}
+		case ';':
+			pattern.WriteString(fmt.Sprintf(`(?:;%s(?:=([^;/?#]*))?)?`, regexp.QuoteMeta(variable.name)))
+		}
+	}
+	if e.operator == '#' {
+		pattern.WriteString(`)?`)
+	}
+	return pattern.String()
+}
+
+func (t *UriTemplate) String() string {
+	return t.template
+}
+
+// Variables returns the names of the variables of the template, in order
+func (t *UriTemplate) Variables() []string {
+	variables := []string{}
+	for _, expression := range t.expressions {
+		for _, variable := range expression.variables {
+			variables = append(variables, variable.name)
+		}
+	}
+	return variables
+}
+
+// Match checks if the uri matches the template and returns the values
+// of the variables found in the uri
+func (t *UriTemplate) Match(uri string) (map[string]string, bool) {
+	matches := t.regex.FindStringSubmatch(uri)
+	if matches == nil {
+		return nil, false
+	}
+
+	values := map[string]string{}
+
+	// the query parameters of all the query expressions are merged
+	query := url.Values{}
+	for _, expression := range t.expressions {
+		if expression.queryGroup == 0 || matches[expression.queryGroup] == "" {
+			continue
+		}
+		parsed, err := url.ParseQuery(matches[expression.queryGroup])
+		if err != nil {
+			return nil, false
+		}
+		for key, value := range parsed {
+			query[key] = append(query[key], value...)
+		}
+	}
+
+	for _, expression := range t.expressions {
+		for ix, variable := range expression.variables {
+			if expression.queryGroup != 0 {
+				if query.Has(variable.name) {
+					values[variable.name] = query.Get(variable.name)
+				}
+				continue
+			}
+			value := matches[variable.group]
+			isMandatory := ix == 0 && (expression.operator == 0 || expression.operator == '+')
+			if value == "" && !isMandatory {
+				// optional part of the expansion
+				continue
+			}
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, false
+			}
+			values[variable.name] = unescaped
+		}
+	}
+
+	return values, true
+}
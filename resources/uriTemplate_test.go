@@ -0,0 +1,197 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUriTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		wantVariables []string
+		wantError     bool
+	}{
+		{
+			name:          "no variable",
+			template:      "file:///etc/hosts",
+			wantVariables: []string{},
+		},
+		{
+			name:          "simple variable",
+			template:      "notion://page/{pageId}",
+			wantVariables: []string{"pageId"},
+		},
+		{
+			name:          "operators and modifiers",
+			template:      "repo://{owner}/{repo}{/path*}{?ref,depth:3}",
+			wantVariables: []string{"owner", "repo", "path", "ref", "depth"},
+		},
+		{
+			name:      "unclosed expression",
+			template:  "notion://page/{pageId",
+			wantError: true,
+		},
+		{
+			name:      "unexpected closing brace",
+			template:  "notion://page/pageId}",
+			wantError: true,
+		},
+		{
+			name:      "empty expression",
+			template:  "notion://page/{}",
+			wantError: true,
+		},
+		{
+			name:      "reserved operator",
+			template:  "notion://page/{=pageId}",
+			wantError: true,
+		},
+		{
+			name:      "invalid variable name",
+			template:  "notion://page/{page-id}",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseUriTemplate(tt.template)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseUriTemplate() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if !reflect.DeepEqual(template.Variables(), tt.wantVariables) {
+				t.Errorf("Variables() = %v, want %v", template.Variables(), tt.wantVariables)
+			}
+		})
+	}
+}
+
+func TestUriTemplateMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		uri        string
+		wantValues map[string]string
+		wantMatch  bool
+	}{
+		{
+			name:       "simple variable",
+			template:   "notion://page/{pageId}",
+			uri:        "notion://page/1234",
+			wantValues: map[string]string{"pageId": "1234"},
+			wantMatch:  true,
+		},
+		{
+			name:       "percent encoded value",
+			template:   "notion://page/{pageId}",
+			uri:        "notion://page/hello%20world",
+			wantValues: map[string]string{"pageId": "hello world"},
+			wantMatch:  true,
+		},
+		{
+			name:      "simple variable does not match a slash",
+			template:  "notion://page/{pageId}",
+			uri:       "notion://page/12/34",
+			wantMatch: false,
+		},
+		{
+			name:      "different literal",
+			template:  "notion://page/{pageId}",
+			uri:       "notion://database/1234",
+			wantMatch: false,
+		},
+		{
+			name:       "reserved expansion",
+			template:   "file://{+path}",
+			uri:        "file:///home/user/notes.md",
+			wantValues: map[string]string{"path": "/home/user/notes.md"},
+			wantMatch:  true,
+		},
+		{
+			name:       "multiple variables",
+			template:   "repo://{owner}/{repo}/issues/{number}",
+			uri:        "repo://hamstah/gomcp/issues/12",
+			wantValues: map[string]string{"owner": "hamstah", "repo": "gomcp", "number": "12"},
+			wantMatch:  true,
+		},
+		{
+			name:       "path segments",
+			template:   "repo://{repo}{/branch,path}",
+			uri:        "repo://gomcp/main/README.md",
+			wantValues: map[string]string{"repo": "gomcp", "branch": "main", "path": "README.md"},
+			wantMatch:  true,
+		},
+		{
+			name:       "exploded path",
+			template:   "repo://{repo}{/path*}",
+			uri:        "repo://gomcp/docs/intro.md",
+			wantValues: map[string]string{"repo": "gomcp", "path": "docs/intro.md"},
+			wantMatch:  true,
+		},
+		{
+			name:       "label expansion",
+			template:   "file://{name}{.ext}",
+			uri:        "file://report.pdf",
+			wantValues: map[string]string{"name": "report", "ext": "pdf"},
+			wantMatch:  true,
+		},
+		{
+			name:       "path-style parameters",
+			template:   "map://point{;x,y}",
+			uri:        "map://point;x=1024;y=768",
+			wantValues: map[string]string{"x": "1024", "y": "768"},
+			wantMatch:  true,
+		},
+		{
+			name:       "query parameters",
+			template:   "search://notes{?q,limit}",
+			uri:        "search://notes?q=go%20mcp&limit=10",
+			wantValues: map[string]string{"q": "go mcp", "limit": "10"},
+			wantMatch:  true,
+		},
+		{
+			name:       "missing optional query parameter",
+			template:   "search://notes{?q,limit}",
+			uri:        "search://notes?q=go",
+			wantValues: map[string]string{"q": "go"},
+			wantMatch:  true,
+		},
+		{
+			name:       "query continuation",
+			template:   "search://notes?fixed=yes{&q}",
+			uri:        "search://notes?fixed=yes&q=mcp",
+			wantValues: map[string]string{"q": "mcp"},
+			wantMatch:  true,
+		},
+		{
+			name:       "fragment",
+			template:   "doc://{id}{#section}",
+			uri:        "doc://42#usage",
+			wantValues: map[string]string{"id": "42", "section": "usage"},
+			wantMatch:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseUriTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseUriTemplate() error = %v", err)
+			}
+			values, ok := template.Match(tt.uri)
+			if ok != tt.wantMatch {
+				t.Fatalf("Match() ok = %v, want %v", ok, tt.wantMatch)
+			}
+			if !tt.wantMatch {
+				return
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Match() = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
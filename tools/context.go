@@ -2,8 +2,14 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
 
+	"github.com/hamstah/gomcp/protocol/mcp"
 	"github.com/hamstah/gomcp/types"
+	"github.com/hamstah/gomcp/utils"
+	"github.com/invopop/jsonschema"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
@@ -12,10 +18,152 @@ type contextKey string
 // loggerContextKey is the key used to store the logger in the context
 var loggerKey = contextKey("logger")
 
-func makeContextWithLogger(ctx context.Context, logger types.Logger) context.Context {
+func MakeContextWithLogger(ctx context.Context, logger types.Logger) context.Context {
 	return context.WithValue(ctx, loggerKey, logger)
 }
 
 func GetLogger(ctx context.Context) types.Logger {
 	return ctx.Value(loggerKey).(types.Logger)
 }
+
+// logForwarderKey is the key used to store the log forwarder in the context
+var logForwarderKey = contextKey("logForwarder")
+
+// MakeContextWithLogForwarder sets the forwarder sending the logs of the
+// handlers to the MCP client of the session
+func MakeContextWithLogForwarder(ctx context.Context, logForwarder types.LogForwarder) context.Context {
+	return context.WithValue(ctx, logForwarderKey, logForwarder)
+}
+
+// GetLogForwarder returns nil if the logs are not forwarded to the client
+func GetLogForwarder(ctx context.Context) types.LogForwarder {
+	logForwarder, _ := ctx.Value(logForwarderKey).(types.LogForwarder)
+	return logForwarder
+}
+
+// progressReporterKey is the key used to store the progress reporter in the context
+var progressReporterKey = contextKey("progressReporter")
+
+// ProgressReporter sends a progress notification to the MCP client
+// total and message are optional (0 and "")
+type ProgressReporter func(progress float64, total float64, message string)
+
+func MakeContextWithProgressReporter(ctx context.Context, progressReporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey, progressReporter)
+}
+
+// ReportProgress reports the progress of the current tool call, it does nothing
+// if the client didn't ask for progress notifications
+func ReportProgress(ctx context.Context, progress float64, total float64, message string) {
+	progressReporter, ok := ctx.Value(progressReporterKey).(ProgressReporter)
+	if !ok {
+		return
+	}
+	progressReporter(progress, total, message)
+}
+
+// messageCreatorKey is the key used to store the message creator in the context
+var messageCreatorKey = contextKey("messageCreator")
+
+// MessageCreator sends a sampling request to the MCP client and waits for the result
+type MessageCreator func(ctx context.Context, request *mcp.JsonRpcRequestSamplingCreateMessageParams) (*mcp.JsonRpcResponseSamplingCreateMessageResult, error)
+
+func MakeContextWithMessageCreator(ctx context.Context, messageCreator MessageCreator) context.Context {
+	return context.WithValue(ctx, messageCreatorKey, messageCreator)
+}
+
+// CreateMessage asks the LLM of the client to generate a message
+func CreateMessage(ctx context.Context, request *mcp.JsonRpcRequestSamplingCreateMessageParams) (*mcp.JsonRpcResponseSamplingCreateMessageResult, error) {
+	messageCreator, ok := ctx.Value(messageCreatorKey).(MessageCreator)
+	if !ok {
+		return nil, fmt.Errorf("sampling is not available in this context")
+	}
+	return messageCreator(ctx, request)
+}
+
+// rootsProviderKey is the key used to store the roots provider in the context
+var rootsProviderKey = contextKey("rootsProvider")
+
+// RootsProvider returns the roots of the MCP client
+type RootsProvider func() []mcp.Root
+
+func MakeContextWithRootsProvider(ctx context.Context, rootsProvider RootsProvider) context.Context {
+	return context.WithValue(ctx, rootsProviderKey, rootsProvider)
+}
+
+// GetRoots returns the roots of the MCP client, it returns nil if
+// the client does not support roots
+func GetRoots(ctx context.Context) []mcp.Root {
+	rootsProvider, ok := ctx.Value(rootsProviderKey).(RootsProvider)
+	if !ok {
+		return nil
+	}
+	return rootsProvider()
+}
+
+// elicitorKey is the key used to store the elicitor in the context
+var elicitorKey = contextKey("elicitor")
+
+// Elicitor sends an elicitation request to the MCP client and waits for the answer of the user
+type Elicitor func(ctx context.Context, request *mcp.JsonRpcRequestElicitationCreateParams) (*mcp.JsonRpcResponseElicitationCreateResult, error)
+
+func MakeContextWithElicitor(ctx context.Context, elicitor Elicitor) context.Context {
+	return context.WithValue(ctx, elicitorKey, elicitor)
+}
+
+// Elicit asks the user to fill the fields of the struct pointed by target,
+// the struct is only filled when the user accepts
+func Elicit(ctx context.Context, message string, target interface{}) (mcp.ElicitationAction, error) {
+	elicitor, ok := ctx.Value(elicitorKey).(Elicitor)
+	if !ok {
+		return "", fmt.Errorf("elicitation is not available in this context")
+	}
+
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr || targetType.Elem().Kind() != reflect.Struct {
+		return "", fmt.Errorf("elicitation target must be a pointer to a struct")
+	}
+	schema, _, err := utils.GetSchemaFromType(targetType)
+	if err != nil {
+		return "", fmt.Errorf("error generating schema for elicitation target: %w", err)
+	}
+	// the requested schema is a flat object with primitive properties
+	if schema.Properties != nil {
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			switch pair.Value.Type {
+			case "string", "number", "integer", "boolean":
+			default:
+				return "", fmt.Errorf("elicitation field %s must be a string, a number or a boolean", pair.Key)
+			}
+		}
+	}
+
+	result, err := elicitor(ctx, &mcp.JsonRpcRequestElicitationCreateParams{
+		Message: message,
+		RequestedSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: schema.Properties,
+			Required:   schema.Required,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Action != mcp.ElicitationActionAccept {
+		return result.Action, nil
+	}
+
+	// the content must match the requested schema
+	err = utils.ValidateJsonSchemaWithObject(schema, result.Content)
+	if err != nil {
+		return "", fmt.Errorf("invalid elicitation content: %w", err)
+	}
+	contentBytes, err := json.Marshal(result.Content)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(contentBytes, target); err != nil {
+		return "", fmt.Errorf("invalid elicitation content: %w", err)
+	}
+	return result.Action, nil
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hamstah/gomcp/protocol/mcp"
+)
+
+type workspaceChoice struct {
+	Workspace string `json:"workspace" jsonschema:"enum=dev,enum=prod"`
+	Confirm   bool   `json:"confirm"`
+}
+
+func TestElicit(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *mcp.JsonRpcResponseElicitationCreateResult
+		target     interface{}
+		wantErr    bool
+		wantAction mcp.ElicitationAction
+		want       workspaceChoice
+	}{
+		{
+			name: "accept",
+			result: &mcp.JsonRpcResponseElicitationCreateResult{
+				Action:  mcp.ElicitationActionAccept,
+				Content: map[string]interface{}{"workspace": "dev", "confirm": true},
+			},
+			wantAction: mcp.ElicitationActionAccept,
+			want:       workspaceChoice{Workspace: "dev", Confirm: true},
+		},
+		{
+			name: "decline",
+			result: &mcp.JsonRpcResponseElicitationCreateResult{
+				Action: mcp.ElicitationActionDecline,
+			},
+			wantAction: mcp.ElicitationActionDecline,
+		},
+		{
+			name: "content not matching the schema",
+			result: &mcp.JsonRpcResponseElicitationCreateResult{
+				Action:  mcp.ElicitationActionAccept,
+				Content: map[string]interface{}{"workspace": "staging", "confirm": true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "target is not a pointer to a struct",
+			target:  workspaceChoice{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := MakeContextWithElicitor(context.Background(), func(ctx context.Context, request *mcp.JsonRpcRequestElicitationCreateParams) (*mcp.JsonRpcResponseElicitationCreateResult, error) {
+				return tt.result, nil
+			})
+			choice := workspaceChoice{}
+			target := tt.target
+			if target == nil {
+				target = &choice
+			}
+			action, err := Elicit(ctx, "which workspace?", target)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", action)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if action != tt.wantAction {
+				t.Errorf("expected action %s, got %s", tt.wantAction, action)
+			}
+			if choice != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, choice)
+			}
+		})
+	}
+}
+
+func TestElicitNotAvailable(t *testing.T) {
+	if _, err := Elicit(context.Background(), "which workspace?", &workspaceChoice{}); err == nil {
+		t.Errorf("expected an error without elicitor")
+	}
+}
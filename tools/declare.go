@@ -15,8 +15,13 @@ type ToolDefinition struct {
 	ToolName            string
 	ToolHandlerFunction interface{}
 	Description         string
+	Title               string
+	Annotations         *types.ToolAnnotations
 	InputSchema         *jsonschema.Schema
 	InputTypeName       string
+	// set when the tool handler returns a typed output
+	OutputSchema   *jsonschema.Schema
+	OutputTypeName string
 	// for a tool to be available from a proxy, we need to set the ToolProxyId
 	ToolProxyId string
 }
@@ -139,9 +144,15 @@ func (tp *ToolProvider) AddToolDefinition(toolName string, description string, t
 		InputTypeName:       inputTypeName,
 		ToolProxyId:         "",
 	})
+	return nil
 }
 
 func (tp *ToolProvider) AddTool(toolName string, description string, toolHandler interface{}) error {
+	return tp.AddToolWithOptions(toolName, description, toolHandler, types.ToolOptions{})
+}
+
+// AddToolWithOptions adds a tool with a title and behavior annotations
+func (tp *ToolProvider) AddToolWithOptions(toolName string, description string, toolHandler interface{}, options types.ToolOptions) error {
 	// Validate that toolHandler is a function
 	fnType := reflect.TypeOf(toolHandler)
 	if fnType.Kind() != reflect.Func {
@@ -184,53 +195,89 @@ func (tp *ToolProvider) AddTool(toolName string, description string, toolHandler
 		return fmt.Errorf("toolHandler for %s fourth argument must implement types.ToolCallResult but is %s", toolName, fnType.In(3).String())
 	}
 
-	// the function must return an error
-	if fnType.NumOut() != 1 || fnType.Out(0).String() != "error" {
+	// the function must return an error, optionally preceded by
+	// a pointer to a struct for the structured output of the tool
+	if fnType.NumOut() != 1 && fnType.NumOut() != 2 {
+		return fmt.Errorf("toolHandler for %s must return an error or an output and an error", toolName)
+	}
+	if fnType.Out(fnType.NumOut()-1).String() != "error" {
 		return fmt.Errorf("toolHandler for %s must return an error", toolName)
 	}
+	var outputSchema *jsonschema.Schema
+	var outputTypeName string
+	if fnType.NumOut() == 2 {
+		if fnType.Out(0).Kind() != reflect.Ptr || fnType.Out(0).Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("toolHandler for %s first return value must be a pointer to a struct", toolName)
+		}
+		outputSchema, outputTypeName, err = utils.GetSchemaFromType(fnType.Out(0))
+		if err != nil {
+			return fmt.Errorf("error generating schema for toolHandler for %s output", toolName)
+		}
+	}
 
 	// Store the function for later use
 	if err := tp.AddToolDefinition(toolName, description, toolHandler, inputSchema, inputTypeName); err != nil {
 		return err
 	}
+	toolDefinition := tp.toolDefinitions[len(tp.toolDefinitions)-1]
+	toolDefinition.Title = options.Title
+	toolDefinition.Annotations = options.Annotations
+	toolDefinition.OutputSchema = outputSchema
+	toolDefinition.OutputTypeName = outputTypeName
 	return nil
 }
 
-func (tp *ToolProvider) AddProxyTool(toolName string, description string, inputSchema interface{}) error {
-	// Convert the interface{} to *jsonschema.Schema
-	var schema *jsonschema.Schema
+func (tp *ToolProvider) AddProxyTool(proxyTool ProxyToolDefinition) error {
+	schema, err := toJsonSchema(proxyTool.InputSchema)
+	if err != nil {
+		return err
+	}
+	// the output schema is optional
+	var outputSchema *jsonschema.Schema
+	if proxyTool.OutputSchema != nil {
+		outputSchema, err = toJsonSchema(proxyTool.OutputSchema)
+		if err != nil {
+			return err
+		}
+	}
+
+	toolDefinition := &ToolDefinition{
+		ToolName:     proxyTool.Name,
+		Title:        proxyTool.Title,
+		Description:  proxyTool.Description,
+		Annotations:  proxyTool.Annotations,
+		ToolProxyId:  tp.proxyId,
+		InputSchema:  schema,
+		OutputSchema: outputSchema,
+	}
+
+	// a tool registered again gets a new definition, the sessions
+	// may still read the previous one
+	for i, tool := range tp.toolDefinitions {
+		if tool.ToolName == proxyTool.Name {
+			tp.toolDefinitions[i] = toolDefinition
+			return nil
+		}
+	}
+	tp.toolDefinitions = append(tp.toolDefinitions, toolDefinition)
+	return nil
+}
+
+// toJsonSchema converts the schema of a proxy tool to *jsonschema.Schema
+func toJsonSchema(inputSchema interface{}) (*jsonschema.Schema, error) {
 	switch s := inputSchema.(type) {
 	case *jsonschema.Schema:
-		schema = s
+		return s, nil
 	case map[string]interface{}:
-		schema = &jsonschema.Schema{}
+		schema := &jsonschema.Schema{}
 		// Unmarshal the map into the schema
 		if err := mapToStruct(s, schema); err != nil {
-			return fmt.Errorf("invalid schema format: %v", err)
+			return nil, fmt.Errorf("invalid schema format: %v", err)
 		}
+		return schema, nil
 	default:
-		return fmt.Errorf("inputSchema must be either *jsonschema.Schema or map[string]interface{}")
+		return nil, fmt.Errorf("schema must be either *jsonschema.Schema or map[string]interface{}")
 	}
-
-	// we need to check if the tool name is already registered
-	for _, tool := range tp.toolDefinitions {
-		if tool.ToolName == toolName {
-			// we need to update the tool definition
-			tool.Description = description
-			tool.InputSchema = schema
-			tool.ToolProxyId = tp.proxyId
-			return nil
-		}
-	}
-
-	// we create a new tool definition
-	tp.toolDefinitions = append(tp.toolDefinitions, &ToolDefinition{
-		ToolName:    toolName,
-		Description: description,
-		ToolProxyId: tp.proxyId,
-		InputSchema: schema,
-	})
-	return nil
 }
 
 func mapToStruct(input map[string]interface{}, output interface{}) error {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/hamstah/gomcp/defaults"
+	"github.com/hamstah/gomcp/types"
 	"github.com/hamstah/gomcp/utils"
 	"github.com/invopop/jsonschema"
 )
@@ -21,13 +22,17 @@ type ProxyDefinition struct {
 	ProxyName        string                `json:"proxyName"`
 	ProgramName      string                `json:"programName"`
 	ProgramArguments []string              `json:"programArguments"`
+	ServerUrl        string                `json:"serverUrl,omitempty"`
 	Tools            []ProxyToolDefinition `json:"tools"`
 }
 
 type ProxyToolDefinition struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Title        string                 `json:"title,omitempty"`
+	Description  string                 `json:"description"`
+	InputSchema  interface{}            `json:"inputSchema"`
+	OutputSchema interface{}            `json:"outputSchema,omitempty"`
+	Annotations  *types.ToolAnnotations `json:"annotations,omitempty"`
 }
 
 func NewProxyTools() *ProxyTools {
@@ -89,7 +94,7 @@ func (t *ProxyTools) RegisterProxyTools(toolsRegistry *ToolsRegistry) error {
 
 		// register the proxy tools
 		for _, tool := range def.Tools {
-			err := toolProvider.AddProxyTool(tool.Name, tool.Description, tool.InputSchema)
+			err := toolProvider.AddProxyTool(tool)
 			if err != nil {
 				return err
 			}
@@ -65,7 +65,9 @@ export const BlobResourceContentsSchema = ResourceContentsSchema.extend({
 
 type ToolCallResultImpl struct {
 	Content []interface{} `json:"content"`
-	IsError *bool         `json:"isError,omitempty"`
+	// the typed output of the tool, if the tool declares an output schema
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           *bool       `json:"isError,omitempty"`
 }
 
 func NewToolCallResult() types.ToolCallResult {
@@ -102,6 +104,31 @@ func (r *ToolCallResultImpl) AddImageContent(mimeType string, base64Data string)
 	})
 }
 
+func (r *ToolCallResultImpl) AddAudioContent(mimeType string, base64Data string) {
+	r.Content = append(r.Content, map[string]interface{}{
+		"type":     "audio",
+		"data":     base64Data,
+		"mimeType": mimeType,
+	})
+}
+
+// AddResourceLinkContent references a resource without inlining its content,
+// the description and the mime type are optional
+func (r *ToolCallResultImpl) AddResourceLinkContent(uri string, name string, description string, mimeType string) {
+	content := map[string]interface{}{
+		"type": "resource_link",
+		"uri":  uri,
+		"name": name,
+	}
+	if description != "" {
+		content["description"] = description
+	}
+	if mimeType != "" {
+		content["mimeType"] = mimeType
+	}
+	r.Content = append(r.Content, content)
+}
+
 func (r *ToolCallResultImpl) AddEmbeddedResourceTextContent(uri string, mimeType string, text string) {
 	r.Content = append(r.Content, map[string]interface{}{
 		"type": "resource",
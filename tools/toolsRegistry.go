@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 
 	"github.com/hamstah/gomcp/config"
 	"github.com/hamstah/gomcp/types"
@@ -19,8 +22,10 @@ type toolProviderPrepared struct {
 
 type ToolsRegistry struct {
 	ToolProviders []*ToolProvider
-	Tools         map[string]*toolProviderPrepared
-	logger        types.Logger
+	// the tools of the proxies are added while the sessions list and call the tools
+	Tools  map[string]*toolProviderPrepared
+	logger types.Logger
+	mutex  sync.RWMutex
 }
 
 func NewToolsRegistry(loadProxyTools bool, logger types.Logger) *ToolsRegistry {
@@ -38,6 +43,8 @@ func NewToolsRegistry(loadProxyTools bool, logger types.Logger) *ToolsRegistry {
 }
 
 func (r *ToolsRegistry) RegisterToolProvider(toolProvider *ToolProvider) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.ToolProviders = append(r.ToolProviders, toolProvider)
 	r.logger.Info("registered tool provider", types.LogArg{
 		"tool":            toolProvider.toolName,
@@ -49,6 +56,8 @@ func (r *ToolsRegistry) RegisterToolProvider(toolProvider *ToolProvider) error {
 }
 
 func (r *ToolsRegistry) RegisterProxyToolProvider(proxyId string, proxyName string) (*ToolProvider, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	// check if the proxy tool provider is already registered
 	for _, toolProvider := range r.ToolProviders {
 		if toolProvider.proxyId == proxyId {
@@ -65,6 +74,8 @@ func (r *ToolsRegistry) RegisterProxyToolProvider(proxyId string, proxyName stri
 }
 
 func (r *ToolsRegistry) PrepareProxyToolProvider(toolProvider *ToolProvider) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	for _, toolDefinition := range toolProvider.toolDefinitions {
 		r.Tools[toolDefinition.ToolName] = &toolProviderPrepared{
 			ToolProvider:   toolProvider,
@@ -141,7 +152,7 @@ func (r *ToolsRegistry) initializeProviders(ctx context.Context, toolConfigs []c
 					logger := types.NewSubLogger(r.logger, types.LogArg{
 						"tool": toolProvider.toolName,
 					})
-					ctx := makeContextWithLogger(ctx, logger)
+					ctx := MakeContextWithLogger(ctx, logger)
 					result, callErr, err := utils.CallFunction(toolProvider.toolInitFunction, ctx, toolConfig.Configuration)
 					if err != nil {
 						return err
@@ -162,6 +173,8 @@ func (r *ToolsRegistry) initializeProviders(ctx context.Context, toolConfigs []c
 }
 
 func (r *ToolsRegistry) Prepare(ctx context.Context, toolConfigs []config.ToolConfig) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	// we check that the configuration for each tool provider is valid
 	err := r.checkConfiguration(toolConfigs)
 	if err != nil {
@@ -202,14 +215,22 @@ func (r *ToolsRegistry) Prepare(ctx context.Context, toolConfigs []config.ToolCo
 }
 
 func (r *ToolsRegistry) GetListOfTools() []*ToolDefinition {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 	tools := make([]*ToolDefinition, 0, len(r.Tools))
 	for _, tool := range r.Tools {
 		tools = append(tools, tool.ToolDefinition)
 	}
+	// the tools are stored in a map, we sort them to get a stable order
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].ToolName < tools[j].ToolName
+	})
 	return tools
 }
 
 func (r *ToolsRegistry) getTool(toolName string) (*ToolDefinition, *ToolProvider, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 	tool, ok := r.Tools[toolName]
 	if !ok {
 		return nil, nil, fmt.Errorf("tool %s not found", toolName)
@@ -241,12 +262,16 @@ func (r *ToolsRegistry) CallTool(ctx context.Context, toolName string, toolArgs
 	logger := types.NewSubLogger(r.logger, types.LogArg{
 		"tool": toolProvider.toolName,
 	})
-	goCtx := makeContextWithLogger(ctx, logger)
+	// the logs are sent to the client of the session calling the tool
+	if logForwarder := GetLogForwarder(ctx); logForwarder != nil {
+		logger = types.NewForwardingLogger(logger, toolProvider.toolName, logForwarder)
+	}
+	goCtx := MakeContextWithLogger(ctx, logger)
 
 	// let's create the output
 	output := NewToolCallResult()
 
-	_, callErr, err := utils.CallFunction(toolDefinition.ToolHandlerFunction, goCtx, toolProvider.toolContext, toolArgs, output)
+	result, callErr, err := utils.CallFunction(toolDefinition.ToolHandlerFunction, goCtx, toolProvider.toolContext, toolArgs, output)
 	if err != nil {
 		return nil, err
 	}
@@ -254,5 +279,21 @@ func (r *ToolsRegistry) CallTool(ctx context.Context, toolName string, toolArgs
 		return nil, callErr
 	}
 
+	// the tool returns a typed output
+	if toolDefinition.OutputSchema != nil {
+		if reflect.ValueOf(result).IsNil() {
+			return nil, fmt.Errorf("tool %s returned no output", toolName)
+		}
+		// the output must match the schema announced to the client
+		err = utils.ValidateJsonSchemaWithObject(toolDefinition.OutputSchema, result)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output for tool %s: %w", toolName, err)
+		}
+		toolCallResult := output.(*ToolCallResultImpl)
+		toolCallResult.StructuredContent = result
+		// the clients that don't support structured output read the text content
+		toolCallResult.AddJSONTextContent(result)
+	}
+
 	return output, nil
 }
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+type weatherContext struct{}
+
+type weatherInput struct {
+	City string `json:"city"`
+}
+
+type weatherOutput struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit" jsonschema:"enum=celsius,enum=fahrenheit"`
+}
+
+func initWeather(ctx context.Context) (*weatherContext, error) {
+	return &weatherContext{}, nil
+}
+
+func getWeather(ctx context.Context, weatherCtx *weatherContext, input *weatherInput, output types.ToolCallResult) (*weatherOutput, error) {
+	unit := "celsius"
+	if input.City == "nowhere" {
+		unit = "kelvin"
+	}
+	return &weatherOutput{City: input.City, Temperature: 21.5, Unit: unit}, nil
+}
+
+func TestCallToolWithStructuredOutput(t *testing.T) {
+	toolProvider, err := DeclareToolProvider("weather", initWeather)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := toolProvider.AddTool("get_weather", "Get the weather", getWeather); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	toolProvider.toolContext = &weatherContext{}
+
+	registry := NewToolsRegistry(false, nopLogger{})
+	registry.RegisterToolProvider(toolProvider)
+	if err := registry.Prepare(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if registry.GetListOfTools()[0].OutputSchema == nil {
+		t.Fatalf("expected an output schema")
+	}
+
+	tests := []struct {
+		name    string
+		city    string
+		wantErr bool
+	}{
+		{
+			name: "valid output",
+			city: "Paris",
+		},
+		{
+			name:    "output not matching the schema",
+			city:    "nowhere",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := registry.CallTool(context.Background(), "get_weather", map[string]interface{}{"city": tt.city})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			toolCallResult := result.(*ToolCallResultImpl)
+			output, ok := toolCallResult.StructuredContent.(*weatherOutput)
+			if !ok || output.City != tt.city {
+				t.Errorf("expected structured content for %s, got %+v", tt.city, toolCallResult.StructuredContent)
+			}
+			// the JSON text fallback is added for older clients
+			if len(toolCallResult.Content) != 1 {
+				t.Errorf("expected 1 text content, got %d", len(toolCallResult.Content))
+			}
+		})
+	}
+}
+
+func TestAddToolInvalidOutput(t *testing.T) {
+	toolProvider, err := DeclareToolProvider("weather", initWeather)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	invalidHandler := func(ctx context.Context, weatherCtx *weatherContext, input *weatherInput, output types.ToolCallResult) (string, error) {
+		return "", nil
+	}
+	if err := toolProvider.AddTool("get_weather", "Get the weather", invalidHandler); err == nil {
+		t.Errorf("expected an error for an output that is not a pointer to a struct")
+	}
+}
+
+func TestProxyToolsRegisteredConcurrently(t *testing.T) {
+	registry := NewToolsRegistry(false, nopLogger{})
+
+	// the proxies register their tools while the sessions list and call them
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			proxyId := fmt.Sprintf("proxy-%d", i)
+			toolProvider, err := registry.RegisterProxyToolProvider(proxyId, proxyId)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			toolProvider.AddProxyTool(ProxyToolDefinition{
+				Name:        proxyId + "_list",
+				InputSchema: map[string]interface{}{"type": "object"},
+			})
+			registry.PrepareProxyToolProvider(toolProvider)
+		}(i)
+		go func() {
+			defer wg.Done()
+			for _, tool := range registry.GetListOfTools() {
+				registry.IsProxyTool(tool.ToolName)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tools := registry.GetListOfTools(); len(tools) != 10 {
+		t.Errorf("expected the 10 tools of the proxies, got %d", len(tools))
+	}
+	if isProxy, proxyId, err := registry.IsProxyTool("proxy-3_list"); err != nil || !isProxy || proxyId != "proxy-3" {
+		t.Errorf("expected the tool of proxy-3, got %v %q %v", isProxy, proxyId, err)
+	}
+}
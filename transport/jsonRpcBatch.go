@@ -0,0 +1,177 @@
+package transport
+
+import (
+	"encoding/json"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/types"
+)
+
+// jsonRpcBatch collects the responses to the requests of a batch,
+// they are sent in a single array once all the requests are answered
+type jsonRpcBatch struct {
+	responses []json.RawMessage
+	// number of requests of the batch not answered yet
+	remaining int
+}
+
+// SetBatchesEnabled accepts or rejects the JSON-RPC batches,
+// they are rejected by default
+func (t *JsonRpcTransport) SetBatchesEnabled(enabled bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.batchesEnabled = enabled
+}
+
+func (t *JsonRpcTransport) handleBatch(message json.RawMessage, onMessage func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport)) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(message, &items); err != nil {
+		t.SendError(jsonrpc.RpcParseError, "parse error", nil)
+		return
+	}
+
+	t.mutex.Lock()
+	batchesEnabled := t.batchesEnabled
+	t.mutex.Unlock()
+	if !batchesEnabled {
+		t.logger.Error("batch requests not supported", types.LogArg{
+			"name": t.name,
+		})
+		t.SendError(jsonrpc.RpcInvalidRequest, "batch requests are not supported", nil)
+		return
+	}
+	// an empty array is an invalid request, answered with a single response
+	if len(items) == 0 {
+		t.SendError(jsonrpc.RpcInvalidRequest, "empty batch", nil)
+		return
+	}
+
+	type batchItem struct {
+		nature  jsonrpc.MessageNature
+		message jsonrpc.JsonRpcRawMessage
+	}
+	batch := &jsonRpcBatch{}
+	batchItems := []batchItem{}
+	requestIds := map[string]bool{}
+	for _, item := range items {
+		nature, jsonRpcRawMessage, err := jsonrpc.CheckJsonMessage(item)
+		if err != nil {
+			// invalid items are answered in the batch without id
+			batch.addError(jsonrpc.RpcInvalidRequest, "Invalid Request", err.Error(), nil)
+			continue
+		}
+		if nature == jsonrpc.MessageNatureBatchRequest {
+			batch.addError(jsonrpc.RpcInvalidRequest, "Invalid Request", "nested batch", nil)
+			continue
+		}
+		// the invalid requests and notifications are answered before
+		// the batch is registered, the valid ones are dispatched
+		if nature == jsonrpc.MessageNatureRequest || nature == jsonrpc.MessageNatureNotification {
+			if _, requestId, rpcErr := jsonrpc.ParseJsonRpcRequest(jsonRpcRawMessage); rpcErr != nil {
+				batch.addError(jsonrpc.RpcInvalidRequest, "Invalid Request", rpcErr.Message, requestId)
+				continue
+			}
+		}
+		if nature == jsonrpc.MessageNatureRequest {
+			requestId := jsonrpc.RequestIdFromValue(jsonRpcRawMessage["id"])
+			if requestId == nil {
+				batch.addError(jsonrpc.RpcInvalidRequest, "Invalid Request", "invalid request id", nil)
+				continue
+			}
+			key := jsonrpc.RequestIdToString(requestId)
+			if requestIds[key] {
+				batch.addError(jsonrpc.RpcInvalidRequest, "Invalid Request", "duplicate request id in batch", requestId)
+				continue
+			}
+			requestIds[key] = true
+		}
+		batchItems = append(batchItems, batchItem{nature: nature, message: jsonRpcRawMessage})
+	}
+
+	// the requests are registered before being dispatched
+	// so that their responses are collected in the batch
+	t.mutex.Lock()
+	for key := range requestIds {
+		t.batches[key] = batch
+	}
+	batch.remaining = len(requestIds)
+	t.mutex.Unlock()
+	if len(requestIds) == 0 {
+		// only notifications, responses or invalid items
+		t.sendBatch(batch)
+	}
+
+	for _, item := range batchItems {
+		t.dispatchMessage(item.nature, item.message, onMessage)
+	}
+}
+
+func (b *jsonRpcBatch) addError(code int, message string, data string, id *jsonrpc.JsonRpcRequestId) {
+	dataBytes, _ := json.Marshal(data)
+	rawData := json.RawMessage(dataBytes)
+	jsonMessage, err := jsonrpc.MarshalJsonRpcResponse(&jsonrpc.JsonRpcResponse{
+		Error: &jsonrpc.JsonRpcError{
+			Code:    code,
+			Message: message,
+			Data:    &rawData,
+		},
+		Id: id,
+	})
+	if err != nil {
+		return
+	}
+	b.responses = append(b.responses, jsonMessage)
+}
+
+// addToBatch keeps the response if it answers a request of a batch,
+// the batch is sent when its last request is answered
+func (t *JsonRpcTransport) addToBatch(id *jsonrpc.JsonRpcRequestId, jsonMessage json.RawMessage) bool {
+	if id == nil {
+		return false
+	}
+	key := jsonrpc.RequestIdToString(id)
+	t.mutex.Lock()
+	batch, ok := t.batches[key]
+	if !ok {
+		t.mutex.Unlock()
+		return false
+	}
+	delete(t.batches, key)
+	if jsonMessage != nil {
+		batch.responses = append(batch.responses, jsonMessage)
+	}
+	batch.remaining--
+	complete := batch.remaining == 0
+	t.mutex.Unlock()
+
+	if complete {
+		t.sendBatch(batch)
+	}
+	return true
+}
+
+// DiscardResponse is called when a request won't be answered (it was cancelled)
+// so that the batch containing it is not waiting for its response
+func (t *JsonRpcTransport) DiscardResponse(id *jsonrpc.JsonRpcRequestId) {
+	t.addToBatch(id, nil)
+}
+
+func (t *JsonRpcTransport) sendBatch(batch *jsonRpcBatch) {
+	// nothing is sent if there are no responses
+	if len(batch.responses) == 0 {
+		return
+	}
+	jsonMessage, err := json.Marshal(batch.responses)
+	if err != nil {
+		t.logger.Error("error marshalling batch", types.LogArg{
+			"error": err,
+		})
+		return
+	}
+	if err := t.transport.Send(jsonMessage); err != nil {
+		t.logger.Error("error sending batch", types.LogArg{
+			"error": err,
+			"name":  t.name,
+		})
+	}
+}
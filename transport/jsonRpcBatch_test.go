@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(message string, fields types.LogArg)  {}
+func (nopLogger) Debug(message string, fields types.LogArg) {}
+func (nopLogger) Error(message string, fields types.LogArg) {}
+func (nopLogger) Fatal(message string, fields types.LogArg) {}
+
+// fakeTransport records the messages sent
+type fakeTransport struct {
+	sent []json.RawMessage
+}
+
+func (f *fakeTransport) Start(ctx context.Context) error { return nil }
+func (f *fakeTransport) Send(message json.RawMessage) error {
+	f.sent = append(f.sent, message)
+	return nil
+}
+func (f *fakeTransport) OnMessage(callback func(json.RawMessage)) {}
+func (f *fakeTransport) Close()                                   {}
+func (f *fakeTransport) OnStarted(callback func())                {}
+func (f *fakeTransport) OnClose(callback func())                  {}
+func (f *fakeTransport) OnError(callback func(error))             {}
+
+func TestJsonRpcBatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		batchesDisabled bool
+		message         string
+		// number of messages sent and number of responses in the batch array
+		wantSent      int
+		wantResponses int
+		wantError     int
+	}{
+		{
+			name:          "requests and notification",
+			message:       `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":"a","method":"ping"}]`,
+			wantSent:      1,
+			wantResponses: 2,
+		},
+		{
+			name:          "request answered later",
+			message:       `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"later"}]`,
+			wantSent:      1,
+			wantResponses: 2,
+		},
+		{
+			name:     "only notifications",
+			message:  `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+			wantSent: 0,
+		},
+		{
+			name:          "invalid items",
+			message:       `[1, {"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+			wantSent:      1,
+			wantResponses: 2,
+		},
+		{
+			name:      "empty batch",
+			message:   `[]`,
+			wantSent:  1,
+			wantError: jsonrpc.RpcInvalidRequest,
+		},
+		{
+			name:            "batches disabled",
+			batchesDisabled: true,
+			message:         `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+			wantSent:        1,
+			wantError:       jsonrpc.RpcInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTransport{}
+			jsonRpcTransport := NewJsonRpcTransport(fake, "test", nopLogger{})
+			jsonRpcTransport.SetBatchesEnabled(!tt.batchesDisabled)
+
+			var later *jsonrpc.JsonRpcRequestId
+			jsonRpcTransport.handleMessage(json.RawMessage(tt.message), func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+				if message.Request.Id == nil {
+					return
+				}
+				if message.Method == "later" {
+					later = message.Request.Id
+					return
+				}
+				jsonRpcTransport.SendResponseWithResults(message.Request.Id, map[string]interface{}{})
+			})
+			if later != nil {
+				if len(fake.sent) != 0 {
+					t.Fatalf("expected the batch to wait for all the responses, got %s", fake.sent[0])
+				}
+				jsonRpcTransport.SendResponseWithResults(later, map[string]interface{}{})
+			}
+
+			if len(fake.sent) != tt.wantSent {
+				t.Fatalf("expected %d messages sent, got %d", tt.wantSent, len(fake.sent))
+			}
+			if tt.wantSent == 0 {
+				return
+			}
+			if tt.wantError != 0 {
+				var response struct {
+					Error struct {
+						Code int `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(fake.sent[0], &response); err != nil {
+					t.Fatalf("expected a single error response, got %s", fake.sent[0])
+				}
+				if response.Error.Code != tt.wantError {
+					t.Errorf("expected error %d, got %d", tt.wantError, response.Error.Code)
+				}
+				return
+			}
+			var responses []map[string]interface{}
+			if err := json.Unmarshal(fake.sent[0], &responses); err != nil {
+				t.Fatalf("expected a batch response, got %s", fake.sent[0])
+			}
+			if len(responses) != tt.wantResponses {
+				t.Errorf("expected %d responses, got %d: %s", tt.wantResponses, len(responses), fake.sent[0])
+			}
+		})
+	}
+}
+
+func TestJsonRpcBatchDiscardResponse(t *testing.T) {
+	fake := &fakeTransport{}
+	jsonRpcTransport := NewJsonRpcTransport(fake, "test", nopLogger{})
+	jsonRpcTransport.SetBatchesEnabled(true)
+
+	message := `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"cancelled"}]`
+	jsonRpcTransport.handleMessage(json.RawMessage(message), func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+		if message.Method == "ping" {
+			jsonRpcTransport.SendResponseWithResults(message.Request.Id, map[string]interface{}{})
+		}
+	})
+	// the cancelled request is not answered
+	jsonRpcTransport.DiscardResponse(jsonrpc.RequestIdFromValue(float64(2)))
+
+	if len(fake.sent) != 1 {
+		t.Fatalf("expected the batch to be sent, got %d messages", len(fake.sent))
+	}
+	var responses []map[string]interface{}
+	if err := json.Unmarshal(fake.sent[0], &responses); err != nil || len(responses) != 1 {
+		t.Errorf("expected a batch with 1 response, got %s", fake.sent[0])
+	}
+}
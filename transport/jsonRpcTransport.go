@@ -1,9 +1,11 @@
 package transport
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/hamstah/gomcp/jsonrpc"
 	"github.com/hamstah/gomcp/types"
@@ -21,7 +23,14 @@ type JsonRpcTransport struct {
 	onStarted     func()
 	// pendingRequests is a map of message id to pending request
 	pendingRequests map[string]*pendingRequest
-	name            string
+	// protects the request ids and the pending requests, requests can be
+	// sent from the handlers running concurrently with the read loop
+	mutex sync.Mutex
+	name  string
+	// JSON-RPC batches are only accepted by some protocol versions
+	batchesEnabled bool
+	// batches waiting for responses, by request id
+	batches map[string]*jsonRpcBatch
 }
 
 type JsonRpcMessage struct {
@@ -65,6 +74,7 @@ func NewJsonRpcTransport(transport types.Transport, name string, logger types.Lo
 		lastRequestId:   0,
 		pendingRequests: make(map[string]*pendingRequest),
 		name:            name,
+		batches:         make(map[string]*jsonRpcBatch),
 	}
 }
 
@@ -83,93 +93,7 @@ func (t *JsonRpcTransport) Start(ctx context.Context, onMessage func(message Jso
 		case <-ctx.Done():
 			return
 		default:
-			t.logger.Debug("message received", types.LogArg{
-				"name":    t.name,
-				"message": string(message),
-			})
-			// check the message nature
-			nature, jsonRpcRawMessage, err := jsonrpc.CheckJsonMessage(message)
-			if err != nil {
-				t.logger.Error("error checking message", types.LogArg{
-					"message": string(message),
-					"error":   err,
-					"name":    t.name,
-				})
-				return
-			}
-
-			// the MCP protocol does not support batch requests
-			switch nature {
-			case jsonrpc.MessageNatureBatchRequest:
-				t.logger.Error("batch requests not supported in protocol", types.LogArg{})
-				return
-			case jsonrpc.MessageNatureRequest:
-				request, _, rpcErr := jsonrpc.ParseJsonRpcRequest(jsonRpcRawMessage)
-				if rpcErr != nil {
-					t.logger.Error("error parsing request", types.LogArg{
-						"error": rpcErr,
-						"name":  t.name,
-					})
-					return
-				}
-				onMessage(JsonRpcMessage{
-					Request:  request,
-					Method:   request.Method,
-					Response: nil,
-				}, t)
-
-			case jsonrpc.MessageNatureResponse:
-				response, _, rpcErr := jsonrpc.ParseJsonRpcResponse(jsonRpcRawMessage)
-
-				if rpcErr != nil {
-					t.logger.Error("error parsing response", types.LogArg{
-						"error": rpcErr,
-						"name":  t.name,
-					})
-					return
-				}
-				pendingRequestMethod, reqId := t.GetPendingRequest(response.Id)
-				if pendingRequestMethod == "" {
-					t.logger.Error("pending request method not found", types.LogArg{
-						"requestId": jsonrpc.RequestIdToString(response.Id),
-						"name":      t.name,
-						"response":  response,
-					})
-					return
-				}
-				t.logger.Info("pending request method found", types.LogArg{
-					"method": pendingRequestMethod,
-					"name":   t.name,
-					"id":     jsonrpc.RequestIdToString(reqId),
-				})
-				onMessage(JsonRpcMessage{
-					Response: response,
-					Method:   pendingRequestMethod,
-				}, t)
-			case jsonrpc.MessageNatureNotification:
-				t.logger.Info("notification received", types.LogArg{
-					"name": t.name,
-				})
-				request, _, rpcErr := jsonrpc.ParseJsonRpcRequest(jsonRpcRawMessage)
-				if rpcErr != nil {
-					t.logger.Error("error parsing notification", types.LogArg{
-						"error": rpcErr,
-						"name":  t.name,
-					})
-					return
-				}
-				onMessage(JsonRpcMessage{
-					Request:  request,
-					Method:   request.Method,
-					Response: nil,
-				}, t)
-			default:
-				t.logger.Error("invalid message nature", types.LogArg{
-					"nature": nature,
-					"name":   t.name,
-				})
-				return
-			}
+			t.handleMessage(message, onMessage)
 		}
 	})
 
@@ -212,16 +136,166 @@ func (t *JsonRpcTransport) Start(ctx context.Context, onMessage func(message Jso
 	}
 }
 
+func (t *JsonRpcTransport) handleMessage(message json.RawMessage, onMessage func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport)) {
+	t.logger.Debug("message received", types.LogArg{
+		"name":    t.name,
+		"message": string(message),
+	})
+	// blank lines between messages are not messages
+	if len(bytes.TrimSpace(message)) == 0 {
+		return
+	}
+	// check the message nature
+	nature, jsonRpcRawMessage, err := jsonrpc.CheckJsonMessage(message)
+	if err != nil {
+		t.logger.Error("error checking message", types.LogArg{
+			"message": string(message),
+			"error":   err,
+			"name":    t.name,
+		})
+		t.answerInvalidMessage(message, err)
+		return
+	}
+
+	if nature == jsonrpc.MessageNatureBatchRequest {
+		t.handleBatch(message, onMessage)
+		return
+	}
+	requestId, rpcErr := t.dispatchMessage(nature, jsonRpcRawMessage, onMessage)
+	if rpcErr != nil {
+		t.SendErrorWithData(jsonrpc.RpcInvalidRequest, "Invalid Request", rpcErr.Message, requestId)
+	}
+}
+
+// answerInvalidMessage answers a message that is not a JSON-RPC message
+// so that the client does not wait for a response
+func (t *JsonRpcTransport) answerInvalidMessage(message json.RawMessage, err error) {
+	var rawJson interface{}
+	if json.Unmarshal(message, &rawJson) != nil {
+		t.SendErrorWithData(jsonrpc.RpcParseError, "Parse error", err.Error(), nil)
+		return
+	}
+	// the id is sent back when it can be recovered, null otherwise
+	var requestId *jsonrpc.JsonRpcRequestId
+	if object, ok := rawJson.(map[string]interface{}); ok {
+		// an invalid response is never answered
+		_, hasMethod := object["method"]
+		_, hasResult := object["result"]
+		_, hasError := object["error"]
+		if !hasMethod && (hasResult || hasError) {
+			return
+		}
+		requestId = jsonrpc.RequestIdFromValue(object["id"])
+	}
+	t.SendErrorWithData(jsonrpc.RpcInvalidRequest, "Invalid Request", err.Error(), requestId)
+}
+
+// dispatchMessage parses a single message and passes it to onMessage,
+// it returns the error to answer when the message is an invalid request
+// or notification, with the id of the request if there is one
+func (t *JsonRpcTransport) dispatchMessage(nature jsonrpc.MessageNature, jsonRpcRawMessage jsonrpc.JsonRpcRawMessage, onMessage func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport)) (*jsonrpc.JsonRpcRequestId, *jsonrpc.JsonRpcError) {
+	switch nature {
+	case jsonrpc.MessageNatureRequest:
+		request, requestId, rpcErr := jsonrpc.ParseJsonRpcRequest(jsonRpcRawMessage)
+		if rpcErr != nil {
+			t.logger.Error("error parsing request", types.LogArg{
+				"error": rpcErr,
+				"name":  t.name,
+			})
+			return requestId, rpcErr
+		}
+		onMessage(JsonRpcMessage{
+			Request:  request,
+			Method:   request.Method,
+			Response: nil,
+		}, t)
+
+	case jsonrpc.MessageNatureResponse:
+		response, _, rpcErr := jsonrpc.ParseJsonRpcResponse(jsonRpcRawMessage)
+
+		if rpcErr != nil {
+			t.logger.Error("error parsing response", types.LogArg{
+				"error": rpcErr,
+				"name":  t.name,
+			})
+			return nil, nil
+		}
+		pendingRequestMethod, reqId := t.GetPendingRequest(response.Id)
+		if pendingRequestMethod == "" {
+			t.logger.Error("pending request method not found", types.LogArg{
+				"requestId": jsonrpc.RequestIdToString(response.Id),
+				"name":      t.name,
+				"response":  response,
+			})
+			return nil, nil
+		}
+		t.logger.Info("pending request method found", types.LogArg{
+			"method": pendingRequestMethod,
+			"name":   t.name,
+			"id":     jsonrpc.RequestIdToString(reqId),
+		})
+		onMessage(JsonRpcMessage{
+			Response: response,
+			Method:   pendingRequestMethod,
+		}, t)
+	case jsonrpc.MessageNatureNotification:
+		t.logger.Info("notification received", types.LogArg{
+			"name": t.name,
+		})
+		request, _, rpcErr := jsonrpc.ParseJsonRpcRequest(jsonRpcRawMessage)
+		if rpcErr != nil {
+			t.logger.Error("error parsing notification", types.LogArg{
+				"error": rpcErr,
+				"name":  t.name,
+			})
+			return nil, rpcErr
+		}
+		onMessage(JsonRpcMessage{
+			Request:  request,
+			Method:   request.Method,
+			Response: nil,
+		}, t)
+	default:
+		t.logger.Error("invalid message nature", types.LogArg{
+			"nature": nature,
+			"name":   t.name,
+		})
+		return nil, &jsonrpc.JsonRpcError{
+			Code:    jsonrpc.RpcInvalidRequest,
+			Message: "unknown message nature",
+		}
+	}
+	return nil, nil
+}
+
 func (t *JsonRpcTransport) SendRequestWithMethodAndParams(method string, params interface{}) (*jsonrpc.JsonRpcRequestId, error) {
 	requestId := t.GetNextRequestId()
+	return requestId, t.SendRequestWithIdMethodAndParams(requestId, method, params)
+}
+
+// SendRequestWithIdMethodAndParams sends a request with an id given by GetNextRequestId,
+// the caller can get ready for the response before sending the request
+func (t *JsonRpcTransport) SendRequestWithIdMethodAndParams(requestId *jsonrpc.JsonRpcRequestId, method string, params interface{}) error {
 	request := buildJsonRpcRequestWithNamedParams(
 		method, params, requestId)
 
 	if request == nil {
-		return nil, fmt.Errorf("failed to create initialize request")
+		return fmt.Errorf("failed to create %s request", method)
+	}
+
+	return t.SendRequest(request)
+}
+
+func (t *JsonRpcTransport) SendNotificationWithParams(method string, params interface{}) error {
+	// a notification is a request without id
+	notification := buildJsonRpcRequestWithNamedParams(
+		method, params, nil)
+
+	if notification == nil {
+		return fmt.Errorf("failed to create %s notification", method)
 	}
 
-	return requestId, t.SendRequest(request)
+	return t.SendRequest(notification)
 }
 
 func (t *JsonRpcTransport) SendResponseWithResults(reqId *jsonrpc.JsonRpcRequestId, result interface{}) error {
@@ -246,10 +320,12 @@ func (t *JsonRpcTransport) SendRequest(request *jsonrpc.JsonRpcRequest) error {
 	// we store the request in the pending requests map
 	// so we can match the response with the request
 	if request.Id != nil {
+		t.mutex.Lock()
 		t.pendingRequests[jsonrpc.RequestIdToString(request.Id)] = &pendingRequest{
 			method:    request.Method,
 			requestId: request.Id,
 		}
+		t.mutex.Unlock()
 	}
 
 	t.logger.Info("sending request", types.LogArg{
@@ -270,10 +346,18 @@ func (t *JsonRpcTransport) SendResponse(response *jsonrpc.JsonRpcResponse) error
 		})
 		return err
 	}
+	if t.addToBatch(response.Id, jsonMessage) {
+		return nil
+	}
 	return t.transport.Send(jsonMessage)
 }
 
 func (t *JsonRpcTransport) SendError(code int, message string, id *jsonrpc.JsonRpcRequestId) error {
+	return t.SendErrorWithData(code, message, nil, id)
+}
+
+// SendErrorWithData sends an error with details in its data field, data is omitted if nil
+func (t *JsonRpcTransport) SendErrorWithData(code int, message string, data interface{}, id *jsonrpc.JsonRpcRequestId) error {
 	response := &jsonrpc.JsonRpcResponse{
 		Error: &jsonrpc.JsonRpcError{
 			Code:    code,
@@ -281,6 +365,14 @@ func (t *JsonRpcTransport) SendError(code int, message string, id *jsonrpc.JsonR
 		},
 		Id: id,
 	}
+	if data != nil {
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		rawData := json.RawMessage(dataBytes)
+		response.Error.Data = &rawData
+	}
 
 	jsonMessage, err := jsonrpc.MarshalJsonRpcResponse(response)
 	if err != nil {
@@ -289,6 +381,9 @@ func (t *JsonRpcTransport) SendError(code int, message string, id *jsonrpc.JsonR
 		})
 		return err
 	}
+	if t.addToBatch(id, jsonMessage) {
+		return nil
+	}
 	// send the message
 	return t.transport.Send(jsonMessage)
 
@@ -299,6 +394,8 @@ func (t *JsonRpcTransport) Close() {
 }
 
 func (t *JsonRpcTransport) GetNextRequestId() *jsonrpc.JsonRpcRequestId {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 	requestId := t.lastRequestId
 	t.lastRequestId++
 	return &jsonrpc.JsonRpcRequestId{
@@ -313,6 +410,8 @@ func (t *JsonRpcTransport) GetPendingRequest(reqId *jsonrpc.JsonRpcRequestId) (s
 		return "", nil
 	}
 	reqIdStr := jsonrpc.RequestIdToString(reqId)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 	pendingRequest := t.pendingRequests[reqIdStr]
 	if pendingRequest == nil {
 		t.logger.Error("pending request not found", types.LogArg{
@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+)
+
+func TestJsonRpcTransportInvalidMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantSent bool
+		wantCode int
+		wantId   string
+	}{
+		{
+			name:     "invalid JSON",
+			message:  `{"jsonrpc": "2.0", "method": "ping"`,
+			wantSent: true,
+			wantCode: jsonrpc.RpcParseError,
+			wantId:   "null",
+		},
+		{
+			name:     "missing jsonrpc field with id",
+			message:  `{"method": "ping", "id": 3}`,
+			wantSent: true,
+			wantCode: jsonrpc.RpcInvalidRequest,
+			wantId:   "3",
+		},
+		{
+			name:     "not an object",
+			message:  `42`,
+			wantSent: true,
+			wantCode: jsonrpc.RpcInvalidRequest,
+			wantId:   "null",
+		},
+		{
+			name:     "invalid method",
+			message:  `{"jsonrpc": "2.0", "method": 1, "id": "a"}`,
+			wantSent: true,
+			wantCode: jsonrpc.RpcInvalidRequest,
+			wantId:   `"a"`,
+		},
+		{
+			name:     "invalid notification",
+			message:  `{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+			wantSent: true,
+			wantCode: jsonrpc.RpcInvalidRequest,
+			wantId:   "null",
+		},
+		{
+			name:    "invalid response is not answered",
+			message: `{"jsonrpc": "1.0", "result": {}, "id": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTransport{}
+			jsonRpcTransport := NewJsonRpcTransport(fake, "test", nopLogger{})
+			jsonRpcTransport.handleMessage(json.RawMessage(tt.message), func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+				t.Errorf("unexpected message dispatched: %+v", message)
+			})
+
+			if !tt.wantSent {
+				if len(fake.sent) != 0 {
+					t.Errorf("expected no response, got %s", fake.sent[0])
+				}
+				return
+			}
+			if len(fake.sent) != 1 {
+				t.Fatalf("expected 1 response, got %d", len(fake.sent))
+			}
+			var response jsonrpc.RawJsonRpcErrorMessage
+			if err := json.Unmarshal(fake.sent[0], &response); err != nil {
+				t.Fatalf("invalid response %s: %v", fake.sent[0], err)
+			}
+			if response.Error.Code != tt.wantCode {
+				t.Errorf("expected code %d, got %d", tt.wantCode, response.Error.Code)
+			}
+			if string(response.Id) != tt.wantId {
+				t.Errorf("expected id %s, got %s", tt.wantId, response.Id)
+			}
+			if response.Error.Data == nil {
+				t.Errorf("expected details in the error data")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+// the mux link is a TCP socket (host:port), a unix domain socket (unix:///path/to/gomcp.sock)
+// or a WebSocket (ws://host:port/path, wss://host:port/path with TLS)
+const (
+	UnixScheme            = "unix"
+	WebSocketScheme       = "ws"
+	SecureWebSocketScheme = "wss"
+)
+
+// Server accepts the connections of the proxies
+type Server interface {
+	OnError(callback func(error))
+	Start(ctx context.Context, callback func(types.Transport)) error
+	Close()
+}
+
+// Client connects a proxy to the hub
+type Client interface {
+	Start() (types.Transport, error)
+}
+
+func isWebSocketAddress(address string) bool {
+	return strings.HasPrefix(address, WebSocketScheme+"://") || isSecureWebSocketAddress(address)
+}
+
+func isSecureWebSocketAddress(address string) bool {
+	return strings.HasPrefix(address, SecureWebSocketScheme+"://")
+}
+
+// checkTls returns an error if the scheme of the address does not match the use of TLS
+func checkTls(address string, useTls bool) error {
+	if strings.HasPrefix(address, UnixScheme+"://") && useTls {
+		return fmt.Errorf("TLS is not supported on unix sockets: %s", address)
+	}
+	if strings.HasPrefix(address, WebSocketScheme+"://") && useTls {
+		return fmt.Errorf("use a %s:// address with TLS: %s", SecureWebSocketScheme, address)
+	}
+	return nil
+}
+
+// NewServer creates the server for the scheme of the address, it uses TLS if tlsConfig is not nil
+func NewServer(address string, tlsConfig *tls.Config) (Server, error) {
+	if err := checkTls(address, tlsConfig != nil); err != nil {
+		return nil, err
+	}
+	if isSecureWebSocketAddress(address) && tlsConfig == nil {
+		return nil, fmt.Errorf("a certificate is required to listen on %s", address)
+	}
+	if isWebSocketAddress(address) {
+		return NewWebSocketServer(address, tlsConfig), nil
+	}
+	return NewSocketServer(address, tlsConfig), nil
+}
+
+// NewClient creates the client for the scheme of the address, it uses TLS if tlsConfig
+// is not nil, the wss:// addresses are verified with the system roots without it
+func NewClient(address string, tlsConfig *tls.Config) (Client, error) {
+	if err := checkTls(address, tlsConfig != nil); err != nil {
+		return nil, err
+	}
+	if isWebSocketAddress(address) {
+		return NewWebSocketClient(address, tlsConfig), nil
+	}
+	return NewSocketClient(address, tlsConfig), nil
+}
+
+// NetworkAddress returns the network and the address to dial to reach the server of the address
+func NetworkAddress(address string) (string, string, error) {
+	if path, ok := strings.CutPrefix(address, UnixScheme+"://"); ok {
+		// the hub and the proxies don't run in the same directory
+		if !filepath.IsAbs(path) {
+			return "", "", fmt.Errorf("the path of the unix socket must be absolute: %s", address)
+		}
+		return "unix", path, nil
+	}
+	if !isWebSocketAddress(address) {
+		return "tcp", address, nil
+	}
+	wsUrl, err := url.Parse(address)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid websocket address %s: %w", address, err)
+	}
+	host := wsUrl.Host
+	if wsUrl.Port() == "" {
+		port := "80"
+		if wsUrl.Scheme == SecureWebSocketScheme {
+			port = "443"
+		}
+		host = net.JoinHostPort(wsUrl.Hostname(), port)
+	}
+	return "tcp", host, nil
+}
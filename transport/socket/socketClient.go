@@ -1,28 +1,41 @@
 package socket
 
 import (
+	"crypto/tls"
 	"net"
 
 	"github.com/hamstah/gomcp/transport"
 	"github.com/hamstah/gomcp/types"
 )
 
-// SocketClient implements the Transport interface using TCP sockets
+// SocketClient implements the Transport interface using TCP or unix domain sockets
 type SocketClient struct {
-	addr string
-	conn net.Conn
+	addr      string
+	tlsConfig *tls.Config
+	conn      net.Conn
 }
 
-// NewSocketTransport creates a new socket transport instance
-func NewSocketClient(address string) *SocketClient {
+// NewSocketTransport creates a new socket transport instance,
+// the connection uses TLS if tlsConfig is not nil
+func NewSocketClient(address string, tlsConfig *tls.Config) *SocketClient {
 	return &SocketClient{
-		addr: address,
+		addr:      address,
+		tlsConfig: tlsConfig,
 	}
 }
 
 func (s *SocketClient) Start() (types.Transport, error) {
-	var err error
-	s.conn, err = net.Dial("tcp", s.addr)
+	network, address, err := NetworkAddress(s.addr)
+	if err != nil {
+		return nil, err
+	}
+	if s.tlsConfig != nil {
+		// the handshake is done by the dial, the server is authenticated before anything is sent
+		dialer := &net.Dialer{Timeout: tlsHandshakeTimeout}
+		s.conn, err = tls.DialWithDialer(dialer, network, address, s.tlsConfig)
+	} else {
+		s.conn, err = net.Dial(network, address)
+	}
 	if err != nil {
 		return nil, err
 	}
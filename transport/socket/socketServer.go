@@ -2,22 +2,33 @@ package socket
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"io"
 	"net"
+	"time"
 
 	"github.com/hamstah/gomcp/transport"
 	"github.com/hamstah/gomcp/types"
 )
 
+// maximum time for a client to complete the TLS handshake
+const tlsHandshakeTimeout = 10 * time.Second
+
 type SocketServer struct {
 	address   string
+	tlsConfig *tls.Config
 	listener  net.Listener
 	onError   func(error)
 	isClosing bool
 }
 
-func NewSocketServer(address string) *SocketServer {
+// NewSocketServer creates a server for a TCP or unix address, the connections
+// use TLS if tlsConfig is not nil
+func NewSocketServer(address string, tlsConfig *tls.Config) *SocketServer {
 	return &SocketServer{
 		address:   address,
+		tlsConfig: tlsConfig,
 		listener:  nil,
 		isClosing: false,
 	}
@@ -27,9 +38,11 @@ func (s *SocketServer) OnError(callback func(error)) {
 	s.onError = callback
 }
 
+// Start accepts the connections, the callback is called for each of them
+// once the TLS handshake is done, possibly concurrently
 func (s *SocketServer) Start(ctx context.Context, callback func(types.Transport)) error {
 	go func() {
-		listener, err := net.Listen("tcp", s.address)
+		listener, err := listen(s.address)
 		if err != nil {
 			if s.onError != nil {
 				s.onError(err)
@@ -53,8 +66,12 @@ func (s *SocketServer) Start(ctx context.Context, callback func(types.Transport)
 					continue
 				}
 
-				transport := transport.NewSocketConn(conn)
-				callback(transport)
+				if s.tlsConfig == nil {
+					callback(transport.NewSocketConn(conn))
+					continue
+				}
+				// the handshake of a client must not delay the others
+				go s.handshake(ctx, tls.Server(conn, s.tlsConfig), callback)
 			}
 		}
 	}()
@@ -62,6 +79,22 @@ func (s *SocketServer) Start(ctx context.Context, callback func(types.Transport)
 	return nil
 }
 
+// handshake authenticates the connection before it is handed to the callback
+func (s *SocketServer) handshake(ctx context.Context, conn *tls.Conn, callback func(types.Transport)) {
+	handshakeCtx, cancel := context.WithTimeout(ctx, tlsHandshakeTimeout)
+	defer cancel()
+	err := conn.HandshakeContext(handshakeCtx)
+	if err != nil {
+		conn.Close()
+		// the clients checking that the server is up close the connection right away
+		if s.onError != nil && !errors.Is(err, io.EOF) {
+			s.onError(err)
+		}
+		return
+	}
+	callback(transport.NewSocketConn(conn))
+}
+
 func (s *SocketServer) Close() {
 	if s.isClosing {
 		return
@@ -71,3 +104,15 @@ func (s *SocketServer) Close() {
 		s.listener.Close()
 	}
 }
+
+// listen listens on a TCP address (host:port) or on a unix domain socket (unix:///path/to/gomcp.sock)
+func listen(address string) (net.Listener, error) {
+	network, networkAddress, err := NetworkAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		return listenUnix(networkAddress)
+	}
+	return net.Listen(network, networkAddress)
+}
@@ -0,0 +1,164 @@
+package socket
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// newTestCertificate returns a certificate signed by the parent, self-signed without parent
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parentCertificate, signer := template, any(key)
+	if parent != nil {
+		parentCertificate, signer = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCertificate, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestSocketMutualTls(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil)
+	hub := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "hub"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}, &ca)
+	proxy := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "proxy-1"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+	anonymous := newTestCertificate(t, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	address := freeAddress(t)
+	server, err := NewServer(address, &tls.Config{
+		Certificates: []tls.Certificate{hub},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer server.Close()
+	peers := make(chan string, 1)
+	server.Start(ctx, func(tran types.Transport) {
+		peers <- tran.(transport.PeerIdentity).PeerCommonName()
+	})
+
+	// the server is started in a goroutine
+	var client types.Transport
+	for i := 0; i < 50; i++ {
+		socketClient, err := NewClient(address, &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{proxy}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client, err = socketClient.Start()
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client == nil {
+		t.Fatalf("failed to connect to the server")
+	}
+	defer client.Close()
+
+	select {
+	case peer := <-peers:
+		if peer != "proxy-1" {
+			t.Errorf("expected the common name of the proxy, got %q", peer)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the connection was not handed to the callback")
+	}
+
+	// the proxies without client certificate are refused
+	socketClient, _ := NewClient(address, &tls.Config{RootCAs: pool})
+	if conn, err := socketClient.Start(); err == nil {
+		conn.Close()
+	}
+	select {
+	case <-peers:
+		t.Errorf("expected the connection without client certificate to be refused")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// the certificates without common name are accepted by TLS, the hub denies their registration
+	socketClient, _ = NewClient(address, &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{anonymous}})
+	conn, err := socketClient.Start()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	select {
+	case peer := <-peers:
+		if peer != "" {
+			t.Errorf("expected no common name, got %q", peer)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the connection was not handed to the callback")
+	}
+}
+
+func TestCheckTls(t *testing.T) {
+	tests := []struct {
+		address string
+		useTls  bool
+		wantErr bool
+	}{
+		{"localhost:4567", true, false},
+		{"wss://localhost:4567/mux", true, false},
+		{"ws://localhost:4567/mux", true, true},
+		{"unix:///tmp/gomcp.sock", true, true},
+		{"unix:///tmp/gomcp.sock", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkTls(tt.address, tt.useTls)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package socket
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// listenUnix listens on a unix domain socket only reachable by the user running the hub,
+// the socket left by a hub that did not stop cleanly is removed
+func listenUnix(path string) (net.Listener, error) {
+	// the other users can't reach the socket before its permissions are changed
+	if err := makePrivateDirectory(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// makePrivateDirectory creates the missing directory of the socket with owner-only
+// permissions. An existing directory is not changed (it may be the home or a project
+// directory), it is refused if the other users can access it or if it is shared like /tmp.
+func makePrivateDirectory(dir string) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0700)
+	}
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("the directory of the socket %s does not belong to the user running the hub", dir)
+	}
+	if info.Mode()&os.ModeSticky != 0 {
+		return fmt.Errorf("the directory of the socket %s is shared, use a private directory (eg ~/.gomcp)", dir)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("the directory of the socket %s is accessible by the other users, use a private directory (eg ~/.gomcp) or restrict it with chmod 700", dir)
+	}
+	return nil
+}
+
+// removeStaleSocket removes the socket at path if no server is listening on it anymore
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("another server is listening on %s", path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,124 @@
+package socket
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetworkAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{"localhost:4567", "tcp", "localhost:4567", false},
+		{"unix:///tmp/gomcp.sock", "unix", "/tmp/gomcp.sock", false},
+		{"unix://gomcp.sock", "", "", true},
+		{"ws://localhost:4567/mux", "tcp", "localhost:4567", false},
+		{"ws://localhost/mux", "tcp", "localhost:80", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			network, address, err := NetworkAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if network != tt.wantNetwork || address != tt.wantAddress {
+				t.Errorf("expected %s %s, got %s %s", tt.wantNetwork, tt.wantAddress, network, address)
+			}
+		})
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	// the missing directory is created
+	path := filepath.Join(t.TempDir(), "gomcp", "gomcp.sock")
+
+	listener, err := listenUnix(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the socket to be owner-only, got %v", info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("expected the directory to be owner-only, got %v", info.Mode().Perm())
+	}
+
+	// the socket of a running server is kept
+	if _, err := listenUnix(path); err == nil {
+		t.Errorf("expected an error while the server is listening")
+	}
+
+	// the socket of a server that did not stop cleanly is replaced
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	listener, err = listenUnix(path)
+	if err != nil {
+		t.Fatalf("expected the stale socket to be removed: %v", err)
+	}
+	listener.Close()
+
+	// other files are never removed
+	if err := os.WriteFile(path, []byte{}, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := listenUnix(path); err == nil {
+		t.Errorf("expected an error for a file that is not a socket")
+	}
+}
+
+func TestListenUnixDirectory(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     os.FileMode
+		wantErr  bool
+		wantMode os.FileMode
+	}{
+		{"missing directory", 0, false, 0700},
+		{"private directory", 0700, false, 0700},
+		{"directory readable by the others", 0755, true, 0755},
+		{"directory of the group", 0770, true, 0770},
+		{"shared directory", os.ModeSticky | 0777, true, os.ModeSticky | 0777},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "gomcp")
+			if tt.mode != 0 {
+				if err := os.Mkdir(dir, 0700); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				// chmod is not restricted by the umask
+				if err := os.Chmod(dir, tt.mode); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			listener, err := listenUnix(filepath.Join(dir, "gomcp.sock"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if listener != nil {
+				listener.Close()
+			}
+			info, err := os.Stat(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode := info.Mode() & (os.ModePerm | os.ModeSticky); mode != tt.wantMode {
+				t.Errorf("expected the directory mode %v, got %v", tt.wantMode, mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// WebSocketClient connects a proxy to the hub over WebSocket
+type WebSocketClient struct {
+	address   string
+	tlsConfig *tls.Config
+}
+
+// NewWebSocketClient creates a client for a ws:// address (eg ws://localhost:4567/mux),
+// tlsConfig is used for the wss:// addresses
+func NewWebSocketClient(address string, tlsConfig *tls.Config) *WebSocketClient {
+	return &WebSocketClient{
+		address:   address,
+		tlsConfig: tlsConfig,
+	}
+}
+
+func (s *WebSocketClient) Start() (types.Transport, error) {
+	conn, err := transport.DialWebSocket(context.Background(), s.address, nil, s.tlsConfig, 0)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
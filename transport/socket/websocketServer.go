@@ -0,0 +1,107 @@
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/hamstah/gomcp/transport"
+	"github.com/hamstah/gomcp/types"
+)
+
+// WebSocketServer accepts the connections of the proxies over WebSocket,
+// for the networks where only HTTP can reach the hub
+type WebSocketServer struct {
+	address   string
+	tlsConfig *tls.Config
+	server    *http.Server
+	onError   func(error)
+	mutex     sync.Mutex
+	isClosing bool
+}
+
+// NewWebSocketServer creates a server listening on a ws:// address (eg ws://localhost:4567/mux),
+// or a wss:// address with tlsConfig
+func NewWebSocketServer(address string, tlsConfig *tls.Config) *WebSocketServer {
+	return &WebSocketServer{
+		address:   address,
+		tlsConfig: tlsConfig,
+	}
+}
+
+func (s *WebSocketServer) OnError(callback func(error)) {
+	s.onError = callback
+}
+
+func (s *WebSocketServer) Start(ctx context.Context, callback func(types.Transport)) error {
+	wsUrl, err := url.Parse(s.address)
+	if err != nil {
+		return fmt.Errorf("invalid websocket address %s: %w", s.address, err)
+	}
+	path := wsUrl.Path
+	if path == "" {
+		path = "/"
+	}
+
+	// the proxies are not browsers, the default upgrader refuses the cross origin requests
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			if s.onError != nil {
+				s.onError(err)
+			}
+			return
+		}
+		callback(transport.NewWebSocketConn(conn, 0))
+	})
+
+	s.mutex.Lock()
+	s.server = &http.Server{
+		Addr:      wsUrl.Host,
+		Handler:   mux,
+		TLSConfig: s.tlsConfig,
+	}
+	server := s.server
+	s.mutex.Unlock()
+
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			// the certificates are in the configuration
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			if s.onError != nil {
+				s.onError(err)
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		s.Close()
+	}()
+
+	return nil
+}
+
+func (s *WebSocketServer) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.isClosing {
+		return
+	}
+	s.isClosing = true
+	// the hijacked connections are closed by their sessions
+	if s.server != nil {
+		s.server.Close()
+	}
+}
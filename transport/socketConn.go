@@ -3,6 +3,7 @@ package transport
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -11,6 +12,13 @@ import (
 	"github.com/hamstah/gomcp/types"
 )
 
+// PeerIdentity is implemented by the transports of the connections authenticated
+// with a client certificate (mutual TLS)
+type PeerIdentity interface {
+	// common name of the certificate of the peer, empty without client certificate
+	PeerCommonName() string
+}
+
 type SocketConn struct {
 	conn net.Conn
 
@@ -60,6 +68,13 @@ func (s *SocketConn) Send(message json.RawMessage) error {
 	return err
 }
 
+// PeerCommonName implements PeerIdentity
+func (s *SocketConn) PeerCommonName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return peerCommonName(s.conn)
+}
+
 // OnMessage implements Transport.OnMessage
 func (s *SocketConn) OnMessage(callback func(json.RawMessage)) {
 	s.onMessage = callback
@@ -135,3 +150,16 @@ func (s *SocketConn) readLoop(ctx context.Context, errChan chan error) {
 		}
 	}
 }
+
+// peerCommonName returns the common name of the client certificate of a TLS connection
+func peerCommonName(conn net.Conn) string {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return ""
+	}
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return ""
+	}
+	return certificates[0].Subject.CommonName
+}
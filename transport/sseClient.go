@@ -0,0 +1,308 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+const (
+	// delay before reconnecting, the server can change it with the retry field
+	sseClientDefaultRetryDelay = time.Second
+	sseClientMaxRetryDelay     = 30 * time.Second
+	// the transport fails after that many reconnections without getting an endpoint
+	sseClientMaxReconnectAttempts = 5
+)
+
+// SseClientTransport connects to an MCP server using the HTTP+SSE transport of the
+// 2024-11-05 revision. It reconnects when the stream is interrupted, OnStarted is
+// called again when the server opens a new session so that it is initialized again.
+type SseClientTransport struct {
+	url        string
+	httpClient *http.Client
+	logger     types.Logger
+	onMessage  func(json.RawMessage)
+	onStarted  func()
+	onClose    func()
+	onError    func(error)
+
+	// endpoint of the session the messages are posted to
+	endpoint    string
+	lastEventId string
+	retryDelay  time.Duration
+	mutex       sync.Mutex
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	isClosed bool
+}
+
+func NewSseClientTransport(sseUrl string, logger types.Logger) *SseClientTransport {
+	return &SseClientTransport{
+		url:        sseUrl,
+		httpClient: &http.Client{},
+		logger:     logger,
+		retryDelay: sseClientDefaultRetryDelay,
+	}
+}
+
+// Start opens the stream and reconnects when it is interrupted, until the context
+// is cancelled or the server can't be reached anymore
+func (t *SseClientTransport) Start(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.isClosed {
+		t.mutex.Unlock()
+		return fmt.Errorf("transport closed")
+	}
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	ctx = t.ctx
+	t.mutex.Unlock()
+	defer t.Close()
+
+	failures := 0
+	for {
+		connected, err := t.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			failures = 0
+		}
+		failures++
+		if failures > sseClientMaxReconnectAttempts {
+			return fmt.Errorf("failed to connect to %s: %w", t.url, err)
+		}
+
+		t.mutex.Lock()
+		delay := t.retryDelay * time.Duration(failures)
+		t.mutex.Unlock()
+		if delay > sseClientMaxRetryDelay {
+			delay = sseClientMaxRetryDelay
+		}
+		t.logger.Info("sse stream interrupted, reconnecting", types.LogArg{
+			"url":   t.url,
+			"error": err,
+			"delay": delay.String(),
+		})
+		if t.onError != nil {
+			t.onError(err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// connect reads the stream until it is interrupted,
+// it returns true if the server sent an endpoint
+func (t *SseClientTransport) connect(ctx context.Context) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, t.url, nil)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Accept", contentTypeEventStream)
+	request.Header.Set("Cache-Control", "no-cache")
+	t.mutex.Lock()
+	if t.lastEventId != "" {
+		request.Header.Set(streamableHttpLastEventIdHeader, t.lastEventId)
+	}
+	t.mutex.Unlock()
+
+	response, err := t.httpClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	connected := false
+	reader := bufio.NewReader(response.Body)
+	var eventType, eventId string
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("stream closed by the server")
+			}
+			return connected, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// end of the event
+			if eventId != "" {
+				t.mutex.Lock()
+				t.lastEventId = eventId
+				t.mutex.Unlock()
+			}
+			if data.Len() > 0 {
+				if t.dispatchEvent(eventType, data.Bytes()) {
+					connected = true
+				}
+			}
+			eventType, eventId = "", ""
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, the keepalives of the server
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "id":
+			eventId = value
+		case "retry":
+			if milliseconds, err := strconv.Atoi(value); err == nil && milliseconds > 0 {
+				t.mutex.Lock()
+				t.retryDelay = time.Duration(milliseconds) * time.Millisecond
+				t.mutex.Unlock()
+			}
+		}
+	}
+}
+
+// dispatchEvent handles an event of the stream, it returns true for the endpoint event
+func (t *SseClientTransport) dispatchEvent(eventType string, data []byte) bool {
+	switch eventType {
+	case "endpoint":
+		endpoint, err := t.resolveEndpoint(string(data))
+		if err != nil {
+			t.logger.Error("invalid endpoint sent by the server", types.LogArg{
+				"endpoint": string(data),
+				"error":    err,
+			})
+			return false
+		}
+		t.mutex.Lock()
+		previousEndpoint := t.endpoint
+		t.endpoint = endpoint
+		t.mutex.Unlock()
+
+		// a new session needs to be initialized
+		if endpoint != previousEndpoint {
+			if previousEndpoint != "" {
+				t.logger.Info("the server opened a new session", types.LogArg{
+					"endpoint": endpoint,
+				})
+			}
+			if t.onStarted != nil {
+				t.onStarted()
+			}
+		}
+		return true
+	case "", "message":
+		if t.onMessage != nil {
+			t.onMessage(json.RawMessage(data))
+		}
+	default:
+		t.logger.Debug("ignoring sse event", types.LogArg{
+			"event": eventType,
+		})
+	}
+	return false
+}
+
+// resolveEndpoint returns the absolute URL of the endpoint, it must have the origin of the stream
+func (t *SseClientTransport) resolveEndpoint(endpoint string) (string, error) {
+	base, err := url.Parse(t.url)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := base.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if resolved.Scheme != base.Scheme || resolved.Host != base.Host {
+		return "", fmt.Errorf("the endpoint does not have the origin of the stream")
+	}
+	return resolved.String(), nil
+}
+
+// Send posts a message to the endpoint of the session
+func (t *SseClientTransport) Send(message json.RawMessage) error {
+	t.mutex.Lock()
+	endpoint := t.endpoint
+	ctx := t.ctx
+	t.mutex.Unlock()
+	if endpoint == "" || ctx == nil {
+		return fmt.Errorf("not connected to %s", t.url)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentTypeJson)
+	response, err := t.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to post message: unexpected status %s", response.Status)
+	}
+	return nil
+}
+
+func (t *SseClientTransport) OnMessage(callback func(json.RawMessage)) {
+	t.onMessage = callback
+}
+
+func (t *SseClientTransport) OnStarted(callback func()) {
+	t.onStarted = callback
+}
+
+func (t *SseClientTransport) OnClose(callback func()) {
+	t.onClose = callback
+}
+
+func (t *SseClientTransport) OnError(callback func(error)) {
+	t.onError = callback
+}
+
+// Close closes the stream, the server ends the session
+func (t *SseClientTransport) Close() {
+	t.mutex.Lock()
+	if t.isClosed {
+		t.mutex.Unlock()
+		return
+	}
+	t.isClosed = true
+	cancel := t.cancel
+	t.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if t.onClose != nil {
+		t.onClose()
+	}
+}
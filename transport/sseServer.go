@@ -0,0 +1,365 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hamstah/gomcp/types"
+)
+
+const (
+	sseDefaultPath              = "/sse"
+	sseDefaultMessagePath       = "/message"
+	sseDefaultKeepAliveInterval = 15 * time.Second
+	// number of messages waiting to be written on the stream of a session
+	sseSessionQueueSize = 64
+)
+
+// SseServerTransport serves the MCP clients over the HTTP+SSE transport of the
+// 2024-11-05 revision: the client opens an SSE stream with GET, the server sends
+// the endpoint the client posts its messages to and its own messages on the stream.
+// Each stream is a session, it ends when the client disconnects.
+type SseServerTransport struct {
+	options types.SseOptions
+	logger  types.Logger
+
+	sessions map[string]*sseServerSession
+	mutex    sync.Mutex
+
+	onSession func(session types.Transport)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewSseServerTransport(options types.SseOptions, logger types.Logger) *SseServerTransport {
+	if options.SsePath == "" {
+		options.SsePath = sseDefaultPath
+	}
+	if options.MessagePath == "" {
+		options.MessagePath = sseDefaultMessagePath
+	}
+	if options.KeepAliveInterval == 0 {
+		options.KeepAliveInterval = sseDefaultKeepAliveInterval
+	}
+	return &SseServerTransport{
+		options:  options,
+		logger:   logger,
+		sessions: map[string]*sseServerSession{},
+		closed:   make(chan struct{}),
+	}
+}
+
+// Start listens on the address of the options until the context is cancelled,
+// without address it only waits for the context as the application serves the handler
+func (t *SseServerTransport) Start(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	var server *http.Server
+	if t.options.ListenAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(t.options.SsePath, t)
+		mux.Handle(t.options.MessagePath, t)
+		server = &http.Server{
+			Addr:    t.options.ListenAddress,
+			Handler: mux,
+		}
+		go func() {
+			t.logger.Info("sse transport listening", types.LogArg{
+				"address": t.options.ListenAddress,
+				"path":    t.options.SsePath,
+			})
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				if t.onError != nil {
+					t.onError(err)
+				}
+				errChan <- fmt.Errorf("failed to serve %s: %w", t.options.ListenAddress, err)
+			}
+		}()
+	}
+
+	if t.onStarted != nil {
+		t.onStarted()
+	}
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-t.closed:
+	}
+
+	t.Close()
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+	return err
+}
+
+// Send is not supported, the messages are sent by the transports of the sessions
+func (t *SseServerTransport) Send(message json.RawMessage) error {
+	return fmt.Errorf("sse transport: messages are sent through the sessions")
+}
+
+// OnMessage is not used, the messages are received by the transports of the sessions
+func (t *SseServerTransport) OnMessage(callback func(json.RawMessage)) {}
+
+func (t *SseServerTransport) OnSession(callback func(session types.Transport)) {
+	t.onSession = callback
+}
+
+func (t *SseServerTransport) OnStarted(callback func()) {
+	t.onStarted = callback
+}
+
+func (t *SseServerTransport) OnClose(callback func()) {
+	t.onClose = callback
+}
+
+func (t *SseServerTransport) OnError(callback func(error)) {
+	t.onError = callback
+}
+
+// Close terminates all the sessions
+func (t *SseServerTransport) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+
+		t.mutex.Lock()
+		sessions := make([]*sseServerSession, 0, len(t.sessions))
+		for _, session := range t.sessions {
+			sessions = append(sessions, session)
+		}
+		t.mutex.Unlock()
+
+		for _, session := range sessions {
+			session.Close()
+		}
+		if t.onClose != nil {
+			t.onClose()
+		}
+	})
+}
+
+func (t *SseServerTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// browsers of other sites must not reach a local server (DNS rebinding)
+	if !isAllowedOrigin(r, t.options.AllowedOrigins) {
+		t.logger.Error("request from an origin not allowed", types.LogArg{
+			"origin": r.Header.Get("Origin"),
+		})
+		http.Error(w, "Forbidden: origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		t.handleStream(w, r)
+	case http.MethodPost:
+		t.handleMessage(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleStream opens a session, its messages are written on the stream until the client disconnects
+func (t *SseServerTransport) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if t.onSession == nil {
+		http.Error(w, "the transport is not started", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := &sseServerSession{
+		id:       uuid.New().String(),
+		logger:   t.logger,
+		outgoing: make(chan json.RawMessage, sseSessionQueueSize),
+		started:  make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	t.mutex.Lock()
+	t.sessions[session.id] = session
+	t.mutex.Unlock()
+	defer func() {
+		t.mutex.Lock()
+		delete(t.sessions, session.id)
+		t.mutex.Unlock()
+		session.Close()
+	}()
+
+	t.logger.Info("new sse session", types.LogArg{
+		"sessionId": session.id,
+	})
+	t.onSession(session)
+
+	select {
+	case <-session.started:
+	case <-time.After(httpSessionStartTimeout):
+		http.Error(w, "the session was not started", http.StatusInternalServerError)
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// the client posts its messages to the endpoint of the session
+	endpoint := fmt.Sprintf("%s?sessionId=%s", t.options.MessagePath, session.id)
+	if err := writeSseEvent(w, "", "endpoint", []byte(endpoint)); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var keepAlive <-chan time.Time
+	if t.options.KeepAliveInterval > 0 {
+		ticker := time.NewTicker(t.options.KeepAliveInterval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case message := <-session.outgoing:
+			if err := writeSseEvent(w, "", "message", message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive:
+			// a comment, ignored by the clients
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			t.logger.Info("sse client disconnected", types.LogArg{
+				"sessionId": session.id,
+			})
+			return
+		case <-session.closed:
+			return
+		}
+	}
+}
+
+// handleMessage delivers a message posted by the client to its session
+func (t *SseServerTransport) handleMessage(w http.ResponseWriter, r *http.Request) {
+	sessionId := r.URL.Query().Get("sessionId")
+	if sessionId == "" {
+		http.Error(w, "Bad Request: missing sessionId", http.StatusBadRequest)
+		return
+	}
+	t.mutex.Lock()
+	session := t.sessions[sessionId]
+	t.mutex.Unlock()
+	if session == nil {
+		http.Error(w, "Not Found: unknown or terminated session", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHttpBodySize))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	// the invalid messages are answered by the server on the stream
+	session.deliver(body)
+	w.WriteHeader(http.StatusAccepted)
+	io.WriteString(w, "Accepted")
+}
+
+// sseServerSession is the transport of one MCP client of the HTTP+SSE transport
+type sseServerSession struct {
+	id        string
+	logger    types.Logger
+	onMessage func(json.RawMessage)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+	outgoing  chan json.RawMessage
+	started   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	// the messages of the client are delivered one at a time
+	deliverMutex sync.Mutex
+}
+
+func (s *sseServerSession) Start(ctx context.Context) error {
+	if s.onStarted != nil {
+		s.onStarted()
+	}
+	close(s.started)
+
+	select {
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	case <-s.closed:
+		return nil
+	}
+}
+
+// Send queues a message to be written on the stream of the session
+func (s *sseServerSession) Send(message json.RawMessage) error {
+	select {
+	case s.outgoing <- message:
+		return nil
+	case <-s.closed:
+		return fmt.Errorf("session %s is terminated", s.id)
+	}
+}
+
+func (s *sseServerSession) deliver(body []byte) {
+	s.deliverMutex.Lock()
+	defer s.deliverMutex.Unlock()
+	if s.onMessage != nil {
+		s.onMessage(json.RawMessage(body))
+	}
+}
+
+func (s *sseServerSession) OnMessage(callback func(json.RawMessage)) {
+	s.onMessage = callback
+}
+
+func (s *sseServerSession) OnStarted(callback func()) {
+	s.onStarted = callback
+}
+
+func (s *sseServerSession) OnClose(callback func()) {
+	s.onClose = callback
+}
+
+func (s *sseServerSession) OnError(callback func(error)) {
+	s.onError = callback
+}
+
+// Close terminates the session, the stream is closed
+func (s *sseServerSession) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.logger.Info("sse session terminated", types.LogArg{
+			"sessionId": s.id,
+		})
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/types"
+)
+
+func TestSseTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// short keepalives, they must be ignored by the client
+	sseTransport := NewSseServerTransport(types.SseOptions{KeepAliveInterval: 5 * time.Millisecond}, nopLogger{})
+	sseTransport.OnSession(func(session types.Transport) {
+		startTestSession(ctx, session, nil)
+	})
+	go sseTransport.Start(ctx)
+	server := httptest.NewServer(sseTransport)
+	t.Cleanup(server.Close)
+
+	response, err := http.Post(server.URL+"/message?sessionId=unknown", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown session, got %d", response.StatusCode)
+	}
+
+	client := NewSseClientTransport(server.URL+"/sse", nopLogger{})
+	client.retryDelay = 10 * time.Millisecond
+	jsonRpcClient := NewJsonRpcTransport(client, "client", nopLogger{})
+	var sessions atomic.Int32
+	started := make(chan struct{}, 2)
+	jsonRpcClient.OnStarted(func() {
+		sessions.Add(1)
+		started <- struct{}{}
+	})
+	messages := make(chan JsonRpcMessage, 10)
+	go jsonRpcClient.Start(ctx, func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+		messages <- message
+	})
+
+	// call sends a request and waits for its notification and its response
+	call := func(method string, wantMessages int) []JsonRpcMessage {
+		if _, err := jsonRpcClient.SendRequestWithMethodAndParams(method, map[string]interface{}{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		received := []JsonRpcMessage{}
+		for len(received) < wantMessages {
+			select {
+			case message := <-messages:
+				received = append(received, message)
+			case <-ctx.Done():
+				t.Fatalf("timeout waiting for the %s messages, got %+v", method, received)
+			}
+		}
+		return received
+	}
+
+	<-started
+	received := call("notify", 2)
+	if received[0].Request == nil || received[0].Method != "notifications/message" || received[1].Response == nil {
+		t.Errorf("unexpected messages %+v", received)
+	}
+
+	// the keepalives are received while the stream is idle
+	time.Sleep(20 * time.Millisecond)
+
+	// the client reconnects and gets a new session
+	server.CloseClientConnections()
+	select {
+	case <-started:
+	case <-ctx.Done():
+		t.Fatalf("the client did not reconnect")
+	}
+	received = call("ping", 1)
+	if received[0].Response == nil || jsonrpc.RequestIdToString(received[0].Response.Id) == "" {
+		t.Errorf("unexpected messages after reconnection %+v", received)
+	}
+	if sessions.Load() != 2 {
+		t.Errorf("expected 2 sessions, got %d", sessions.Load())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/hamstah/gomcp/channels/hubinspector"
@@ -22,6 +23,8 @@ type StdioTransport struct {
 	onMessage         func(json.RawMessage)
 	onClose           func()
 	onError           func(error)
+	// Mutex for thread-safe writes
+	mu sync.Mutex
 }
 
 func NewStdioTransport(protocolDebugFile string, inspector *hubinspector.Inspector, logger types.Logger) types.Transport {
@@ -65,6 +68,8 @@ func (t *StdioTransport) Send(message json.RawMessage) error {
 		})
 	}
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	_, err := fmt.Fprintf(os.Stdout, "%s\n", message)
 	return err
 }
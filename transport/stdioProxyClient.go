@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"sync"
 
 	"github.com/hamstah/gomcp/types"
 )
@@ -17,6 +18,16 @@ type ProxiedMcpServerDescription struct {
 	CurrentWorkingDirectory string
 	ProgramName             string
 	ProgramArgs             []string
+	// URL of a remote MCP server using the HTTP+SSE transport, instead of a program
+	ServerUrl string
+}
+
+// Command describes the MCP server for the hub, its program or its URL
+func (d *ProxiedMcpServerDescription) Command() string {
+	if d.ServerUrl != "" {
+		return d.ServerUrl
+	}
+	return d.ProgramName
 }
 
 type StdioProxyClientTransport struct {
@@ -30,6 +41,8 @@ type StdioProxyClientTransport struct {
 	onStarted func()
 	// we need to keep track of the pipe reader
 	pipeReader *io.PipeReader
+	// Mutex for thread-safe writes
+	mu sync.Mutex
 }
 
 func NewStdioProxyClientTransport(options *ProxiedMcpServerDescription) types.Transport {
@@ -162,6 +175,8 @@ func (t *StdioProxyClientTransport) Close() {
 
 func (t *StdioProxyClientTransport) Send(message json.RawMessage) error {
 	nlTerminatedMessage := string(message) + "\n"
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	_, err := t.stdin.Write([]byte(nlTerminatedMessage))
 	return err
 }
@@ -0,0 +1,508 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/protocol/mcp"
+	"github.com/hamstah/gomcp/types"
+)
+
+const (
+	StreamableHttpSessionIdHeader       = "Mcp-Session-Id"
+	StreamableHttpProtocolVersionHeader = "Mcp-Protocol-Version"
+	streamableHttpLastEventIdHeader     = "Last-Event-ID"
+	streamableHttpDefaultPath           = "/mcp"
+	// maximum time to wait for the server to start a new session
+	httpSessionStartTimeout = 10 * time.Second
+	// maximum time to wait for the requests in progress when stopping
+	httpShutdownTimeout = 5 * time.Second
+)
+
+var errTooManySessions = errors.New("too many sessions")
+
+const (
+	contentTypeJson        = "application/json"
+	contentTypeEventStream = "text/event-stream"
+	// maximum size of the body of a POST request
+	maxHttpBodySize = 10 * 1024 * 1024
+)
+
+// StreamableHttpTransport serves the MCP clients over the Streamable HTTP transport
+// of the specification: a single endpoint receiving the messages of the clients with
+// POST requests, answered with JSON or an SSE stream, and opening a stream of the
+// messages of the server with GET requests. Each client gets its own session.
+type StreamableHttpTransport struct {
+	options types.StreamableHttpOptions
+	logger  types.Logger
+
+	sessions map[string]*streamableHttpSession
+	mutex    sync.Mutex
+
+	onSession func(session types.Transport)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewStreamableHttpTransport(options types.StreamableHttpOptions, logger types.Logger) *StreamableHttpTransport {
+	if options.Path == "" {
+		options.Path = streamableHttpDefaultPath
+	}
+	return &StreamableHttpTransport{
+		options:  options,
+		logger:   logger,
+		sessions: map[string]*streamableHttpSession{},
+		closed:   make(chan struct{}),
+	}
+}
+
+// Start listens on the address of the options until the context is cancelled,
+// without address it only waits for the context as the application serves the handler
+func (t *StreamableHttpTransport) Start(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	var server *http.Server
+	if t.options.ListenAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(t.options.Path, t)
+		server = &http.Server{
+			Addr:    t.options.ListenAddress,
+			Handler: mux,
+		}
+		go func() {
+			t.logger.Info("streamable http transport listening", types.LogArg{
+				"address": t.options.ListenAddress,
+				"path":    t.options.Path,
+			})
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				if t.onError != nil {
+					t.onError(err)
+				}
+				errChan <- fmt.Errorf("failed to serve %s: %w", t.options.ListenAddress, err)
+			}
+		}()
+	}
+
+	if t.options.SessionIdleTimeout > 0 {
+		go t.closeIdleSessions(ctx)
+	}
+
+	if t.onStarted != nil {
+		t.onStarted()
+	}
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-t.closed:
+	}
+
+	t.Close()
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+	return err
+}
+
+// Send is not supported, the messages are sent by the transports of the sessions
+func (t *StreamableHttpTransport) Send(message json.RawMessage) error {
+	return fmt.Errorf("streamable http transport: messages are sent through the sessions")
+}
+
+// OnMessage is not used, the messages are received by the transports of the sessions
+func (t *StreamableHttpTransport) OnMessage(callback func(json.RawMessage)) {}
+
+func (t *StreamableHttpTransport) OnSession(callback func(session types.Transport)) {
+	t.onSession = callback
+}
+
+func (t *StreamableHttpTransport) OnStarted(callback func()) {
+	t.onStarted = callback
+}
+
+func (t *StreamableHttpTransport) OnClose(callback func()) {
+	t.onClose = callback
+}
+
+func (t *StreamableHttpTransport) OnError(callback func(error)) {
+	t.onError = callback
+}
+
+// Close terminates all the sessions
+func (t *StreamableHttpTransport) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+
+		t.mutex.Lock()
+		sessions := make([]*streamableHttpSession, 0, len(t.sessions))
+		for _, session := range t.sessions {
+			sessions = append(sessions, session)
+		}
+		t.mutex.Unlock()
+
+		for _, session := range sessions {
+			session.Close()
+		}
+		if t.onClose != nil {
+			t.onClose()
+		}
+	})
+}
+
+func (t *StreamableHttpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// browsers of other sites must not reach a local server (DNS rebinding)
+	if !isAllowedOrigin(r, t.options.AllowedOrigins) {
+		t.logger.Error("request from an origin not allowed", types.LogArg{
+			"origin": r.Header.Get("Origin"),
+		})
+		http.Error(w, "Forbidden: origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// isAllowedOrigin checks the origin of the browsers, the other clients don't send one
+func isAllowedOrigin(r *http.Request, allowedOrigins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a browser
+		return true
+	}
+	if originUrl, err := url.Parse(origin); err == nil && originUrl.Host == r.Host {
+		return true
+	}
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePost receives the messages of the client, the requests are answered
+// with a JSON response or with an SSE stream, the other messages are accepted
+func (t *StreamableHttpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	acceptsJson, acceptsEventStream := acceptedContentTypes(r)
+	if !acceptsJson && !acceptsEventStream {
+		writeHttpJsonRpcError(w, http.StatusNotAcceptable, jsonrpc.RpcInvalidRequest, "Not Acceptable: the client must accept application/json and text/event-stream")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHttpBodySize))
+	if err != nil {
+		writeHttpJsonRpcError(w, http.StatusRequestEntityTooLarge, jsonrpc.RpcInvalidRequest, "Request body too large")
+		return
+	}
+	var rawMessage interface{}
+	if err := json.Unmarshal(body, &rawMessage); err != nil {
+		writeHttpJsonRpcError(w, http.StatusBadRequest, jsonrpc.RpcParseError, "Parse error")
+		return
+	}
+	requestIds, isInitialize := findRequests(rawMessage)
+
+	var session *streamableHttpSession
+	sessionId := r.Header.Get(StreamableHttpSessionIdHeader)
+	switch {
+	case sessionId == "" && isInitialize:
+		session, err = t.newSession(r.Context())
+		if errors.Is(err, errTooManySessions) {
+			writeHttpJsonRpcError(w, http.StatusServiceUnavailable, jsonrpc.RpcInternalError, "Service Unavailable: too many sessions")
+			return
+		}
+		if err != nil {
+			writeHttpJsonRpcError(w, http.StatusInternalServerError, jsonrpc.RpcInternalError, err.Error())
+			return
+		}
+	case sessionId == "":
+		writeHttpJsonRpcError(w, http.StatusBadRequest, jsonrpc.RpcInvalidRequest, "Bad Request: missing session id, the session starts with an initialize request")
+		return
+	case isInitialize:
+		writeHttpJsonRpcError(w, http.StatusBadRequest, jsonrpc.RpcInvalidRequest, "Bad Request: the session is already initialized")
+		return
+	default:
+		session = t.getSession(sessionId)
+		if session == nil {
+			writeHttpJsonRpcError(w, http.StatusNotFound, jsonrpc.RpcInvalidRequest, "Not Found: unknown or terminated session")
+			return
+		}
+		if !isSupportedProtocolVersionHeader(r) {
+			writeHttpJsonRpcError(w, http.StatusBadRequest, jsonrpc.RpcInvalidRequest, "Bad Request: unsupported protocol version")
+			return
+		}
+	}
+	w.Header().Set(StreamableHttpSessionIdHeader, session.id)
+	defer session.beginRequest()()
+
+	// the stream is ready before the message is delivered
+	// so that the responses can't arrive before
+	useEventStream := acceptsEventStream && (!t.options.JsonResponse || !acceptsJson)
+	stream := session.newStream(requestIds, useEventStream)
+	session.deliver(body, stream)
+
+	if len(requestIds) == 0 {
+		// notifications and responses are accepted, unless they are invalid
+		events := session.takeEvents(stream)
+		if len(events) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writeHttpJson(w, http.StatusBadRequest, events)
+		return
+	}
+
+	if useEventStream {
+		session.writeEventStream(w, r, stream, 1)
+		return
+	}
+	events, ok := session.waitEvents(r.Context(), stream)
+	if !ok {
+		return
+	}
+	writeHttpJson(w, http.StatusOK, events)
+}
+
+// handleGet opens the stream of the messages sent by the server outside of
+// the requests, or resumes a stream with the id of the last event received
+func (t *StreamableHttpTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	_, acceptsEventStream := acceptedContentTypes(r)
+	if !acceptsEventStream {
+		http.Error(w, "Not Acceptable: the client must accept text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	session := t.sessionFromHeader(w, r)
+	if session == nil {
+		return
+	}
+	w.Header().Set(StreamableHttpSessionIdHeader, session.id)
+	defer session.beginRequest()()
+
+	stream, fromSeq := session.resumeStream(r.Header.Get(streamableHttpLastEventIdHeader))
+	session.writeEventStream(w, r, stream, fromSeq)
+}
+
+// handleDelete terminates the session
+func (t *StreamableHttpTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session := t.sessionFromHeader(w, r)
+	if session == nil {
+		return
+	}
+	session.Close()
+	w.WriteHeader(http.StatusOK)
+}
+
+// sessionFromHeader returns the session of the request,
+// it answers with an error and returns nil if there is none
+func (t *StreamableHttpTransport) sessionFromHeader(w http.ResponseWriter, r *http.Request) *streamableHttpSession {
+	sessionId := r.Header.Get(StreamableHttpSessionIdHeader)
+	if sessionId == "" {
+		http.Error(w, "Bad Request: missing session id", http.StatusBadRequest)
+		return nil
+	}
+	session := t.getSession(sessionId)
+	if session == nil {
+		http.Error(w, "Not Found: unknown or terminated session", http.StatusNotFound)
+		return nil
+	}
+	if !isSupportedProtocolVersionHeader(r) {
+		http.Error(w, "Bad Request: unsupported protocol version", http.StatusBadRequest)
+		return nil
+	}
+	return session
+}
+
+// newSession creates a session and waits for the server to be ready to receive its messages
+func (t *StreamableHttpTransport) newSession(ctx context.Context) (*streamableHttpSession, error) {
+	if t.onSession == nil {
+		return nil, fmt.Errorf("the transport is not started")
+	}
+	session := newStreamableHttpSession(uuid.New().String(), t)
+
+	t.mutex.Lock()
+	if t.options.MaxSessions > 0 && len(t.sessions) >= t.options.MaxSessions {
+		t.mutex.Unlock()
+		t.logger.Error("new streamable http session refused", types.LogArg{
+			"maxSessions": t.options.MaxSessions,
+		})
+		return nil, errTooManySessions
+	}
+	t.sessions[session.id] = session
+	t.mutex.Unlock()
+
+	t.logger.Info("new streamable http session", types.LogArg{
+		"sessionId": session.id,
+	})
+	t.onSession(session)
+
+	select {
+	case <-session.started:
+		return session, nil
+	case <-time.After(httpSessionStartTimeout):
+	case <-ctx.Done():
+	}
+	session.Close()
+	return nil, fmt.Errorf("the session was not started")
+}
+
+func (t *StreamableHttpTransport) getSession(sessionId string) *streamableHttpSession {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.sessions[sessionId]
+}
+
+func (t *StreamableHttpTransport) removeSession(sessionId string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.sessions, sessionId)
+}
+
+// closeIdleSessions terminates the sessions idle for SessionIdleTimeout,
+// until the context is cancelled or the transport is closed
+func (t *StreamableHttpTransport) closeIdleSessions(ctx context.Context) {
+	ticker := time.NewTicker(t.options.SessionIdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			t.mutex.Lock()
+			idle := []*streamableHttpSession{}
+			for _, session := range t.sessions {
+				if session.isIdle(now, t.options.SessionIdleTimeout) {
+					idle = append(idle, session)
+				}
+			}
+			t.mutex.Unlock()
+
+			for _, session := range idle {
+				t.logger.Info("closing idle streamable http session", types.LogArg{
+					"sessionId": session.id,
+				})
+				session.Close()
+			}
+		case <-ctx.Done():
+			return
+		case <-t.closed:
+			return
+		}
+	}
+}
+
+// acceptedContentTypes checks the Accept header, a missing header accepts everything
+func acceptedContentTypes(r *http.Request) (acceptsJson bool, acceptsEventStream bool) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return true, true
+	}
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			switch mediaType {
+			case contentTypeJson, "application/*":
+				acceptsJson = true
+			case contentTypeEventStream, "text/*":
+				acceptsEventStream = true
+			case "*/*":
+				acceptsJson = true
+				acceptsEventStream = true
+			}
+		}
+	}
+	return acceptsJson, acceptsEventStream
+}
+
+// isSupportedProtocolVersionHeader checks the protocol version sent by the client
+// after the initialization, it is optional for the clients of 2025-03-26
+func isSupportedProtocolVersionHeader(r *http.Request) bool {
+	version := r.Header.Get(StreamableHttpProtocolVersionHeader)
+	return version == "" || mcp.IsSupportedProtocolVersion(version)
+}
+
+// findRequests returns the ids of the requests of a message or a batch,
+// and whether the message is an initialize request
+func findRequests(rawMessage interface{}) ([]string, bool) {
+	items, isBatch := rawMessage.([]interface{})
+	if !isBatch {
+		items = []interface{}{rawMessage}
+	}
+	requestIds := []string{}
+	isInitialize := false
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		method, ok := object["method"].(string)
+		if !ok || object["id"] == nil {
+			continue
+		}
+		requestId := jsonrpc.RequestIdFromValue(object["id"])
+		if requestId == nil {
+			continue
+		}
+		requestIds = append(requestIds, jsonrpc.RequestIdToString(requestId))
+		// the initialize request can't be part of a batch
+		if method == "initialize" && !isBatch {
+			isInitialize = true
+		}
+	}
+	return requestIds, isInitialize
+}
+
+func writeHttpJson(w http.ResponseWriter, status int, messages []json.RawMessage) {
+	var body []byte
+	if len(messages) == 1 {
+		body = messages[0]
+	} else {
+		body, _ = json.Marshal(messages)
+	}
+	w.Header().Set("Content-Type", contentTypeJson)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// writeHttpJsonRpcError answers a request that can't be delivered to the session
+func writeHttpJsonRpcError(w http.ResponseWriter, status int, code int, message string) {
+	response, err := jsonrpc.MarshalJsonRpcResponse(&jsonrpc.JsonRpcResponse{
+		Error: &jsonrpc.JsonRpcError{
+			Code:    code,
+			Message: message,
+		},
+	})
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+	writeHttpJson(w, status, []json.RawMessage{response})
+}
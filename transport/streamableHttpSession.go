@@ -0,0 +1,512 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamstah/gomcp/jsonrpc"
+	"github.com/hamstah/gomcp/types"
+)
+
+const (
+	// id of the stream opened with GET, the streams of the POST requests are numbered from 1
+	standaloneStreamId = "0"
+	// number of events kept on the GET stream for the clients resuming it
+	maxStandaloneStreamEvents = 1000
+	// number of interrupted POST streams kept for the clients resuming them
+	maxDetachedStreams = 32
+)
+
+// httpStream is the sequence of messages answering a POST request, or sent on the GET stream.
+// The messages are kept so that a client can resume an interrupted SSE stream
+// with the id of the last event received.
+type httpStream struct {
+	id          string
+	eventStream bool
+	// events[0] has the sequence number firstSeq, numbered from 1
+	events   []json.RawMessage
+	firstSeq int
+	// last sequence number written to a client
+	sentSeq int
+	// ids of the requests not answered yet
+	pending map[string]bool
+	// signals the connection of the stream that events were added
+	changed chan struct{}
+	// closed when another connection takes over the stream
+	evicted chan struct{}
+}
+
+func (s *httpStream) lastSeq() int {
+	return s.firstSeq + len(s.events) - 1
+}
+
+// isComplete returns true when all the requests of a POST stream are answered,
+// the GET stream is never complete
+func (s *httpStream) isComplete() bool {
+	return s.id != standaloneStreamId && len(s.pending) == 0
+}
+
+func (s *httpStream) signal() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// streamableHttpSession is the transport of one MCP client of the Streamable HTTP
+// transport, the messages sent by the server are routed to the HTTP responses
+type streamableHttpSession struct {
+	id        string
+	transport *StreamableHttpTransport
+	logger    types.Logger
+	onMessage func(json.RawMessage)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+	started   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// the messages of the client are delivered one at a time
+	deliverMutex sync.Mutex
+	// protects the streams
+	mutex sync.Mutex
+	// streams by id
+	streams map[string]*httpStream
+	// streams of the requests not answered yet, by request id
+	requestStreams map[string]*httpStream
+	// stream of the message being delivered, it receives the errors of the transport
+	delivering *httpStream
+	// GET stream, for the messages not related to a request
+	standalone   *httpStream
+	lastStreamId int
+	// interrupted POST streams, oldest first
+	detached []*httpStream
+	// HTTP requests of the client in progress, the session is not idle while
+	// there is one, and the end of the last one
+	requests     int
+	lastActivity time.Time
+}
+
+func newStreamableHttpSession(id string, transport *StreamableHttpTransport) *streamableHttpSession {
+	standalone := &httpStream{
+		id:          standaloneStreamId,
+		eventStream: true,
+		firstSeq:    1,
+		pending:     map[string]bool{},
+		changed:     make(chan struct{}, 1),
+		evicted:     make(chan struct{}),
+	}
+	return &streamableHttpSession{
+		id:             id,
+		transport:      transport,
+		logger:         transport.logger,
+		started:        make(chan struct{}),
+		closed:         make(chan struct{}),
+		streams:        map[string]*httpStream{standaloneStreamId: standalone},
+		requestStreams: map[string]*httpStream{},
+		standalone:     standalone,
+		lastActivity:   time.Now(),
+	}
+}
+
+func (s *streamableHttpSession) Start(ctx context.Context) error {
+	if s.onStarted != nil {
+		s.onStarted()
+	}
+	close(s.started)
+
+	select {
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	case <-s.closed:
+		return nil
+	}
+}
+
+// Send routes a message of the server: the responses go to the stream of their
+// request, the requests and notifications go to a POST stream in progress or
+// to the GET stream
+func (s *streamableHttpSession) Send(message json.RawMessage) error {
+	select {
+	case <-s.closed:
+		return fmt.Errorf("session %s is terminated", s.id)
+	default:
+	}
+
+	responseIds, isResponse := findResponses(message)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var stream *httpStream
+	if isResponse {
+		for _, responseId := range responseIds {
+			requestStream, ok := s.requestStreams[responseId]
+			if !ok {
+				continue
+			}
+			delete(s.requestStreams, responseId)
+			delete(requestStream.pending, responseId)
+			stream = requestStream
+		}
+		if stream == nil {
+			// errors without id are answered on the stream being delivered
+			stream = s.delivering
+		}
+		if stream == nil {
+			s.logger.Error("dropping response of an abandoned request", types.LogArg{
+				"sessionId": s.id,
+				"ids":       responseIds,
+			})
+			return nil
+		}
+	} else {
+		stream = s.findOpenStream()
+	}
+
+	stream.events = append(stream.events, message)
+	if stream == s.standalone && len(stream.events) > maxStandaloneStreamEvents {
+		stream.events = stream.events[1:]
+		stream.firstSeq++
+	}
+	stream.signal()
+	return nil
+}
+
+// findOpenStream returns the most recent POST stream waiting for responses,
+// or the GET stream
+func (s *streamableHttpSession) findOpenStream() *httpStream {
+	if s.delivering != nil && s.delivering.eventStream && !s.delivering.isComplete() {
+		return s.delivering
+	}
+	var openStream *httpStream
+	openStreamId := 0
+	for _, stream := range s.streams {
+		if !stream.eventStream || stream.isComplete() {
+			continue
+		}
+		streamId, _ := strconv.Atoi(stream.id)
+		if openStream == nil || streamId > openStreamId {
+			openStream = stream
+			openStreamId = streamId
+		}
+	}
+	return openStream
+}
+
+func (s *streamableHttpSession) OnMessage(callback func(json.RawMessage)) {
+	s.onMessage = callback
+}
+
+func (s *streamableHttpSession) OnStarted(callback func()) {
+	s.onStarted = callback
+}
+
+func (s *streamableHttpSession) OnClose(callback func()) {
+	s.onClose = callback
+}
+
+func (s *streamableHttpSession) OnError(callback func(error)) {
+	s.onError = callback
+}
+
+// Close terminates the session, the connections of its streams are closed
+func (s *streamableHttpSession) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.transport.removeSession(s.id)
+		s.logger.Info("streamable http session terminated", types.LogArg{
+			"sessionId": s.id,
+		})
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+}
+
+// beginRequest records an HTTP request of the client, the returned function
+// is called at the end of the request
+func (s *streamableHttpSession) beginRequest() func() {
+	s.mutex.Lock()
+	s.requests++
+	s.mutex.Unlock()
+	return func() {
+		s.mutex.Lock()
+		s.requests--
+		s.lastActivity = time.Now()
+		s.mutex.Unlock()
+	}
+}
+
+// isIdle returns true if the client has no request in progress (eg an open
+// stream) and sent none during the timeout
+func (s *streamableHttpSession) isIdle(now time.Time, timeout time.Duration) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests == 0 && now.Sub(s.lastActivity) >= timeout
+}
+
+// newStream registers the stream answering a POST request before its messages are delivered
+func (s *streamableHttpSession) newStream(requestIds []string, eventStream bool) *httpStream {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastStreamId++
+	stream := &httpStream{
+		id:          strconv.Itoa(s.lastStreamId),
+		eventStream: eventStream && len(requestIds) > 0,
+		firstSeq:    1,
+		pending:     map[string]bool{},
+		changed:     make(chan struct{}, 1),
+		evicted:     make(chan struct{}),
+	}
+	for _, requestId := range requestIds {
+		stream.pending[requestId] = true
+		s.requestStreams[requestId] = stream
+	}
+	s.streams[stream.id] = stream
+	return stream
+}
+
+// deliver passes the body of a POST request to the server, the errors
+// answered by the transport while parsing it are added to the stream
+func (s *streamableHttpSession) deliver(body []byte, stream *httpStream) {
+	s.deliverMutex.Lock()
+	defer s.deliverMutex.Unlock()
+
+	s.mutex.Lock()
+	s.delivering = stream
+	s.mutex.Unlock()
+
+	if s.onMessage != nil {
+		s.onMessage(json.RawMessage(body))
+	}
+
+	s.mutex.Lock()
+	s.delivering = nil
+	s.mutex.Unlock()
+}
+
+// takeEvents removes a stream and returns its messages
+func (s *streamableHttpSession) takeEvents(stream *httpStream) []json.RawMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.removeStream(stream)
+	return stream.events
+}
+
+// waitEvents waits for the responses to all the requests of a stream, it returns
+// false if the client disconnected or the session was terminated before
+func (s *streamableHttpSession) waitEvents(ctx context.Context, stream *httpStream) ([]json.RawMessage, bool) {
+	for {
+		s.mutex.Lock()
+		complete := stream.isComplete()
+		s.mutex.Unlock()
+		if complete {
+			return s.takeEvents(stream), true
+		}
+		select {
+		case <-stream.changed:
+		case <-ctx.Done():
+			s.takeEvents(stream)
+			return nil, false
+		case <-s.closed:
+			return nil, false
+		}
+	}
+}
+
+// resumeStream returns the stream of the last event received by the client and
+// the sequence number of the next event to send. Without a known event id it
+// returns the GET stream, with the events not sent yet.
+func (s *streamableHttpSession) resumeStream(lastEventId string) (*httpStream, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if streamId, seq, ok := parseEventId(lastEventId); ok {
+		if stream, ok := s.streams[streamId]; ok && stream.eventStream {
+			return stream, seq + 1
+		}
+		s.logger.Info("unknown event id, opening the GET stream", types.LogArg{
+			"sessionId":   s.id,
+			"lastEventId": lastEventId,
+		})
+	}
+	return s.standalone, s.standalone.sentSeq + 1
+}
+
+// writeEventStream writes the events of a stream from the sequence number
+// fromSeq, until the stream is complete or the client disconnects
+func (s *streamableHttpSession) writeEventStream(w http.ResponseWriter, r *http.Request, stream *httpStream, fromSeq int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s.mutex.Lock()
+	// a single connection receives the events of a stream, the previous one is closed
+	close(stream.evicted)
+	stream.evicted = make(chan struct{})
+	evicted := stream.evicted
+	s.removeDetached(stream)
+	s.mutex.Unlock()
+
+	nextSeq := fromSeq
+	for {
+		s.mutex.Lock()
+		if nextSeq < stream.firstSeq {
+			// the oldest events of the GET stream are lost
+			nextSeq = stream.firstSeq
+		}
+		events := []json.RawMessage{}
+		if nextSeq <= stream.lastSeq() {
+			events = append(events, stream.events[nextSeq-stream.firstSeq:]...)
+		}
+		s.mutex.Unlock()
+
+		for _, event := range events {
+			if err := writeSseEvent(w, fmt.Sprintf("%s-%d", stream.id, nextSeq), "", event); err != nil {
+				s.detach(stream, evicted)
+				return
+			}
+			nextSeq++
+		}
+		flusher.Flush()
+
+		s.mutex.Lock()
+		if nextSeq-1 > stream.sentSeq {
+			stream.sentSeq = nextSeq - 1
+		}
+		finished := stream.isComplete() && nextSeq > stream.lastSeq()
+		if finished {
+			s.removeStream(stream)
+		}
+		s.mutex.Unlock()
+		if finished {
+			return
+		}
+
+		select {
+		case <-stream.changed:
+		case <-evicted:
+			return
+		case <-r.Context().Done():
+			s.detach(stream, evicted)
+			return
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// detach keeps an interrupted POST stream so that the client can resume it,
+// the oldest interrupted streams are dropped
+func (s *streamableHttpSession) detach(stream *httpStream, evicted chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if stream == s.standalone || stream.evicted != evicted {
+		return
+	}
+	s.detached = append(s.detached, stream)
+	if len(s.detached) > maxDetachedStreams {
+		s.removeStream(s.detached[0])
+	}
+}
+
+func (s *streamableHttpSession) removeDetached(stream *httpStream) {
+	for i, detached := range s.detached {
+		if detached == stream {
+			s.detached = append(s.detached[:i], s.detached[i+1:]...)
+			return
+		}
+	}
+}
+
+// removeStream forgets a POST stream, the responses to its requests are dropped.
+// The mutex must be held.
+func (s *streamableHttpSession) removeStream(stream *httpStream) {
+	if stream == s.standalone {
+		return
+	}
+	delete(s.streams, stream.id)
+	for requestId := range stream.pending {
+		delete(s.requestStreams, requestId)
+	}
+	s.removeDetached(stream)
+}
+
+// writeSseEvent writes an SSE event, each line of the data is prefixed.
+// The id and the type of the event are optional.
+func writeSseEvent(w http.ResponseWriter, eventId string, eventType string, data []byte) error {
+	var buffer bytes.Buffer
+	if eventId != "" {
+		buffer.WriteString("id: " + eventId + "\n")
+	}
+	if eventType != "" {
+		buffer.WriteString("event: " + eventType + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buffer.WriteString("data: ")
+		buffer.Write(line)
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("\n")
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// parseEventId splits the id of an event in the id of its stream and its sequence number
+func parseEventId(eventId string) (string, int, bool) {
+	streamId, seqString, found := strings.Cut(eventId, "-")
+	if !found {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(seqString)
+	if err != nil {
+		return "", 0, false
+	}
+	return streamId, seq, true
+}
+
+// findResponses returns the ids of the responses of a message or a batch,
+// and false if the message is a request or a notification
+func findResponses(message json.RawMessage) ([]string, bool) {
+	var rawMessage interface{}
+	if err := json.Unmarshal(message, &rawMessage); err != nil {
+		return nil, false
+	}
+	items, isBatch := rawMessage.([]interface{})
+	if !isBatch {
+		items = []interface{}{rawMessage}
+	}
+	responseIds := []string{}
+	isResponse := false
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, hasMethod := object["method"]; hasMethod {
+			continue
+		}
+		isResponse = true
+		if requestId := jsonrpc.RequestIdFromValue(object["id"]); requestId != nil {
+			responseIds = append(responseIds, jsonrpc.RequestIdToString(requestId))
+		}
+	}
+	return responseIds, isResponse
+}
@@ -0,0 +1,296 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamstah/gomcp/types"
+)
+
+// startTestSession answers the requests of a session: initialize, notify sends a
+// notification before the response, slow answers once release is closed
+func startTestSession(ctx context.Context, session types.Transport, release chan struct{}) {
+	jsonRpcTransport := NewJsonRpcTransport(session, "session", nopLogger{})
+	go jsonRpcTransport.Start(ctx, func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+		request := message.Request
+		if request == nil || request.Id == nil {
+			return
+		}
+		switch request.Method {
+		case "initialize":
+			jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{"protocolVersion": "2025-06-18"})
+		case "notify":
+			jsonRpcTransport.SendNotificationWithParams("notifications/message", map[string]interface{}{"data": "hello"})
+			jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{})
+		case "slow":
+			jsonRpcTransport.SendNotificationWithParams("notifications/message", map[string]interface{}{"data": "working"})
+			go func() {
+				<-release
+				jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{"done": true})
+			}()
+		default:
+			jsonRpcTransport.SendResponseWithResults(request.Id, map[string]interface{}{})
+		}
+	})
+}
+
+// startStreamableHttpServer serves the test sessions with the Streamable HTTP transport
+func startStreamableHttpServer(t *testing.T, ctx context.Context, options types.StreamableHttpOptions, release chan struct{}) (*StreamableHttpTransport, *httptest.Server) {
+	httpTransport := NewStreamableHttpTransport(options, nopLogger{})
+	httpTransport.OnSession(func(session types.Transport) {
+		startTestSession(ctx, session, release)
+	})
+	go httpTransport.Start(ctx)
+
+	server := httptest.NewServer(httpTransport)
+	t.Cleanup(server.Close)
+	return httpTransport, server
+}
+
+func doRequest(t *testing.T, ctx context.Context, method string, url string, sessionId string, accept string, body string) *http.Response {
+	request, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request.Header.Set("Accept", accept)
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if sessionId != "" {
+		request.Header.Set(StreamableHttpSessionIdHeader, sessionId)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return response
+}
+
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readSseEvents reads count events of an SSE response
+func readSseEvents(t *testing.T, reader *bufio.Reader, count int) []sseEvent {
+	events := []sseEvent{}
+	event := sseEvent{}
+	for len(events) < count {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read events, got %+v: %v", events, err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data += strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, event)
+			event = sseEvent{}
+		}
+	}
+	return events
+}
+
+func TestStreamableHttpTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	release := make(chan struct{})
+	_, server := startStreamableHttpServer(t, ctx, types.StreamableHttpOptions{}, release)
+
+	const both = "application/json, text/event-stream"
+	initialize := `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		sessionId  string
+		body       string
+		wantStatus int
+	}{
+		{"request without session", http.MethodPost, "", `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`, http.StatusBadRequest},
+		{"unknown session", http.MethodPost, "unknown", `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`, http.StatusNotFound},
+		{"invalid JSON", http.MethodPost, "", `{"jsonrpc"`, http.StatusBadRequest},
+		{"method not allowed", http.MethodPut, "", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := doRequest(t, ctx, tt.method, server.URL, tt.sessionId, both, tt.body)
+			response.Body.Close()
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, response.StatusCode)
+			}
+		})
+	}
+
+	// the initialize request creates the session
+	response := doRequest(t, ctx, http.MethodPost, server.URL, "", "application/json", initialize)
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	sessionId := response.Header.Get(StreamableHttpSessionIdHeader)
+	if response.StatusCode != http.StatusOK || sessionId == "" || !strings.Contains(string(body), `"protocolVersion"`) {
+		t.Fatalf("unexpected initialize response %d %q: %s", response.StatusCode, sessionId, body)
+	}
+
+	// notifications are accepted
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, both, `{"jsonrpc": "2.0", "method": "notifications/initialized"}`)
+	response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", response.StatusCode)
+	}
+
+	// the messages related to the request are sent on its SSE stream
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, both, `{"jsonrpc": "2.0", "id": 2, "method": "notify"}`)
+	if response.Header.Get("Content-Type") != contentTypeEventStream {
+		t.Fatalf("expected an event stream, got %s", response.Header.Get("Content-Type"))
+	}
+	events := readSseEvents(t, bufio.NewReader(response.Body), 2)
+	response.Body.Close()
+	if !strings.Contains(events[0].data, `"notifications/message"`) || !strings.Contains(events[1].data, `"id":2`) {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	// a JSON response carries only the response, the notification goes to the GET stream
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, "application/json", `{"jsonrpc": "2.0", "id": 3, "method": "notify"}`)
+	body, _ = io.ReadAll(response.Body)
+	response.Body.Close()
+	var jsonResponse map[string]interface{}
+	if err := json.Unmarshal(body, &jsonResponse); err != nil || jsonResponse["id"] != float64(3) {
+		t.Errorf("unexpected JSON response %s: %v", body, err)
+	}
+	getCtx, getCancel := context.WithCancel(ctx)
+	response = doRequest(t, getCtx, http.MethodGet, server.URL, sessionId, contentTypeEventStream, "")
+	events = readSseEvents(t, bufio.NewReader(response.Body), 1)
+	getCancel()
+	response.Body.Close()
+	if !strings.Contains(events[0].data, `"hello"`) || !strings.HasPrefix(events[0].id, standaloneStreamId+"-") {
+		t.Errorf("unexpected GET stream events %+v", events)
+	}
+
+	// an interrupted stream is resumed with the id of the last event received
+	postCtx, postCancel := context.WithCancel(ctx)
+	response = doRequest(t, postCtx, http.MethodPost, server.URL, sessionId, both, `{"jsonrpc": "2.0", "id": 4, "method": "slow"}`)
+	events = readSseEvents(t, bufio.NewReader(response.Body), 1)
+	postCancel()
+	response.Body.Close()
+	waitDetachedStream(t, server, sessionId)
+	close(release)
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	request.Header.Set("Accept", contentTypeEventStream)
+	request.Header.Set(StreamableHttpSessionIdHeader, sessionId)
+	request.Header.Set(streamableHttpLastEventIdHeader, events[0].id)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events = readSseEvents(t, bufio.NewReader(response.Body), 1)
+	response.Body.Close()
+	if !strings.Contains(events[0].data, `"done":true`) {
+		t.Errorf("unexpected resumed events %+v", events)
+	}
+
+	// the session is terminated with DELETE
+	response = doRequest(t, ctx, http.MethodDelete, server.URL, sessionId, both, "")
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", response.StatusCode)
+	}
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, both, `{"jsonrpc": "2.0", "id": 5, "method": "ping"}`)
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 after DELETE, got %d", response.StatusCode)
+	}
+}
+
+// waitDetachedStream waits for the server to notice that the client disconnected
+func waitDetachedStream(t *testing.T, server *httptest.Server, sessionId string) {
+	httpTransport := server.Config.Handler.(*StreamableHttpTransport)
+	session := httpTransport.getSession(sessionId)
+	for i := 0; i < 100; i++ {
+		session.mutex.Lock()
+		detached := len(session.detached)
+		session.mutex.Unlock()
+		if detached > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the stream was not detached")
+}
+
+// initializeSession starts a session, it returns the status and the session id
+func initializeSession(t *testing.T, ctx context.Context, server *httptest.Server) (int, string) {
+	response := doRequest(t, ctx, http.MethodPost, server.URL, "", "application/json", `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`)
+	response.Body.Close()
+	return response.StatusCode, response.Header.Get(StreamableHttpSessionIdHeader)
+}
+
+func TestStreamableHttpMaxSessions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, server := startStreamableHttpServer(t, ctx, types.StreamableHttpOptions{MaxSessions: 1}, nil)
+
+	status, sessionId := initializeSession(t, ctx, server)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	if status, _ := initializeSession(t, ctx, server); status != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 above the maximum, got %d", status)
+	}
+
+	// a new session can start once the first one is terminated
+	response := doRequest(t, ctx, http.MethodDelete, server.URL, sessionId, "application/json", "")
+	response.Body.Close()
+	if status, _ := initializeSession(t, ctx, server); status != http.StatusOK {
+		t.Errorf("expected status 200 after DELETE, got %d", status)
+	}
+}
+
+func TestStreamableHttpSessionIdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	const timeout = 100 * time.Millisecond
+	_, server := startStreamableHttpServer(t, ctx, types.StreamableHttpOptions{SessionIdleTimeout: timeout}, nil)
+	ping := `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`
+
+	// the session is kept while the client sends requests
+	_, sessionId := initializeSession(t, ctx, server)
+	for i := 0; i < 4; i++ {
+		time.Sleep(timeout / 4)
+		response := doRequest(t, ctx, http.MethodPost, server.URL, sessionId, "application/json", ping)
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200 while active, got %d", response.StatusCode)
+		}
+	}
+
+	// and while its GET stream is open
+	getCtx, getCancel := context.WithCancel(ctx)
+	response := doRequest(t, getCtx, http.MethodGet, server.URL, sessionId, contentTypeEventStream, "")
+	time.Sleep(3 * timeout)
+	getCancel()
+	response.Body.Close()
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, "application/json", ping)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 after the GET stream, got %d", response.StatusCode)
+	}
+
+	// it is terminated once idle
+	time.Sleep(3 * timeout)
+	response = doRequest(t, ctx, http.MethodPost, server.URL, sessionId, "application/json", ping)
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 once idle, got %d", response.StatusCode)
+	}
+}
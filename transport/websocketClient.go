@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hamstah/gomcp/types"
+)
+
+// WebSocketClientTransport connects to an MCP server serving the WebSocket transport,
+// the connection is opened by Start and is not reopened once closed
+type WebSocketClientTransport struct {
+	url          string
+	header       http.Header
+	pingInterval time.Duration
+	logger       types.Logger
+
+	onMessage func(json.RawMessage)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+
+	conn     *WebSocketConn
+	mutex    sync.Mutex
+	isClosed bool
+}
+
+func NewWebSocketClientTransport(url string, header http.Header, logger types.Logger) *WebSocketClientTransport {
+	return &WebSocketClientTransport{
+		url:    url,
+		header: header,
+		logger: logger,
+	}
+}
+
+// DialWebSocket opens a WebSocket connection, the transport returned is already connected
+// tlsConfig is used for the wss:// URLs, the system roots are used when nil
+func DialWebSocket(ctx context.Context, url string, header http.Header, tlsConfig *tls.Config, pingInterval time.Duration) (*WebSocketConn, error) {
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: websocketWriteTimeout,
+		Subprotocols:     []string{WebSocketSubprotocol},
+		TLSClientConfig:  tlsConfig,
+	}
+	conn, response, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w (status %s)", url, err, response.Status)
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	return NewWebSocketConn(conn, pingInterval), nil
+}
+
+// Start connects to the server and reads its messages until the connection is closed
+func (t *WebSocketClientTransport) Start(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.isClosed {
+		t.mutex.Unlock()
+		return fmt.Errorf("transport closed")
+	}
+	t.mutex.Unlock()
+
+	conn, err := DialWebSocket(ctx, t.url, t.header, nil, t.pingInterval)
+	if err != nil {
+		if t.onError != nil {
+			t.onError(err)
+		}
+		return err
+	}
+	t.logger.Info("connected to the websocket server", types.LogArg{
+		"url": t.url,
+	})
+	conn.OnMessage(t.onMessage)
+	conn.OnStarted(t.onStarted)
+	conn.OnError(t.onError)
+	conn.OnClose(t.onClose)
+
+	t.mutex.Lock()
+	if t.isClosed {
+		t.mutex.Unlock()
+		conn.Close()
+		return fmt.Errorf("transport closed")
+	}
+	t.conn = conn
+	t.mutex.Unlock()
+
+	return conn.Start(ctx)
+}
+
+func (t *WebSocketClientTransport) Send(message json.RawMessage) error {
+	t.mutex.Lock()
+	conn := t.conn
+	t.mutex.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected to %s", t.url)
+	}
+	return conn.Send(message)
+}
+
+func (t *WebSocketClientTransport) OnMessage(callback func(json.RawMessage)) {
+	t.onMessage = callback
+}
+
+func (t *WebSocketClientTransport) OnStarted(callback func()) {
+	t.onStarted = callback
+}
+
+func (t *WebSocketClientTransport) OnClose(callback func()) {
+	t.onClose = callback
+}
+
+func (t *WebSocketClientTransport) OnError(callback func(error)) {
+	t.onError = callback
+}
+
+// Close closes the connection with a normal closure
+func (t *WebSocketClientTransport) Close() {
+	t.mutex.Lock()
+	if t.isClosed {
+		t.mutex.Unlock()
+		return
+	}
+	t.isClosed = true
+	conn := t.conn
+	t.mutex.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	} else if t.onClose != nil {
+		t.onClose()
+	}
+}
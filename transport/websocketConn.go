@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	websocketDefaultPingInterval = 30 * time.Second
+	websocketWriteTimeout        = 10 * time.Second
+	// a peer not answering the pings within that many intervals is considered gone
+	websocketPongWaitIntervals = 2
+	websocketMaxMessageSize    = maxHttpBodySize
+)
+
+// WebSocketConn is the transport of an established WebSocket connection: each text
+// frame carries one JSON-RPC message (or batch). The peer is pinged on an interval
+// and the connection is dropped when it stops answering.
+type WebSocketConn struct {
+	conn         *websocket.Conn
+	pingInterval time.Duration
+
+	// gorilla/websocket supports a single concurrent writer
+	writeMutex sync.Mutex
+
+	onMessage func(json.RawMessage)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWebSocketConn wraps a connection, pingInterval defaults to 30s, negative disables the pings
+func NewWebSocketConn(conn *websocket.Conn, pingInterval time.Duration) *WebSocketConn {
+	if pingInterval == 0 {
+		pingInterval = websocketDefaultPingInterval
+	}
+	return &WebSocketConn{
+		conn:         conn,
+		pingInterval: pingInterval,
+		closed:       make(chan struct{}),
+	}
+}
+
+// Start reads the frames until the connection is closed by either side or the context is cancelled
+func (s *WebSocketConn) Start(ctx context.Context) error {
+	s.conn.SetReadLimit(websocketMaxMessageSize)
+	if s.pingInterval > 0 {
+		pongWait := s.pingInterval * websocketPongWaitIntervals
+		s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		s.conn.SetPongHandler(func(string) error {
+			return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		go s.pingLoop()
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.readLoop()
+	}()
+
+	if s.onStarted != nil {
+		s.onStarted()
+	}
+
+	select {
+	case err := <-errChan:
+		s.Close()
+		return err
+	case <-ctx.Done():
+		s.closeWithCode(websocket.CloseGoingAway, "")
+		return ctx.Err()
+	case <-s.closed:
+		return nil
+	}
+}
+
+// readLoop delivers the messages of the peer, it returns nil when the connection is closed on our side
+func (s *WebSocketConn) readLoop() error {
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.closed:
+				// closed on our side
+				return nil
+			default:
+			}
+			// like the EOF of a socket, the end of the connection is reported to the owner
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return fmt.Errorf("connection closed by the peer: %w", err)
+			}
+			// the frames above the read limit are refused with CloseMessageTooBig by gorilla/websocket
+			if s.onError != nil {
+				s.onError(err)
+			}
+			return err
+		}
+
+		if messageType != websocket.TextMessage {
+			s.closeWithCode(websocket.CloseUnsupportedData, "only text frames are supported")
+			return fmt.Errorf("unsupported websocket frame type %d", messageType)
+		}
+
+		// the invalid messages are answered by the JSON-RPC transport
+		if s.onMessage != nil {
+			s.onMessage(json.RawMessage(data))
+		}
+	}
+}
+
+func (s *WebSocketConn) pingLoop() {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(websocketWriteTimeout))
+			if err != nil {
+				// the connection is broken, the read loop fails as well
+				return
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// Send writes the message in a text frame
+func (s *WebSocketConn) Send(message json.RawMessage) error {
+	select {
+	case <-s.closed:
+		return fmt.Errorf("connection is closed")
+	default:
+	}
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+	return s.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// PeerCommonName implements PeerIdentity
+func (s *WebSocketConn) PeerCommonName() string {
+	return peerCommonName(s.conn.UnderlyingConn())
+}
+
+func (s *WebSocketConn) OnMessage(callback func(json.RawMessage)) {
+	s.onMessage = callback
+}
+
+func (s *WebSocketConn) OnStarted(callback func()) {
+	s.onStarted = callback
+}
+
+func (s *WebSocketConn) OnClose(callback func()) {
+	s.onClose = callback
+}
+
+func (s *WebSocketConn) OnError(callback func(error)) {
+	s.onError = callback
+}
+
+// Close sends a normal closure to the peer and closes the connection
+func (s *WebSocketConn) Close() {
+	s.closeWithCode(websocket.CloseNormalClosure, "")
+}
+
+func (s *WebSocketConn) closeWithCode(code int, reason string) {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		// the peer may be gone already, the error is not relevant
+		s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(websocketWriteTimeout))
+		s.conn.Close()
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+}
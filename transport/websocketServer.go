@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/hamstah/gomcp/types"
+)
+
+const (
+	websocketDefaultPath = "/ws"
+	// subprotocol negotiated with the clients asking for one
+	WebSocketSubprotocol = "mcp"
+)
+
+// WebSocketServerTransport serves the MCP clients over WebSocket, each connection
+// is a session and each text frame carries one JSON-RPC message. Unlike the HTTP
+// transports the browsers can reach it without relying on a streamed response.
+type WebSocketServerTransport struct {
+	options  types.WebSocketOptions
+	logger   types.Logger
+	upgrader websocket.Upgrader
+
+	sessions map[*WebSocketConn]bool
+	mutex    sync.Mutex
+
+	onSession func(session types.Transport)
+	onStarted func()
+	onClose   func()
+	onError   func(error)
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewWebSocketServerTransport(options types.WebSocketOptions, logger types.Logger) *WebSocketServerTransport {
+	if options.Path == "" {
+		options.Path = websocketDefaultPath
+	}
+	t := &WebSocketServerTransport{
+		options:  options,
+		logger:   logger,
+		sessions: map[*WebSocketConn]bool{},
+		closed:   make(chan struct{}),
+	}
+	t.upgrader = websocket.Upgrader{
+		Subprotocols: []string{WebSocketSubprotocol},
+		// browsers of other sites must not reach a local server (DNS rebinding)
+		CheckOrigin: func(r *http.Request) bool {
+			return isAllowedOrigin(r, t.options.AllowedOrigins)
+		},
+	}
+	return t
+}
+
+// Start listens on the address of the options until the context is cancelled,
+// without address it only waits for the context as the application serves the handler
+func (t *WebSocketServerTransport) Start(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	var server *http.Server
+	if t.options.ListenAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(t.options.Path, t)
+		server = &http.Server{
+			Addr:    t.options.ListenAddress,
+			Handler: mux,
+		}
+		go func() {
+			t.logger.Info("websocket transport listening", types.LogArg{
+				"address": t.options.ListenAddress,
+				"path":    t.options.Path,
+			})
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				if t.onError != nil {
+					t.onError(err)
+				}
+				errChan <- fmt.Errorf("failed to serve %s: %w", t.options.ListenAddress, err)
+			}
+		}()
+	}
+
+	if t.onStarted != nil {
+		t.onStarted()
+	}
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-t.closed:
+	}
+
+	t.Close()
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+	return err
+}
+
+// Send is not supported, the messages are sent by the transports of the sessions
+func (t *WebSocketServerTransport) Send(message json.RawMessage) error {
+	return fmt.Errorf("websocket transport: messages are sent through the sessions")
+}
+
+// OnMessage is not used, the messages are received by the transports of the sessions
+func (t *WebSocketServerTransport) OnMessage(callback func(json.RawMessage)) {}
+
+func (t *WebSocketServerTransport) OnSession(callback func(session types.Transport)) {
+	t.onSession = callback
+}
+
+func (t *WebSocketServerTransport) OnStarted(callback func()) {
+	t.onStarted = callback
+}
+
+func (t *WebSocketServerTransport) OnClose(callback func()) {
+	t.onClose = callback
+}
+
+func (t *WebSocketServerTransport) OnError(callback func(error)) {
+	t.onError = callback
+}
+
+// Close closes the connections of all the sessions
+func (t *WebSocketServerTransport) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+
+		t.mutex.Lock()
+		sessions := make([]*WebSocketConn, 0, len(t.sessions))
+		for session := range t.sessions {
+			sessions = append(sessions, session)
+		}
+		t.mutex.Unlock()
+
+		for _, session := range sessions {
+			session.closeWithCode(websocket.CloseGoingAway, "server shutting down")
+		}
+		if t.onClose != nil {
+			t.onClose()
+		}
+	})
+}
+
+// ServeHTTP upgrades the connection and opens a session for it
+func (t *WebSocketServerTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if t.onSession == nil {
+		http.Error(w, "the transport is not started", http.StatusServiceUnavailable)
+		return
+	}
+
+	// the upgrader answers the invalid requests and the forbidden origins
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		t.logger.Error("failed to upgrade the websocket connection", types.LogArg{
+			"origin": r.Header.Get("Origin"),
+			"error":  err,
+		})
+		return
+	}
+
+	session := NewWebSocketConn(conn, t.options.PingInterval)
+	t.mutex.Lock()
+	t.sessions[session] = true
+	t.mutex.Unlock()
+	defer func() {
+		t.mutex.Lock()
+		delete(t.sessions, session)
+		t.mutex.Unlock()
+	}()
+
+	t.logger.Info("new websocket session", types.LogArg{
+		"remoteAddress": conn.RemoteAddr().String(),
+	})
+	t.onSession(session)
+
+	// the connection is hijacked, the handler only keeps track of the session until it ends
+	<-session.closed
+	t.logger.Info("websocket session terminated", types.LogArg{
+		"remoteAddress": conn.RemoteAddr().String(),
+	})
+}
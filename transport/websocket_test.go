@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hamstah/gomcp/types"
+)
+
+func TestWebSocketTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// short pings, they are answered by the clients while reading
+	wsTransport := NewWebSocketServerTransport(types.WebSocketOptions{PingInterval: 20 * time.Millisecond}, nopLogger{})
+	wsTransport.OnSession(func(session types.Transport) {
+		startTestSession(ctx, session, nil)
+	})
+	go wsTransport.Start(ctx)
+	server := httptest.NewServer(wsTransport)
+	t.Cleanup(server.Close)
+	wsUrl := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	// the browsers of other sites are refused
+	_, response, err := websocket.DefaultDialer.DialContext(ctx, wsUrl, http.Header{"Origin": []string{"http://example.com"}})
+	if err == nil || response == nil || response.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the origin to be forbidden, got %v", err)
+	}
+
+	// a request and its notification, one message per frame
+	client := NewWebSocketClientTransport(wsUrl, nil, nopLogger{})
+	jsonRpcClient := NewJsonRpcTransport(client, "client", nopLogger{})
+	started := make(chan struct{}, 1)
+	jsonRpcClient.OnStarted(func() {
+		started <- struct{}{}
+	})
+	messages := make(chan JsonRpcMessage, 10)
+	go jsonRpcClient.Start(ctx, func(message JsonRpcMessage, jsonRpcTransport *JsonRpcTransport) {
+		messages <- message
+	})
+	<-started
+	if _, err := jsonRpcClient.SendRequestWithMethodAndParams("notify", map[string]interface{}{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	received := []JsonRpcMessage{}
+	for len(received) < 2 {
+		select {
+		case message := <-messages:
+			received = append(received, message)
+		case <-ctx.Done():
+			t.Fatalf("timeout waiting for the messages, got %+v", received)
+		}
+	}
+	if received[0].Request == nil || received[0].Method != "notifications/message" || received[1].Response == nil {
+		t.Errorf("unexpected messages %+v", received)
+	}
+	client.Close()
+
+	// the connection is kept alive with pings and the binary frames are refused
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsUrl, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	var pings atomic.Int32
+	conn.SetPingHandler(func(data string) error {
+		pings.Add(1)
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	// the pings are answered while reading
+	type frame struct {
+		data []byte
+		err  error
+	}
+	frames := make(chan frame, 10)
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			frames <- frame{data, err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+	if response := <-frames; response.err != nil || !strings.Contains(string(response.data), `"id":1`) {
+		t.Fatalf("unexpected response %s: %v", response.data, response.err)
+	}
+	if pings.Load() == 0 {
+		t.Errorf("expected pings from the server")
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte(`{}`))
+	if closed := <-frames; !websocket.IsCloseError(closed.err, websocket.CloseUnsupportedData) {
+		t.Errorf("expected close code %d, got %v", websocket.CloseUnsupportedData, closed.err)
+	}
+}
@@ -0,0 +1,7 @@
+package types
+
+import "context"
+
+// CompletionHandler returns the possible values of an argument starting with value,
+// arguments contains the values of the other arguments already set by the client
+type CompletionHandler func(ctx context.Context, value string, arguments map[string]string) ([]string, error)
@@ -52,3 +52,44 @@ func (l *SubLogger) Error(message string, fields LogArg) {
 func (l *SubLogger) Fatal(message string, fields LogArg) {
 	l.logger.Fatal(message, mergeFields(l.fields, fields))
 }
+
+// LogForwarder sends a log record to the MCP client
+// level is one of the MCP logging levels (debug, info, error, critical)
+type LogForwarder func(level string, loggerName string, message string, fields LogArg)
+
+// ForwardingLogger is a logger that also forwards the records to the MCP client
+// it is given to the tool and resource handlers
+type ForwardingLogger struct {
+	logger     Logger
+	loggerName string
+	forward    LogForwarder
+}
+
+func NewForwardingLogger(logger Logger, loggerName string, forward LogForwarder) Logger {
+	return &ForwardingLogger{
+		logger:     logger,
+		loggerName: loggerName,
+		forward:    forward,
+	}
+}
+
+func (l *ForwardingLogger) Info(message string, fields LogArg) {
+	l.logger.Info(message, fields)
+	l.forward("info", l.loggerName, message, fields)
+}
+
+func (l *ForwardingLogger) Debug(message string, fields LogArg) {
+	l.logger.Debug(message, fields)
+	l.forward("debug", l.loggerName, message, fields)
+}
+
+func (l *ForwardingLogger) Error(message string, fields LogArg) {
+	l.logger.Error(message, fields)
+	l.forward("error", l.loggerName, message, fields)
+}
+
+func (l *ForwardingLogger) Fatal(message string, fields LogArg) {
+	// we forward first as the logger exits
+	l.forward("critical", l.loggerName, message, fields)
+	l.logger.Fatal(message, fields)
+}
@@ -2,14 +2,37 @@ package types
 
 type ToolProvider interface {
 	AddTool(toolName string, description string, toolHandler interface{}) error
+	AddToolWithOptions(toolName string, description string, toolHandler interface{}, options ToolOptions) error
 }
 
 type ToolRegistry interface {
 	DeclareToolProvider(toolName string, toolInitFunction interface{}) (ToolProvider, error)
 }
 
+type ResourceProvider interface {
+	AddResource(uri string, name string, description string, mimeType string, resourceHandler interface{}) error
+	RemoveResource(uri string) error
+	AddResourceTemplate(uriTemplate string, name string, description string, mimeType string, resourceHandler interface{}) error
+	AddResourceTemplateCompletion(uriTemplate string, variable string, completionHandler CompletionHandler) error
+	NotifyResourceUpdated(uri string)
+	NotifyResourceListChanged()
+}
+
+type ResourceRegistry interface {
+	DeclareResourceProvider(providerName string, resourceInitFunction interface{}) (ResourceProvider, error)
+}
+
+type PromptRegistry interface {
+	AddPromptArgumentCompletion(promptName string, argumentName string, completionHandler CompletionHandler) error
+}
+
 type ModelContextProtocol interface {
 	StdioTransport() Transport
+	StreamableHttpTransport(options StreamableHttpOptions) Transport
+	SseTransport(options SseOptions) Transport
+	WebSocketTransport(options WebSocketOptions) Transport
 	GetToolRegistry() ToolRegistry
+	GetResourceRegistry() ResourceRegistry
+	GetPromptRegistry() PromptRegistry
 	Start(transport Transport) error
 }
@@ -11,6 +11,8 @@ type PromptGetResult interface {
 	AddTextContent(role Role, content string)
 	AddJSONTextContent(role Role, content interface{})
 	AddImageContent(role Role, mimeType string, base64Data string)
+	AddAudioContent(role Role, mimeType string, base64Data string)
+	AddResourceLinkContent(role Role, uri string, name string, description string, mimeType string)
 	AddEmbeddedResourceTextContent(role Role, uri string, mimeType string, text string)
 	AddEmbeddedResourceBlobContent(role Role, uri string, mimeType string, base64Data string)
 }
@@ -0,0 +1,7 @@
+package types
+
+type ResourceReadResult interface {
+	AddTextContent(uri string, mimeType string, text string)
+	AddJSONTextContent(uri string, content interface{})
+	AddBlobContent(uri string, mimeType string, base64Data string)
+}
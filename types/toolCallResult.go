@@ -4,6 +4,8 @@ type ToolCallResult interface {
 	AddTextContent(content string)
 	AddJSONTextContent(content interface{})
 	AddImageContent(mimeType string, base64Data string)
+	AddAudioContent(mimeType string, base64Data string)
+	AddResourceLinkContent(uri string, name string, description string, mimeType string)
 	AddEmbeddedResourceTextContent(uri string, mimeType string, text string)
 	AddEmbeddedResourceBlobContent(uri string, mimeType string, base64Data string)
 	SetError(isError bool)
@@ -0,0 +1,23 @@
+package types
+
+// ToolAnnotations are hints about the behavior of a tool,
+// clients use them to decide when to ask the user for confirmation.
+// A nil hint means it is not set and the client applies the MCP default.
+type ToolAnnotations struct {
+	// the tool does not modify its environment (default: false)
+	ReadOnlyHint *bool `json:"readOnlyHint,omitempty"`
+	// the tool may perform destructive updates (default: true)
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+	// calling the tool again with the same arguments has no additional effect (default: false)
+	IdempotentHint *bool `json:"idempotentHint,omitempty"`
+	// the tool interacts with external entities (default: true)
+	OpenWorldHint *bool `json:"openWorldHint,omitempty"`
+}
+
+// ToolOptions are the optional properties of a tool
+type ToolOptions struct {
+	// human-readable name of the tool
+	Title string
+	// behavior hints, nil if the tool has no annotations
+	Annotations *ToolAnnotations
+}
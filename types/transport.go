@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // Transport defines the interface for MCP communication
@@ -39,3 +40,66 @@ type Transport interface {
 	// any kind of exceptional condition out of band.
 	OnError(callback func(error))
 }
+
+// SessionTransport is a transport serving several MCP clients (eg HTTP),
+// each client gets its own session with the server.
+// Start serves the clients until the context is cancelled, the messages
+// are exchanged with the transports of the sessions.
+type SessionTransport interface {
+	Transport
+
+	// Callback for when a client opens a session, the transport of the
+	// session carries the messages of that client only.
+	OnSession(callback func(session Transport))
+}
+
+// HttpServerOptions configures the HTTP server of the Streamable HTTP, SSE and
+// WebSocket transports
+type HttpServerOptions struct {
+	// address the HTTP server listens on (eg localhost:8080), when empty
+	// the transport is only served as an http.Handler by the application
+	ListenAddress string
+	// origins of the browsers allowed to connect (eg http://localhost:6274),
+	// "*" allows all of them. Same origin requests are always allowed.
+	AllowedOrigins []string
+}
+
+// StreamableHttpOptions configures the Streamable HTTP transport
+type StreamableHttpOptions struct {
+	HttpServerOptions
+	// path of the MCP endpoint, defaults to /mcp
+	Path string
+	// answer the requests with a JSON response instead of an SSE stream,
+	// the server can't send requests or notifications while processing them
+	JsonResponse bool
+	// the sessions without any request or open stream during that time are
+	// terminated, the clients that did not send DELETE. Disabled when 0.
+	SessionIdleTimeout time.Duration
+	// maximum number of sessions, the new ones are refused with 503 Service Unavailable.
+	// Unlimited when 0.
+	MaxSessions int
+}
+
+// SseOptions configures the HTTP+SSE transport of the 2024-11-05 revision,
+// kept for the clients not supporting the Streamable HTTP transport
+type SseOptions struct {
+	HttpServerOptions
+	// path of the SSE stream opened by the clients, defaults to /sse
+	SsePath string
+	// path the clients post their messages to, defaults to /message
+	MessagePath string
+	// interval of the keepalive comments sent on the idle streams so that
+	// the proxies don't close them, defaults to 15s, negative to disable
+	KeepAliveInterval time.Duration
+}
+
+// WebSocketOptions configures the WebSocket transport, each connection is a session
+// and each text frame carries one JSON-RPC message
+type WebSocketOptions struct {
+	HttpServerOptions
+	// path of the WebSocket endpoint, defaults to /ws
+	Path string
+	// interval of the pings checking that the clients are still connected,
+	// defaults to 30s, negative to disable
+	PingInterval time.Duration
+}